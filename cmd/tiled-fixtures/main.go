@@ -0,0 +1,55 @@
+// Command tiled-fixtures parses every map under a directory of Tiled exports and compares
+// each against the summary stored next to it, so a new Tiled release can be validated by
+// re-exporting the fixtures and running the command.
+//
+//	tiled-fixtures -dir fixtures          # check
+//	tiled-fixtures -dir fixtures -update  # write summaries
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+func main() {
+	dir := flag.String("dir", "fixtures", "directory of Tiled exports")
+	update := flag.Bool("update", false, "write summaries instead of comparing them")
+	flag.Parse()
+
+	results, err := tiled.CheckFixtures(os.DirFS(*dir), *update)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tiled-fixtures:", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err == nil && *update {
+			result.Err = writeSummary(filepath.Join(*dir, filepath.FromSlash(result.Path)+tiled.FixtureSummarySuffix), result.Summary)
+		}
+		if result.Err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v\n", result.Path, result.Err)
+			continue
+		}
+		fmt.Printf("ok   %s\n", result.Path)
+	}
+
+	fmt.Printf("%d maps, %d failed\n", len(results), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeSummary(path string, summary *tiled.MapSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
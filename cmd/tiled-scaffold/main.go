@@ -0,0 +1,30 @@
+// Command tiled-scaffold writes a minimal runnable ebiten game that displays a Tiled map
+// through this package, as a starting point for new projects.
+//
+//	tiled-scaffold -map levels/start.tmx -module example.com/mygame -out mygame
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/adm87/finch-tiled/scaffold"
+)
+
+func main() {
+	var opts scaffold.Options
+	flag.StringVar(&opts.Map, "map", "", "TMX map to display")
+	flag.StringVar(&opts.Module, "module", "", "Go module path of the generated game")
+	flag.StringVar(&opts.Dir, "out", ".", "directory to write the game to")
+	flag.IntVar(&opts.Width, "width", 640, "window width in pixels")
+	flag.IntVar(&opts.Height, "height", 480, "window height in pixels")
+	flag.StringVar(&opts.TiledDir, "tiled", "", "finch-tiled checkout the game uses (default: the one this command was built from)")
+	flag.Parse()
+
+	if err := scaffold.Generate(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "tiled-scaffold:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s. To run it:\n\n\tcd %s\n\tgo mod tidy\n\tgo run .\n", opts.Dir, opts.Dir)
+}
@@ -5,6 +5,7 @@ go 1.25.1
 require (
 	github.com/adm87/finch-core v0.0.10
 	github.com/hajimehoshi/ebiten/v2 v2.8.8
+	github.com/klauspost/compress v1.18.0
 )
 
 require (
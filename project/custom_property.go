@@ -1,5 +1,11 @@
 package project
 
+import (
+	"fmt"
+
+	"github.com/adm87/finch-tiled/tiled"
+)
+
 func InsertOrUpdateEnumType(proj *TiledProject, definitions ...TiledEnumPropertyType) error {
 	nextID := getNextPropertyTypeID(proj)
 
@@ -74,3 +80,50 @@ func getExistingClassType(proj *TiledProject, name string) *TiledClassPropertyTy
 	}
 	return nil
 }
+
+// ClassDefaults returns the members of a class as properties holding their default values,
+// or nil if the project doesn't define the class. It can be passed to tiled.SetClassDefaults.
+func (p *TiledProject) ClassDefaults(class string) []*tiled.Property {
+	classType := getExistingClassType(p, class)
+	if classType == nil {
+		return nil
+	}
+
+	props := make([]*tiled.Property, 0, len(classType.Members))
+	for _, member := range classType.Members {
+		props = append(props, p.memberProperty(member.Name, member.Type, member.PropertyType, member.Value))
+	}
+	return props
+}
+
+// memberProperty builds a property from a class member. Class members hold the nested class's
+// defaults, overridden by the member's own values.
+func (p *TiledProject) memberProperty(name, ptype, propertyType string, value any) *tiled.Property {
+	prop := &tiled.Property{Attrs: tiled.TiledXMLAttrTable{
+		tiled.NameAttr: tiled.AttrString(name),
+		tiled.TypeAttr: tiled.AttrString(ptype),
+	}}
+	if propertyType != "" {
+		prop.Attrs[tiled.PropertyTypeAttr] = tiled.AttrString(propertyType)
+	}
+
+	if ptype != tiled.ClassPropertyType {
+		if value != nil {
+			prop.Attrs[tiled.ValueAttr] = tiled.AttrString(fmt.Sprint(value))
+		}
+		return prop
+	}
+
+	overrides, _ := value.(map[string]any)
+	for _, nested := range p.ClassDefaults(propertyType) {
+		if override, exists := overrides[nested.Name()]; exists {
+			var nestedType string
+			if attr, ok := nested.Attrs[tiled.PropertyTypeAttr]; ok {
+				nestedType = attr.String()
+			}
+			nested = p.memberProperty(nested.Name(), nested.Type(), nestedType, override)
+		}
+		prop.Properties = append(prop.Properties, nested)
+	}
+	return prop
+}
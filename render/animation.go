@@ -0,0 +1,89 @@
+package render
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Tile Animation
+// ======================================================
+
+// animationClock is the time, in nanoseconds, every animated tile is shown at.
+var animationClock atomic.Int64
+
+// Advance moves the animation clock forward. Every animated tile picks its frame from the same clock,
+// so tiles sharing an animation stay in step across layers, chunks and maps. Call it once per update;
+// it also renews the DecodeBudget for the next frame.
+func Advance(dt time.Duration) {
+	animationClock.Add(int64(dt))
+	renewDecodeBudget()
+}
+
+// AnimationTime returns how far the animation clock has advanced.
+func AnimationTime() time.Duration {
+	return time.Duration(animationClock.Load())
+}
+
+// tileAnimation is the frames of an animated tile, with the time into the animation each frame ends at.
+type tileAnimation struct {
+	ids   []uint32
+	ends  []time.Duration
+	total time.Duration
+}
+
+// tileAnimations returns the animations of the tileset's tiles by tile ID, or nil if it has none.
+// Animations without any time to show their frames are left out.
+func tileAnimations(tsx *tiled.TSX) map[uint32]*tileAnimation {
+	var anims map[uint32]*tileAnimation
+	for _, tile := range tsx.Tiles {
+		if len(tile.Animation) == 0 {
+			continue
+		}
+
+		anim := &tileAnimation{}
+		for _, frame := range tile.Animation {
+			anim.total += frame.Duration()
+			anim.ids = append(anim.ids, frame.TileID())
+			anim.ends = append(anim.ends, anim.total)
+		}
+		if anim.total <= 0 {
+			continue
+		}
+
+		if anims == nil {
+			anims = make(map[uint32]*tileAnimation)
+		}
+		anims[tile.ID()] = anim
+	}
+	return anims
+}
+
+// frameAt returns the ID of the tile shown at a time on the animation clock.
+func (anim *tileAnimation) frameAt(t time.Duration) uint32 {
+	t %= anim.total
+	if t < 0 {
+		t += anim.total
+	}
+	for i, end := range anim.ends {
+		if t < end {
+			return anim.ids[i]
+		}
+	}
+	return anim.ids[len(anim.ids)-1]
+}
+
+// animated reports whether any of the layer's decoded tiles is animated.
+func (state *layerState) animated() bool {
+	if state.tiles != nil {
+		return state.tiles.animated
+	}
+	for _, block := range state.partitions {
+		if block.animated {
+			return true
+		}
+	}
+	return false
+}
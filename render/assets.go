@@ -0,0 +1,304 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Asset types registered with finch by RegisterTiledAssetImporters.
+const (
+	TMXAssetType     = tiled.TMXAssetType
+	TSXAssetType     = tiled.TSXAssetType
+	TXAssetType      = tiled.TXAssetType
+	CookedAssetType  = tiled.CookedAssetType
+	MapSaveAssetType = tiled.MapSaveAssetType
+)
+
+func init() {
+	tiled.SetResolver(finchResolver{})
+}
+
+// finchResolver finds the files referenced by maps loaded through finch among finch's assets.
+type finchResolver struct{}
+
+func (finchResolver) TSX(path string) (*tiled.TSX, error) {
+	return GetTSX(finch.AssetFile(path))
+}
+
+func (finchResolver) TX(path string) (*tiled.TX, error) {
+	return GetTX(finch.AssetFile(path))
+}
+
+func (finchResolver) Image(path string) (image.Image, error) {
+	asset, err := finch.AssetFile(path).Get()
+	if err != nil {
+		return nil, err
+	}
+	img, ok := asset.(image.Image)
+	if !ok {
+		return nil, fmt.Errorf("asset is not an image: %s", path)
+	}
+	return img, nil
+}
+
+func RegisterTiledAssetImporters() {
+	// TMX Asset Support
+	finch.RegisterAssetImporter(&finch.AssetImporter{
+		AssetTypes: []finch.AssetType{TMXAssetType},
+		ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+			return tiled.ParseTMXFile(bytes.NewReader(data), file.Path())
+		},
+	})
+	// Cooked TMX Asset Support
+	finch.RegisterAssetImporter(&finch.AssetImporter{
+		AssetTypes: []finch.AssetType{CookedAssetType},
+		ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+			tmx, err := tiled.ReadCooked(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+
+			if !tiled.CurrentConfig().AllowsOrientation(tmx.Orientation()) {
+				return nil, fmt.Errorf("map orientation %s is not enabled: %s", tmx.Orientation(), file.Path())
+			}
+
+			if m := tiled.CurrentMetrics(); m != nil {
+				m.AddCounter(tiled.MetricMapsLoaded, 1)
+			}
+
+			return tmx, nil
+		},
+	})
+	// TSX Asset Support
+	finch.RegisterAssetImporter(&finch.AssetImporter{
+		AssetTypes: []finch.AssetType{TSXAssetType},
+		ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+			return tiled.ParseTSXFile(bytes.NewReader(data), file.Path())
+		},
+	})
+	// TX Asset Support
+	finch.RegisterAssetImporter(&finch.AssetImporter{
+		AssetTypes: []finch.AssetType{TXAssetType},
+		ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+			return tiled.ParseTXFile(bytes.NewReader(data), file.Path())
+		},
+	})
+
+	// Map Save Asset Support
+	finch.RegisterAssetImporter(&finch.AssetImporter{
+		AssetTypes: []finch.AssetType{MapSaveAssetType},
+		ProcessAssetFile: func(file finch.AssetFile, data []byte) (any, error) {
+			save, err := tiled.ReadMapSave(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("invalid map save %s: %w", file.Path(), err)
+			}
+			return save, nil
+		},
+	})
+}
+
+// RegisterTiledAssetImportersWithConfig applies the configuration and registers the asset importers.
+func RegisterTiledAssetImportersWithConfig(cfg tiled.Config) error {
+	if err := tiled.Configure(cfg); err != nil {
+		return err
+	}
+	RegisterTiledAssetImporters()
+	return nil
+}
+
+// PropertyAssetFile returns the asset referenced by a file property, ready to be loaded through finch.
+func PropertyAssetFile(prop *tiled.Property) (finch.AssetFile, error) {
+	path, err := prop.FileRef()
+	if err != nil {
+		return "", err
+	}
+	return finch.AssetFile(path), nil
+}
+
+// GetTX retrieves a TX asset by its file reference.
+func GetTX(file finch.AssetFile) (*tiled.TX, error) {
+	asset, err := finch.GetAsset[*tiled.TX](file)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// GetTXTSX retrieves the TSX asset referenced by a TX asset.
+func GetTXTSX(file finch.AssetFile) (*tiled.TSX, error) {
+	tx, err := GetTX(file)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Tileset == nil {
+		return nil, fmt.Errorf("tx does not contain a tileset: %s", file.Path())
+	}
+	return tx.Tileset.TSX()
+}
+
+// GetTXImg retrieves the image associated with a TX asset.
+//
+// Images are retrieved from the TSX asset referenced by the TX.
+func GetTXImg(file finch.AssetFile) (*ebiten.Image, error) {
+	tsx, err := GetTXTSX(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if tsx.Image == nil {
+		return nil, fmt.Errorf("tx tileset is an image collection and has no tileset image: %s", file.Path())
+	}
+	return tilesetImage(tsx.Image, "tx")
+}
+
+// GetImageLayerImg retrieves the image displayed by an image layer.
+func GetImageLayerImg(layer *tiled.ImageLayer) (*ebiten.Image, error) {
+	if layer.Image == nil {
+		return nil, fmt.Errorf("image layer does not contain an image: %s", layer.Name())
+	}
+	return tilesetImage(layer.Image, "image layer")
+}
+
+// GetTMX retrieves a TMX asset by its file reference.
+func GetTMX(file finch.AssetFile) (*tiled.TMX, error) {
+	asset, err := finch.GetAsset[*tiled.TMX](file)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// GetMapSave retrieves a map save asset by its file reference.
+func GetMapSave(file finch.AssetFile) (*tiled.MapSave, error) {
+	asset, err := finch.GetAsset[*tiled.MapSave](file)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// GetTSX retrieves a TSX asset by its file reference.
+func GetTSX(file finch.AssetFile) (*tiled.TSX, error) {
+	asset, err := finch.GetAsset[*tiled.TSX](file)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// GetTSXImg retrieves the image associated with a TSX asset.
+func GetTSXImg(file finch.AssetFile) (*ebiten.Image, error) {
+	tsx, err := GetTSX(file)
+	if err != nil {
+		return nil, err
+	}
+	return tsxImage(tsx, file.Path())
+}
+
+// GetTSXTileImg retrieves the image of a single tile in an image collection tileset.
+func GetTSXTileImg(file finch.AssetFile, id uint32) (*ebiten.Image, error) {
+	tsx, err := GetTSX(file)
+	if err != nil {
+		return nil, err
+	}
+	return tsxTileImage(tsx, file.Path(), id)
+}
+
+// tsxImage returns the tileset image of the tileset loaded from the path.
+func tsxImage(tsx *tiled.TSX, path string) (*ebiten.Image, error) {
+	if tsx.Image == nil {
+		return nil, fmt.Errorf("tsx is an image collection and has no tileset image: %s", path)
+	}
+	return tilesetImage(tsx.Image, "tsx")
+}
+
+// tsxTileImage returns the image of a single tile of the image collection tileset loaded from the path.
+func tsxTileImage(tsx *tiled.TSX, path string, id uint32) (*ebiten.Image, error) {
+	tileImg := tsx.TileImage(id)
+	if tileImg == nil {
+		return nil, fmt.Errorf("tsx tile %d does not have an image: %s", id, path)
+	}
+	return tilesetImage(tileImg, "tsx tile")
+}
+
+// tilesetImage returns the image file the image element references, with its transparent color keyed
+// out. Images of files loaded with LoadTMXWithImages resolve within the file system they were loaded
+// from, others through finch. what names the image's owner in errors.
+func tilesetImage(img *tiled.Image, what string) (*ebiten.Image, error) {
+	src, err := img.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		return nil, fmt.Errorf("could not retrieve %s image from asset file: %s", what, img.Source())
+	}
+	return transparentImage(gpuImage(src), img), nil
+}
+
+// MustGetTX is like GetTX but panics if the asset cannot be found.
+func MustGetTX(file finch.AssetFile) *tiled.TX {
+	tx, err := GetTX(file)
+	if err != nil {
+		panic(err)
+	}
+	return tx
+}
+
+// MustGetTXTSX is like GetTXTSX but panics if the asset cannot be found.
+func MustGetTXTSX(file finch.AssetFile) *tiled.TSX {
+	tsx, err := GetTXTSX(file)
+	if err != nil {
+		panic(err)
+	}
+	return tsx
+}
+
+// MustGetTXImg is like GetTXImg but panics if the asset cannot be found.
+func MustGetTXImg(file finch.AssetFile) *ebiten.Image {
+	img, err := GetTXImg(file)
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+// MustGetTMX is like GetTMX but panics if the asset cannot be found.
+func MustGetTMX(file finch.AssetFile) *tiled.TMX {
+	tmx, err := GetTMX(file)
+	if err != nil {
+		panic(err)
+	}
+	return tmx
+}
+
+// MustGetTSX is like GetTSX but panics if the asset cannot be found.
+func MustGetTSX(file finch.AssetFile) *tiled.TSX {
+	tsx, err := GetTSX(file)
+	if err != nil {
+		panic(err)
+	}
+	return tsx
+}
+
+// MustGetTSXImg is like GetTSXImg but panics if the asset cannot be found.
+func MustGetTSXImg(file finch.AssetFile) *ebiten.Image {
+	img, err := GetTSXImg(file)
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+// MustGetTSXTileImg is like GetTSXTileImg but panics if the asset cannot be found.
+func MustGetTSXTileImg(file finch.AssetFile, id uint32) *ebiten.Image {
+	img, err := GetTSXTileImg(file, id)
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Tile Batching
+// ======================================================
+
+// maxBatchVertices is the most vertices a single DrawTriangles call can index with uint16 indices.
+const maxBatchVertices = math.MaxUint16 + 1
+
+// tileBatch collects consecutive tiles drawn from the same image with the same blend into one
+// vertex and index buffer, so a layer is drawn with a handful of DrawTriangles calls rather than
+// one DrawImage per tile. A tile from another image, or with another blend, flushes the batch
+// first, so tiles are still drawn in render order.
+type tileBatch struct {
+	dest     *ebiten.Image
+	src      *ebiten.Image
+	blend    ebiten.Blend
+	vertices []ebiten.Vertex
+	indices  []uint16
+	opts     ebiten.DrawTrianglesOptions
+
+	// Shader, when set, draws the tiles instead of DrawTriangles.
+	shader     *LayerShader
+	shaderOpts ebiten.DrawTrianglesShaderOptions
+}
+
+var tileBatches = sync.Pool{
+	New: func() any { return &tileBatch{} },
+}
+
+// acquireTileBatch returns an empty batch drawing onto dest. Return it with releaseTileBatch once flushed.
+func acquireTileBatch(dest *ebiten.Image) *tileBatch {
+	batch := tileBatches.Get().(*tileBatch)
+	batch.dest = dest
+	return batch
+}
+
+func releaseTileBatch(batch *tileBatch) {
+	batch.reset()
+	batch.dest = nil
+	batch.shader = nil
+	batch.shaderOpts = ebiten.DrawTrianglesShaderOptions{}
+	tileBatches.Put(batch)
+}
+
+func (b *tileBatch) reset() {
+	b.src = nil
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+}
+
+// add queues a tile drawn with the options' transform, color scale and blend.
+func (b *tileBatch) add(src tileSubImage, op *ebiten.DrawImageOptions) {
+	if b.src != src.parent || b.blend != op.Blend || len(b.vertices)+4 > maxBatchVertices {
+		b.flush()
+		b.src, b.blend = src.parent, op.Blend
+	}
+
+	// DrawImage applies the color scale to premultiplied colors, so the vertices carry it as is.
+	r, g, bl, a := op.ColorScale.R(), op.ColorScale.G(), op.ColorScale.B(), op.ColorScale.A()
+	w, h := float64(src.rect.Dx()), float64(src.rect.Dy())
+
+	base := uint16(len(b.vertices))
+	for _, corner := range [4]image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		dx, dy := op.GeoM.Apply(float64(corner.X)*w, float64(corner.Y)*h)
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX:   float32(dx),
+			DstY:   float32(dy),
+			SrcX:   float32(src.rect.Min.X + corner.X*src.rect.Dx()),
+			SrcY:   float32(src.rect.Min.Y + corner.Y*src.rect.Dy()),
+			ColorR: r,
+			ColorG: g,
+			ColorB: bl,
+			ColorA: a,
+		})
+	}
+	b.indices = append(b.indices, base, base+1, base+2, base+1, base+3, base+2)
+	renderStats.tilesDrawn.Add(1)
+}
+
+// flush draws every queued tile.
+func (b *tileBatch) flush() {
+	switch {
+	case len(b.indices) == 0:
+	case b.shader != nil:
+		b.shaderOpts.Blend = b.blend
+		b.shaderOpts.Uniforms = b.shader.Uniforms
+		b.shaderOpts.Images[0] = b.src
+		b.dest.DrawTrianglesShader(b.vertices, b.indices, b.shader.Shader, &b.shaderOpts)
+		renderStats.drawCalls.Add(1)
+	default:
+		b.opts.Blend = b.blend
+		b.opts.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+		b.dest.DrawTriangles(b.vertices, b.indices, b.src, &b.opts)
+		renderStats.drawCalls.Add(1)
+	}
+	b.reset()
+}
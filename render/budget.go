@@ -0,0 +1,49 @@
+package render
+
+import (
+	"sync"
+	"time"
+
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Decode Budget
+// ======================================================
+
+// decodeBudget tracks how much of the frame's DecodeBudget has been spent decoding layers and chunks
+// and rendering static buffers. It is renewed by Advance.
+var decodeBudget struct {
+	sync.Mutex
+	spent time.Duration
+}
+
+func renewDecodeBudget() {
+	decodeBudget.Lock()
+	decodeBudget.spent = 0
+	decodeBudget.Unlock()
+}
+
+// decodeBudgetLeft reports whether heavy work may start this frame. Work that starts with budget left
+// runs to completion, so every frame makes some progress even when one piece of work exceeds the budget.
+func decodeBudgetLeft() bool {
+	limit := tiled.CurrentConfig().DecodeBudget
+	if limit <= 0 {
+		return true
+	}
+
+	decodeBudget.Lock()
+	defer decodeBudget.Unlock()
+	return decodeBudget.spent < limit
+}
+
+// spendDecodeBudget charges the time since start against this frame's budget.
+func spendDecodeBudget(start time.Time) {
+	if tiled.CurrentConfig().DecodeBudget <= 0 {
+		return
+	}
+
+	decodeBudget.Lock()
+	decodeBudget.spent += time.Since(start)
+	decodeBudget.Unlock()
+}
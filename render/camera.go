@@ -0,0 +1,46 @@
+package render
+
+import (
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Cameras
+// ======================================================
+
+// Camera is anything that can describe what part of the world is seen and how it maps onto the
+// screen, such as a game's camera type.
+type Camera interface {
+	// Viewport returns the area of the world the camera sees.
+	Viewport() geom.Rect64
+
+	// ViewMatrix returns the transform from world space onto the screen.
+	ViewMatrix() ebiten.GeoM
+}
+
+// DrawSceneCamera renders the TMX map as seen through the camera like DrawScene.
+func DrawSceneCamera(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, cam Camera) {
+	viewport, view := cam.Viewport(), cam.ViewMatrix()
+	drawLayers(ctx, DrawModeScene, img, tmx, nil, &viewport, &view)
+}
+
+// DrawSceneLayerCamera renders a specific layer of the TMX map as seen through the camera like DrawSceneLayer.
+func DrawSceneLayerCamera(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, layerName string, cam Camera) {
+	viewport, view := cam.Viewport(), cam.ViewMatrix()
+	drawNamedLayer(ctx, DrawModeScene, img, tmx, nil, layerName, &viewport, &view)
+}
+
+// DrawInstanceSceneCamera renders the instance as seen through the camera like DrawSceneCamera renders a map.
+func DrawInstanceSceneCamera(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, cam Camera) {
+	viewport, view := cam.Viewport(), cam.ViewMatrix()
+	drawLayers(ctx, DrawModeScene, img, inst.TMX, inst, &viewport, &view)
+}
+
+// DrawInstanceSceneLayerCamera renders a layer of the instance as seen through the camera like DrawSceneLayerCamera renders a map layer.
+func DrawInstanceSceneLayerCamera(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, layerName string, cam Camera) {
+	viewport, view := cam.Viewport(), cam.ViewMatrix()
+	drawNamedLayer(ctx, DrawModeScene, img, inst.TMX, inst, layerName, &viewport, &view)
+}
@@ -0,0 +1,145 @@
+package render
+
+import (
+	"image/color"
+	"log/slog"
+	"math"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Object Group Drawing
+// ======================================================
+
+const (
+	// ObjectStrokeWidth is the width, in screen pixels, of shapes drawn by DrawObjectGroup.
+	ObjectStrokeWidth = 1.0
+
+	// ObjectPointSize is the size, in screen pixels, of the marker drawn for point objects.
+	ObjectPointSize = 6.0
+
+	ellipseSegments = 32
+)
+
+// DrawObjectGroup renders the shapes of an object group as outlines in the group's color:
+// rectangles, ellipses, polygons, polylines and points. Tile objects are outlined by their bounds.
+// Hidden groups and objects are skipped. This is intended for debugging, and for games that
+// draw their object layers as vector shapes.
+func DrawObjectGroup(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, groupName string, view ebiten.GeoM) {
+	drawObjectGroup(ctx, img, tmx, nil, groupName, view)
+}
+
+// DrawInstanceObjectGroup renders an object group of the instance like DrawObjectGroup renders a map's object group,
+// respecting the instance's layer visibility.
+func DrawInstanceObjectGroup(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, groupName string, view ebiten.GeoM) {
+	drawObjectGroup(ctx, img, inst.TMX, inst, groupName, view)
+}
+
+func drawObjectGroup(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, inst *tiled.MapInstance, groupName string, view ebiten.GeoM) {
+	og := tmx.ObjectGroupByName(groupName)
+	if og == nil {
+		logDraw(ctx, slog.LevelWarn, ErrLayerNotFound, groupName, slog.String("layer", groupName))
+		return
+	}
+	if !layerVisible(inst, og.Name(), og.IsVisible()) {
+		return
+	}
+
+	clr := og.Color()
+	clr.A = uint8(float64(clr.A) * og.Opacity())
+
+	for _, obj := range og.Objects {
+		if obj.IsVisible() {
+			drawObjectShape(img, tmx, obj, view, clr)
+		}
+	}
+}
+
+func drawObjectShape(img *ebiten.Image, tmx *tiled.TMX, obj *tiled.Object, view ebiten.GeoM, clr color.Color) {
+	x, y := obj.X64(), obj.Y64()
+	w, h := obj.Width64(), obj.Height64()
+
+	switch obj.Shape() {
+	case tiled.ObjectShapePoint:
+		sx, sy := view.Apply(x, y)
+		r := ObjectPointSize / 2
+		strokeScreenPath(img, []geom.Point64{
+			geom.NewPoint64(sx, sy-r),
+			geom.NewPoint64(sx+r, sy),
+			geom.NewPoint64(sx, sy+r),
+			geom.NewPoint64(sx-r, sy),
+		}, true, clr)
+	case tiled.ObjectShapePolygon:
+		strokePath(img, offsetPoints(obj.Polygon.Points(), x, y), true, view, clr)
+	case tiled.ObjectShapePolyline:
+		strokePath(img, offsetPoints(obj.Polyline.Points(), x, y), false, view, clr)
+	case tiled.ObjectShapeEllipse:
+		points := make([]geom.Point64, ellipseSegments)
+		for i := range points {
+			a := 2 * math.Pi * float64(i) / ellipseSegments
+			points[i] = geom.NewPoint64(x+w/2+math.Cos(a)*w/2, y+h/2+math.Sin(a)*h/2)
+		}
+		strokePath(img, points, true, view, clr)
+	default:
+		bounds := tmx.ObjectBounds(obj)
+		x, y = bounds.X, bounds.Y
+		strokePath(img, []geom.Point64{
+			geom.NewPoint64(x, y),
+			geom.NewPoint64(x+w, y),
+			geom.NewPoint64(x+w, y+h),
+			geom.NewPoint64(x, y+h),
+		}, true, view, clr)
+	}
+}
+
+// strokePath draws the outline through world-space points, transformed by view.
+func strokePath(img *ebiten.Image, points []geom.Point64, closed bool, view ebiten.GeoM, clr color.Color) {
+	screen := make([]geom.Point64, len(points))
+	for i, p := range points {
+		sx, sy := view.Apply(p.X, p.Y)
+		screen[i] = geom.NewPoint64(sx, sy)
+	}
+	strokeScreenPath(img, screen, closed, clr)
+}
+
+// strokeScreenPath draws the outline through screen-space points.
+func strokeScreenPath(img *ebiten.Image, points []geom.Point64, closed bool, clr color.Color) {
+	for i := 0; i+1 < len(points); i++ {
+		strokeLine(img, points[i], points[i+1], clr)
+	}
+	if closed && len(points) > 2 {
+		strokeLine(img, points[len(points)-1], points[0], clr)
+	}
+}
+
+// strokeLine draws a screen-space line by stretching the shared white pixel.
+func strokeLine(img *ebiten.Image, from, to geom.Point64, clr color.Color) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	op.GeoM.Translate(0, -0.5)
+	op.GeoM.Scale(length, ObjectStrokeWidth)
+	op.GeoM.Rotate(math.Atan2(dy, dx))
+	op.GeoM.Translate(from.X, from.Y)
+
+	op.ColorScale.ScaleWithColor(clr)
+
+	img.DrawImage(whitePixel(), op)
+}
+
+func offsetPoints(points []geom.Point64, dx, dy float64) []geom.Point64 {
+	for i := range points {
+		points[i] = geom.NewPoint64(points[i].X+dx, points[i].Y+dy)
+	}
+	return points
+}
@@ -0,0 +1,199 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// ======================================================
+// Debug Overlay
+// ======================================================
+
+const (
+	// DebugMinGridSpacing is the smallest size, in screen pixels, cells are drawn at before DebugDraw
+	// leaves out grid lines, which would otherwise cover the screen when zoomed out.
+	DebugMinGridSpacing = 4.0
+
+	// DebugMinLabelSpacing is the smallest size, in screen pixels, cells are drawn at before DebugDraw
+	// leaves out cell labels.
+	DebugMinLabelSpacing = 48.0
+)
+
+var (
+	DebugGridColor  = color.RGBA{R: 255, G: 255, B: 255, A: 64}
+	DebugChunkColor = color.RGBA{R: 255, G: 160, B: 0, A: 192}
+)
+
+// DebugOptions selects what DebugDraw draws.
+type DebugOptions struct {
+	// Grid draws the lines between cells.
+	Grid bool
+
+	// Chunks draws the borders of the chunks of infinite maps.
+	Chunks bool
+
+	// Coordinates labels each cell with its tile coordinates.
+	Coordinates bool
+
+	// GIDs labels each cell with the GID of its tile in the layer named by Layer.
+	GIDs bool
+
+	// Layer names the tile layer whose GIDs are labeled. The first tile layer is used if empty.
+	Layer string
+}
+
+// DebugDraw draws an overlay of the map's cells over the viewport, transformed by the view matrix
+// like DrawScene: grid lines, chunk borders, and cell labels, as selected by the options. Lines are
+// left out when zoomed out too far for them to be told apart, labels well before that.
+func DebugDraw(img *ebiten.Image, tmx *tiled.TMX, viewport geom.Rect64, viewMatrix ebiten.GeoM, opts DebugOptions) {
+	tw, th := float64(tmx.TileWidth()), float64(tmx.TileHeight())
+	if tw <= 0 || th <= 0 {
+		return
+	}
+
+	a, b, c, d := viewMatrix.Element(0, 0), viewMatrix.Element(0, 1), viewMatrix.Element(1, 0), viewMatrix.Element(1, 1)
+	scale := math.Sqrt(math.Abs(a*d - b*c))
+	spacing := min(tw, th) * scale
+
+	minx, miny := viewport.Min()
+	maxx, maxy := viewport.Max()
+	cols := [2]int{int(math.Floor(minx / tw)), int(math.Ceil(maxx / tw))}
+	rows := [2]int{int(math.Floor(miny / th)), int(math.Ceil(maxy / th))}
+
+	if opts.Grid && spacing >= DebugMinGridSpacing {
+		for x := cols[0]; x <= cols[1]; x++ {
+			wx := float64(x) * tw
+			strokePath(img, []geom.Point64{geom.NewPoint64(wx, miny), geom.NewPoint64(wx, maxy)}, false, viewMatrix, DebugGridColor)
+		}
+		for y := rows[0]; y <= rows[1]; y++ {
+			wy := float64(y) * th
+			strokePath(img, []geom.Point64{geom.NewPoint64(minx, wy), geom.NewPoint64(maxx, wy)}, false, viewMatrix, DebugGridColor)
+		}
+	}
+
+	if opts.Chunks && tmx.IsInfinite() {
+		drawDebugChunks(img, tmx, &viewport, viewMatrix)
+	}
+
+	if (opts.Coordinates || opts.GIDs) && spacing >= DebugMinLabelSpacing {
+		var layer *tiled.Layer
+		if opts.GIDs {
+			layer = debugLayer(tmx, opts.Layer)
+		}
+
+		for y := rows[0]; y < rows[1]; y++ {
+			for x := cols[0]; x < cols[1]; x++ {
+				var label string
+				if opts.Coordinates {
+					label = fmt.Sprintf("%d,%d", x, y)
+				}
+				if layer != nil {
+					if data, err := layer.GetTileGID(x, y); err == nil && data&tiled.TILE_ID_MASK != 0 {
+						if label != "" {
+							label += "\n"
+						}
+						label += fmt.Sprintf("#%d", data&tiled.TILE_ID_MASK)
+					}
+				}
+				if label == "" {
+					continue
+				}
+				sx, sy := viewMatrix.Apply(float64(x)*tw, float64(y)*th)
+				ebitenutil.DebugPrintAt(img, label, int(sx)+2, int(sy))
+			}
+		}
+	}
+}
+
+// drawDebugChunks outlines the chunks of every tile layer that overlap the viewport, once each.
+func drawDebugChunks(img *ebiten.Image, tmx *tiled.TMX, viewport *geom.Rect64, viewMatrix ebiten.GeoM) {
+	tw, th := float64(tmx.TileWidth()), float64(tmx.TileHeight())
+	seen := make(map[geom.Rect64]bool)
+
+	for _, layer := range tmx.Layers {
+		if layer.Data == nil {
+			continue
+		}
+		for _, chunk := range layer.Data.Chunks {
+			rect := geom.NewRect64(float64(chunk.X())*tw, float64(chunk.Y())*th, float64(chunk.Width())*tw, float64(chunk.Height())*th)
+			if seen[rect] || !viewport.Intersects(rect) {
+				continue
+			}
+			seen[rect] = true
+
+			minx, miny := rect.Min()
+			maxx, maxy := rect.Max()
+			strokePath(img, []geom.Point64{
+				geom.NewPoint64(minx, miny),
+				geom.NewPoint64(maxx, miny),
+				geom.NewPoint64(maxx, maxy),
+				geom.NewPoint64(minx, maxy),
+			}, true, viewMatrix, DebugChunkColor)
+		}
+	}
+}
+
+// debugLayer returns the named tile layer, or the first tile layer if name is empty.
+func debugLayer(tmx *tiled.TMX, name string) *tiled.Layer {
+	if name != "" {
+		return tmx.LayerByName(name)
+	}
+	if len(tmx.Layers) > 0 {
+		return tmx.Layers[0]
+	}
+	return nil
+}
+
+// DebugObjectGizmoLength is the length, in screen pixels, of the line DebugDrawObjects draws from each
+// object's anchor in the direction it is rotated to.
+const DebugObjectGizmoLength = 16.0
+
+// DebugDrawObjects draws the objects of every visible object group, transformed by the view matrix
+// like DrawScene: each shape outlined and rotated like the editor shows it, a cross on its anchor,
+// a line pointing along its rotation, and its name beside the anchor.
+func DebugDrawObjects(img *ebiten.Image, tmx *tiled.TMX, viewMatrix ebiten.GeoM) {
+	for _, og := range tmx.ObjectGroups {
+		if !og.IsVisible() {
+			continue
+		}
+		clr := og.Color()
+
+		for _, obj := range og.Objects {
+			if !obj.IsVisible() {
+				continue
+			}
+
+			// Objects rotate around their position.
+			x, y := obj.X64(), obj.Y64()
+			var objView ebiten.GeoM
+			objView.Translate(-x, -y)
+			objView.Rotate(obj.Rotation() * math.Pi / 180)
+			objView.Translate(x, y)
+			objView.Concat(viewMatrix)
+
+			drawObjectShape(img, tmx, obj, objView, clr)
+
+			sx, sy := viewMatrix.Apply(x, y)
+			r := ObjectPointSize / 2
+			strokeLine(img, geom.NewPoint64(sx-r, sy), geom.NewPoint64(sx+r, sy), clr)
+			strokeLine(img, geom.NewPoint64(sx, sy-r), geom.NewPoint64(sx, sy+r), clr)
+
+			// The gizmo points along the object's x axis on screen, whatever the view's zoom.
+			gx, gy := objView.Apply(x+1, y)
+			if dx, dy := gx-sx, gy-sy; dx != 0 || dy != 0 {
+				length := math.Hypot(dx, dy)
+				strokeLine(img, geom.NewPoint64(sx, sy), geom.NewPoint64(sx+dx/length*DebugObjectGizmoLength, sy+dy/length*DebugObjectGizmoLength), clr)
+			}
+
+			if name := obj.Name(); name != "" {
+				ebitenutil.DebugPrintAt(img, name, int(sx)+int(r)+2, int(sy))
+			}
+		}
+	}
+}
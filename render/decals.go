@@ -0,0 +1,174 @@
+package render
+
+import (
+	"math"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Decals
+// ======================================================
+
+// MaxDecalsPerLayer caps how many decals a layer of an instance keeps.
+// Stamping beyond the cap drops the oldest decal first.
+const MaxDecalsPerLayer = 256
+
+// Decal is an image stamped into map space, such as a scorch mark or a footprint.
+// Decals are drawn directly above the layer they were stamped on, so they scroll and
+// zoom with the map and are occluded by the layers above it.
+type Decal struct {
+	Image *ebiten.Image
+
+	// X and Y position the decal's top-left corner in world space.
+	X, Y float64
+
+	// Transform is applied in decal-local space before the decal is positioned, e.g. to rotate it.
+	Transform ebiten.GeoM
+
+	// Lifetime is how long the decal lasts. Zero keeps the decal until it is cleared.
+	Lifetime time.Duration
+
+	// FadeOut is how long before the end of its lifetime the decal starts fading out.
+	FadeOut time.Duration
+
+	age  time.Duration
+	seen time.Duration // Instance clock the age was last brought up to.
+}
+
+// alpha returns the decal's opacity at its current age.
+func (d *Decal) alpha() float32 {
+	if d.Lifetime <= 0 || d.FadeOut <= 0 {
+		return 1
+	}
+	remaining := d.Lifetime - d.age
+	if remaining >= d.FadeOut {
+		return 1
+	}
+	return float32(remaining) / float32(d.FadeOut)
+}
+
+// bounds returns the decal's transformed bounds in world space.
+func (d *Decal) bounds() geom.Rect64 {
+	w, h := float64(d.Image.Bounds().Dx()), float64(d.Image.Bounds().Dy())
+	corners := make([]geom.Point64, 0, 4)
+	for _, c := range [][2]float64{{0, 0}, {w, 0}, {w, h}, {0, h}} {
+		x, y := d.Transform.Apply(c[0], c[1])
+		corners = append(corners, geom.NewPoint64(d.X+x, d.Y+y))
+	}
+	return pointBounds(corners)
+}
+
+func pointBounds(points []geom.Point64) geom.Rect64 {
+	if len(points) == 0 {
+		return geom.Rect64{}
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	return geom.NewRect64(minX, minY, maxX-minX, maxY-minY)
+}
+
+// StampDecal adds a decal above the named layer of the instance. Decals age with the instance's clock,
+// which MapInstance.Update advances.
+func StampDecal(inst *tiled.MapInstance, layerName string, decal Decal) {
+	if decal.Image == nil {
+		return
+	}
+	state := instanceStates.getOrCreate(inst)
+	updateDecals(inst, state)
+	if state.decals == nil {
+		state.decals = make(map[string][]*Decal)
+	}
+	decal.age, decal.seen = 0, inst.Clock()
+	decals := state.decals[layerName]
+	if len(decals) >= MaxDecalsPerLayer {
+		decals = append(decals[:0], decals[len(decals)-MaxDecalsPerLayer+1:]...)
+	}
+	state.decals[layerName] = append(decals, &decal)
+}
+
+// ClearDecals removes every decal stamped above the named layer of the instance.
+func ClearDecals(inst *tiled.MapInstance, layerName string) {
+	if state := instanceStates.get(inst); state != nil {
+		delete(state.decals, layerName)
+	}
+}
+
+// Decals returns how many decals are stamped above the named layer of the instance.
+func Decals(inst *tiled.MapInstance, layerName string) int {
+	state := instanceStates.get(inst)
+	if state == nil {
+		return 0
+	}
+	updateDecals(inst, state)
+	return len(state.decals[layerName])
+}
+
+// updateDecals ages every decal by the time the instance's clock advanced since it was last seen and
+// drops the ones whose lifetime has ended. A clock moved back by Rewind does not make decals younger.
+func updateDecals(inst *tiled.MapInstance, state *instanceState) {
+	clock := inst.Clock()
+	for layerName, decals := range state.decals {
+		alive := decals[:0]
+		for _, decal := range decals {
+			decal.age += max(clock-decal.seen, 0)
+			decal.seen = clock
+			if decal.Lifetime > 0 && decal.age >= decal.Lifetime {
+				continue
+			}
+			alive = append(alive, decal)
+		}
+		clear(decals[len(alive):])
+		if len(alive) == 0 {
+			delete(state.decals, layerName)
+			continue
+		}
+		state.decals[layerName] = alive
+	}
+}
+
+func drawDecals(mode DrawMode, destImg *ebiten.Image, inst *tiled.MapInstance, layerName string, region *geom.Rect64, view *ebiten.GeoM) {
+	if inst == nil {
+		return
+	}
+	state := instanceStates.get(inst)
+	if state == nil || len(state.decals[layerName]) == 0 {
+		return
+	}
+	updateDecals(inst, state)
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	for _, decal := range state.decals[layerName] {
+		if !decal.bounds().Intersects(*region) {
+			continue
+		}
+
+		op.GeoM = decal.Transform
+		op.ColorScale.Reset()
+		op.ColorScale.ScaleAlpha(decal.alpha())
+
+		switch mode {
+		case DrawModeNormal:
+			op.GeoM.Translate(decal.X, decal.Y)
+		case DrawModeRegional:
+			minx, miny := region.Min()
+			op.GeoM.Translate(decal.X-minx, decal.Y-miny)
+		case DrawModeScene:
+			op.GeoM.Translate(decal.X, decal.Y)
+			op.GeoM.Concat(*view)
+		default:
+			panic("unhandled draw mode")
+		}
+
+		destImg.DrawImage(decal.Image, op)
+	}
+}
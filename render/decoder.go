@@ -0,0 +1,119 @@
+package render
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Background Chunk Decoding
+// ======================================================
+
+// chunkDecodeSlots bounds how many chunks decode at once across every layer.
+var chunkDecodeSlots = make(chan struct{}, runtime.GOMAXPROCS(0))
+
+// chunkDecoder tracks the chunks of a layer being decoded in the background.
+// A chunk is requested the first time it is drawn and handed to the layer once ready.
+type chunkDecoder struct {
+	mu      sync.Mutex
+	pending map[geom.Rect64]struct{}
+	ready   []decodedChunk
+}
+
+type decodedChunk struct {
+	rect       geom.Rect64
+	tiles      *tileBlock
+	generation int
+	err        error
+}
+
+// request starts decoding a chunk unless it is already on its way.
+func (d *chunkDecoder) request(rect geom.Rect64, generation int, decode func() (*tileBlock, error)) {
+	d.mu.Lock()
+	if _, exists := d.pending[rect]; exists {
+		d.mu.Unlock()
+		return
+	}
+	d.pending[rect] = struct{}{}
+	d.mu.Unlock()
+
+	go func() {
+		chunkDecodeSlots <- struct{}{}
+		tiles, err := decode()
+		<-chunkDecodeSlots
+
+		d.mu.Lock()
+		d.ready = append(d.ready, decodedChunk{rect: rect, tiles: tiles, generation: generation, err: err})
+		d.mu.Unlock()
+	}()
+}
+
+// take returns the chunks that finished decoding since the last call.
+func (d *chunkDecoder) take() []decodedChunk {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ready := d.ready
+	d.ready = nil
+	for _, chunk := range ready {
+		delete(d.pending, chunk.rect)
+	}
+	return ready
+}
+
+// collectDecodedChunks moves chunks that finished decoding into the layer's partitions. Chunks decoded
+// from data that has changed since they were requested are dropped and requested again when drawn.
+func (state *layerState) collectDecodedChunks(layer *tiled.Layer) error {
+	if state.decoder == nil {
+		return nil
+	}
+
+	var firstErr error
+	for _, chunk := range state.decoder.take() {
+		if chunk.generation != layer.Generation() {
+			continue
+		}
+		if chunk.err != nil {
+			if firstErr == nil {
+				firstErr = chunk.err
+			}
+			continue
+		}
+		state.partitions[chunk.rect] = chunk.tiles
+		renderStats.chunksDecoded.Add(1)
+	}
+	return firstErr
+}
+
+// requestChunk queues a chunk of the layer for decoding in the background.
+func (state *layerState) requestChunk(layer *tiled.Layer, rect geom.Rect64, chunk *tiled.DataChunk, tilesets []*tiled.Tileset, cellWidth, cellHeight int) {
+	if state.decoder == nil {
+		state.decoder = &chunkDecoder{pending: make(map[geom.Rect64]struct{})}
+	}
+
+	raw, format, tilesets := chunk.Data, layer.Data.Format(), slices.Clone(tilesets)
+	state.decoder.request(rect, layer.Generation(), func() (*tileBlock, error) {
+		parsedData, err := tiled.DecodeData(raw, format)
+		if err != nil {
+			return nil, err
+		}
+		return decodeTiles(parsedData, tilesets, int(rect.X), int(rect.Y), int(rect.Width), int(rect.Height), cellWidth, cellHeight)
+	})
+}
+
+// PendingChunks returns how many chunks of the layer are still decoding in the background.
+// It is always zero unless Config.AsyncChunkDecode is set.
+func PendingChunks(layer *tiled.Layer) int {
+	state := layerStates.get(layer)
+	if state == nil || state.decoder == nil {
+		return 0
+	}
+
+	state.decoder.mu.Lock()
+	defer state.decoder.mu.Unlock()
+	return len(state.decoder.pending)
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Dependency Loading
+// ======================================================
+
+// LoadMapWithDependencies loads a TMX map through finch's asset system along with everything it
+// references: its tilesets, the templates of its objects and their tilesets, and the images of
+// tilesets and image layers. Files already loaded are left as they are, so it can be called for
+// maps sharing tilesets. Loading fails up front with every missing file rather than at first draw,
+// and the loaded map is checked with Validate, its issues returned as the error.
+// Image files need finch's image importer registered. With PredecodeLayers set, the map's tilesets
+// must already be loaded, since layers are decoded as the map is imported.
+func LoadMapWithDependencies(file finch.AssetFile) (*tiled.TMX, error) {
+	tmx, _, err := loadMapDependencies(file)
+	return tmx, err
+}
+
+// loadMapDependencies loads the map like LoadMapWithDependencies and returns every file it references,
+// in the order they were loaded.
+func loadMapDependencies(file finch.AssetFile) (*tiled.TMX, []finch.AssetFile, error) {
+	if err := loadMissing(file); err != nil {
+		return nil, nil, err
+	}
+	tmx, err := GetTMX(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tilesets, templates []finch.AssetFile
+	for _, tileset := range tmx.Tilesets {
+		if tileset.Source() != "" {
+			tilesets = append(tilesets, finch.AssetFile(tileset.Source()))
+		}
+	}
+	for _, og := range tmx.ObjectGroups {
+		for _, obj := range og.Objects {
+			if obj.HasTemplate() {
+				templates = append(templates, finch.AssetFile(obj.Template()))
+			}
+		}
+	}
+	if err := loadMissing(append(tilesets, templates...)...); err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range templates {
+		if tx, err := GetTX(file); err == nil && tx.Tileset != nil && tx.Tileset.Source() != "" {
+			tilesets = append(tilesets, finch.AssetFile(tx.Tileset.Source()))
+		}
+	}
+	if err := loadMissing(tilesets...); err != nil {
+		return nil, nil, err
+	}
+
+	var images []finch.AssetFile
+	for _, file := range tilesets {
+		tsx, err := GetTSX(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tsx.Image != nil {
+			images = append(images, finch.AssetFile(tsx.Image.Source()))
+		}
+		for _, tile := range tsx.Tiles {
+			if tile.Image != nil {
+				images = append(images, finch.AssetFile(tile.Image.Source()))
+			}
+		}
+	}
+	for _, layer := range tmx.ImageLayers {
+		if layer.Image != nil && layer.Image.Source() != "" {
+			images = append(images, finch.AssetFile(layer.Image.Source()))
+		}
+	}
+	if err := loadMissing(images...); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tmx.Validate().Err(); err != nil {
+		return nil, nil, fmt.Errorf("invalid map %s: %w", file.Path(), err)
+	}
+
+	deps := append(append(templates, tilesets...), images...)
+	return tmx, uniqueFiles(deps), nil
+}
+
+// loadMissing loads the files that are not loaded yet, once each.
+func loadMissing(files ...finch.AssetFile) error {
+	var missing []finch.AssetFile
+	for _, file := range uniqueFiles(files) {
+		if _, err := file.Get(); err != nil {
+			missing = append(missing, file)
+		}
+	}
+	return finch.LoadAssets(missing...)
+}
+
+// uniqueFiles returns the files with repeats left out, in order.
+func uniqueFiles(files []finch.AssetFile) []finch.AssetFile {
+	seen := make(map[finch.AssetFile]bool, len(files))
+	unique := make([]finch.AssetFile, 0, len(files))
+	for _, file := range files {
+		if !seen[file] {
+			seen[file] = true
+			unique = append(unique, file)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,853 @@
+// Package render draws maps loaded by package tiled with ebiten, and loads them through finch's asset
+// system. Draw settings, such as shaders and tile colors, and the tiles and buffers built for drawing
+// are kept by this package rather than on the maps, so package tiled stays free of both.
+package render
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/fsys"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TASK: Implement support for all encoding/compression types Tiled supports.
+//     - Probably a good idea to support as many features of Tiled as possible - this goes beyond just encoding/compression.
+
+// TASK: Implement support for isometric and staggered maps.
+//     - This early in development, it's really just a nice to have - but would be useful for certain types of games.
+
+// TASK: Implement support for dynamically modifying tilemaps (e.g., changing tiles at runtime).
+//     - Another nice to have, but could be useful for games that feature destructible environments or tile-based puzzles.
+
+// TASK: Implement new core hashgrid to optimize chunk and tile selection
+
+// TASK: Look into caching
+
+const (
+	ErrWhileDrawingLayer  = "tiled: error while drawing layer"
+	ErrLayerNotFound      = "tiled: layer not found"
+	ErrDecodingObjectTile = "tiled: error decoding object tile"
+	ErrDrawingObjectTile  = "tiled: error drawing object tile"
+)
+
+type DrawMode int
+
+const (
+	DrawModeNormal DrawMode = iota
+	DrawModeRegional
+	DrawModeScene
+)
+
+// EmptyCellFunc draws filler content for an empty cell of a tile layer.
+// geoM maps the cell's top-left corner, in cell-sized local space, onto the destination image.
+type EmptyCellFunc func(dst *ebiten.Image, cell tiled.Cell, geoM ebiten.GeoM)
+
+// SetEmptyCellFunc registers a function that is called, before the layer's tiles are drawn, for every
+// empty cell of the layer in the drawn region. If fillerGID is not zero, cells containing that GID are
+// treated as empty too and their tile is not drawn, so a placeholder tile can mark where filler goes.
+// Passing a nil function removes the hook.
+func SetEmptyCellFunc(layer *tiled.Layer, fn EmptyCellFunc, fillerGID uint32) {
+	state := stateOf(layer)
+	state.emptyCellFunc = fn
+	state.fillerGID = fillerGID
+}
+
+// drawOptions pools the options filled in by each draw call, so draws to different targets can run
+// on different goroutines without sharing them.
+var drawOptions = sync.Pool{
+	New: func() any { return &ebiten.DrawImageOptions{} },
+}
+
+// acquireDrawOptions returns cleared draw options. Return them with releaseDrawOptions once drawn.
+func acquireDrawOptions() *ebiten.DrawImageOptions {
+	op := drawOptions.Get().(*ebiten.DrawImageOptions)
+	*op = ebiten.DrawImageOptions{}
+	return op
+}
+
+func releaseDrawOptions(op *ebiten.DrawImageOptions) {
+	drawOptions.Put(op)
+}
+
+// whitePixel returns a shared 1x1 white image used to draw solid shapes.
+var whitePixel = sync.OnceValue(func() *ebiten.Image {
+	pixel := ebiten.NewImage(1, 1)
+	pixel.Fill(color.White)
+	return pixel
+})
+
+// Draw attempts to render the entire TMX map onto the provided image.
+// If the map is larger than the image, only the top-left portion will be drawn.
+func Draw(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX) {
+	region := geom.NewRect64(0, 0, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
+	drawLayers(ctx, DrawModeNormal, img, tmx, nil, &region, &ebiten.GeoM{})
+}
+
+// DrawLayer attempts to render a specific layer of the TMX map onto the provided image.
+// If the map is larger than the image, only the top-left portion will be drawn.
+func DrawLayer(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, layerName string) {
+	region := geom.NewRect64(0, 0, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
+	drawNamedLayer(ctx, DrawModeNormal, img, tmx, nil, layerName, &region, &ebiten.GeoM{})
+}
+
+// DrawRegion renders only the specified region of the TMX map onto the provided image.
+func DrawRegion(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, region geom.Rect64) {
+	drawLayers(ctx, DrawModeRegional, img, tmx, nil, &region, &ebiten.GeoM{})
+}
+
+// DrawLayerRegion renders only the specified region of a specific layer of the TMX map onto the provided image.
+func DrawLayerRegion(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, layerName string, region geom.Rect64) {
+	drawNamedLayer(ctx, DrawModeRegional, img, tmx, nil, layerName, &region, &ebiten.GeoM{})
+}
+
+// DrawScene renders the TMX map as seen through a camera, using the provided viewport and view matrix.
+// This is typically used for rendering the map in a game scene where the camera can move and zoom.
+func DrawScene(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, viewport geom.Rect64, viewMatrix ebiten.GeoM) {
+	drawLayers(ctx, DrawModeScene, img, tmx, nil, &viewport, &viewMatrix)
+}
+
+// DrawSceneLayer renders a specific layer of the TMX map as seen through a camera, using the provided viewport and view matrix.
+// This is typically used for rendering the map in a game scene where the camera can move and zoom.
+func DrawSceneLayer(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, layerName string, viewport geom.Rect64, viewMatrix ebiten.GeoM) {
+	drawNamedLayer(ctx, DrawModeScene, img, tmx, nil, layerName, &viewport, &viewMatrix)
+}
+
+// layerVisible resolves a layer's visibility, falling back to the authored visibility when the
+// instance is nil.
+func layerVisible(inst *tiled.MapInstance, layerName string, authored bool) bool {
+	if inst == nil {
+		return authored
+	}
+	return inst.IsLayerVisible(layerName)
+}
+
+// drawLayers renders every tile and image layer of the map in document order.
+func drawLayers(ctx finch.Context, mode DrawMode, img *ebiten.Image, tmx *tiled.TMX, inst *tiled.MapInstance, region *geom.Rect64, view *ebiten.GeoM) {
+	defer metricsObserve(tiled.MetricDrawTime, metricsStart())
+
+	for _, l := range tmx.OrderedLayers() {
+		switch layer := l.(type) {
+		case *tiled.Layer:
+			if err := drawMapLayer(mode, img, layer, inst, tmx.Tilesets, region, view, tmx.TileWidth(), tmx.TileHeight(), tmx.IsInfinite(), tmx.RenderOrder(), nil); err != nil {
+				logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+			}
+			if layerVisible(inst, layer.Name(), layer.IsVisible()) {
+				drawDecals(mode, img, inst, layer.Name(), region, view)
+			}
+		case *tiled.ImageLayer:
+			if err := drawImageLayer(mode, img, layer, inst, region, view); err != nil {
+				logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+			}
+			if layerVisible(inst, layer.Name(), layer.IsVisible()) {
+				drawDecals(mode, img, inst, layer.Name(), region, view)
+			}
+		}
+	}
+}
+
+// drawNamedLayer renders the tile layer, or failing that the image layer, with the given name.
+func drawNamedLayer(ctx finch.Context, mode DrawMode, img *ebiten.Image, tmx *tiled.TMX, inst *tiled.MapInstance, layerName string, region *geom.Rect64, view *ebiten.GeoM) {
+	defer metricsObserve(tiled.MetricDrawTime, metricsStart())
+
+	if layer := tmx.LayerByName(layerName); layer != nil {
+		if err := drawMapLayer(mode, img, layer, inst, tmx.Tilesets, region, view, tmx.TileWidth(), tmx.TileHeight(), tmx.IsInfinite(), tmx.RenderOrder(), nil); err != nil {
+			logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+		}
+		if layerVisible(inst, layer.Name(), layer.IsVisible()) {
+			drawDecals(mode, img, inst, layer.Name(), region, view)
+		}
+		return
+	}
+	if layer := tmx.ImageLayerByName(layerName); layer != nil {
+		if err := drawImageLayer(mode, img, layer, inst, region, view); err != nil {
+			logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+		}
+		if layerVisible(inst, layer.Name(), layer.IsVisible()) {
+			drawDecals(mode, img, inst, layer.Name(), region, view)
+		}
+		return
+	}
+	logDraw(ctx, slog.LevelWarn, ErrLayerNotFound, layerName, slog.String("layer", layerName))
+}
+
+// DrawObject renders a specific drawable object from the TMX map using the provided view matrix.
+// The transform positions the object's anchor, which the tileset's object alignment places on the tile.
+func DrawObject(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, obj *tiled.Object, transform ebiten.GeoM, view ebiten.GeoM) {
+	if obj == nil {
+		return // Nothing to draw
+	}
+
+	width, height := obj.Width64(), obj.Height64()
+
+	// Template instances without a GID of their own draw their template's tile, which references
+	// the template's tileset rather than the map's.
+	gid, tilesets := uint32(obj.GID()), tmx.Tilesets
+	if obj.HasTemplate() && (gid == 0 || width == 0 || height == 0) {
+		tx, err := obj.TX()
+		if err != nil {
+			logDraw(ctx, slog.LevelError, ErrDecodingObjectTile, obj.Template(), slog.String("template", obj.Template()), slog.Any("error", err))
+			return
+		}
+		if tx.Object != nil {
+			if width == 0 || height == 0 {
+				width, height = tx.Object.Width64(), tx.Object.Height64()
+			}
+			if gid == 0 && tx.Tileset != nil {
+				gid, tilesets = uint32(tx.Object.GID()), []*tiled.Tileset{tx.Tileset}
+			}
+		}
+	}
+	if gid == 0 {
+		return // Nothing to draw
+	}
+
+	// The tile is cached for the object drawn, not for its template, so each instance decodes it once.
+	tile := objectTiles.get(obj)
+	if tile == nil {
+		decoded, err := tiled.TileOf(gid, tilesets, tmx.TileHeight())
+		if err != nil {
+			logDraw(ctx, slog.LevelError, ErrDecodingObjectTile, strconv.Itoa(int(gid)), slog.Int("gid", int(gid)), slog.Any("error", err))
+			return
+		}
+		if decoded == nil {
+			return // Nothing to draw
+		}
+
+		tile = objectTiles.getOrCreate(obj)
+		*tile = *decoded
+	}
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	// Tiled stretches tile objects to the object's size.
+	if width > 0 && height > 0 && tile.Width > 0 && tile.Height > 0 {
+		op.GeoM.Scale(width/tile.Width, height/tile.Height)
+	} else {
+		width, height = tile.Width, tile.Height
+	}
+
+	// The object's position is its anchor, set by the tileset's object alignment.
+	anchor := tmx.ObjectAnchor(gid, tilesets)
+	op.GeoM.Translate(-anchor.X*width, -anchor.Y*height)
+
+	op.GeoM.Concat(transform)
+	op.GeoM.Concat(view)
+
+	if err := drawTile(img, tile, tilesets, tmx.TileWidth(), tmx.TileHeight(), op); err != nil {
+		logDraw(ctx, slog.LevelError, ErrDrawingObjectTile, strconv.Itoa(int(gid)), slog.Int("gid", int(gid)), slog.Any("error", err))
+	}
+}
+
+// DrawObjects renders the visible tile objects of an object group in the group's draw order,
+// each positioned at its anchor and transformed by the view matrix.
+func DrawObjects(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, groupName string, view ebiten.GeoM) {
+	drawObjects(ctx, img, tmx, nil, groupName, view)
+}
+
+// DrawInstanceObjects renders the tile objects of an instance's object group like DrawObjects renders
+// a map's, respecting the instance's layer visibility.
+func DrawInstanceObjects(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, groupName string, view ebiten.GeoM) {
+	drawObjects(ctx, img, inst.TMX, inst, groupName, view)
+}
+
+func drawObjects(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, inst *tiled.MapInstance, groupName string, view ebiten.GeoM) {
+	og := tmx.ObjectGroupByName(groupName)
+	if og == nil {
+		logDraw(ctx, slog.LevelWarn, ErrLayerNotFound, groupName, slog.String("layer", groupName))
+		return
+	}
+	if !layerVisible(inst, og.Name(), og.IsVisible()) {
+		return
+	}
+
+	var transform ebiten.GeoM
+	for _, obj := range og.OrderedObjects() {
+		if !obj.IsVisible() || (obj.GID() == 0 && !obj.HasTemplate()) {
+			continue
+		}
+		transform.Reset()
+		transform.Rotate(obj.Rotation() * math.Pi / 180)
+		transform.Translate(obj.X64(), obj.Y64())
+		DrawObject(ctx, img, tmx, obj, transform, view)
+	}
+}
+
+// drawMapLayer draws the tiles of a layer overlapping the region. When sprites, sorted by Y, are
+// given, they are drawn among the tiles, which are then ordered by their bottom edge.
+func drawMapLayer(mode DrawMode, destImg *ebiten.Image, layer *tiled.Layer, inst *tiled.MapInstance, tilesets []*tiled.Tileset, region *geom.Rect64, view *ebiten.GeoM, cellWidth, cellHeight int, isInfinite bool, renderOrder tiled.RenderOrder, sprites []Sprite) error {
+	if !layerVisible(inst, layer.Name(), layer.IsVisible()) || len(tilesets) == 0 {
+		// Sprites are drawn even when the layer they stand among is not.
+		drawSprites(mode, destImg, sprites, math.Inf(1), nil, region, view)
+		return nil
+	}
+
+	layerWidth := layer.Width() * cellWidth
+	layerHeight := layer.Height() * cellHeight
+
+	state := stateOf(layer)
+	if err := processTiles(layer, state, tilesets, region, layerWidth, layerHeight, cellWidth, cellHeight, isInfinite, true); err != nil {
+		return err
+	}
+	defer releaseLayerCache(layer, state)
+
+	// Animated tiles change from frame to frame, so they are never baked into a static buffer.
+	// Neither are layers drawn among sprites.
+	if state.usesStaticBuffer(inst, layer.Name()) && !state.animated() && len(sprites) == 0 {
+		if isInfinite {
+			return drawChunkPages(mode, destImg, layer, state, region, view, cellWidth, cellHeight, renderOrder)
+		}
+		// Until there is budget to render the buffer, the layer is drawn tile by tile.
+		fits := layer.Width()*cellWidth <= maxStaticBufferSize && layer.Height()*cellHeight <= maxStaticBufferSize
+		if fits && (state.static != nil || decodeBudgetLeft()) {
+			return drawStaticLayer(mode, destImg, layer, state, region, view, cellWidth, cellHeight, renderOrder)
+		}
+	}
+
+	var filler tiled.TileKey
+	if state.emptyCellFunc != nil {
+		if err := drawEmptyCells(mode, destImg, layer, state, region, view, cellWidth, cellHeight, isInfinite); err != nil {
+			return err
+		}
+		filler, _ = tiled.TileKeyOf(state.fillerGID, tilesets)
+	}
+
+	tiles := collectTiles(state, region, cellWidth, cellHeight, isInfinite, renderOrder)
+	if len(sprites) > 0 {
+		sortTilesByBase(tiles)
+	}
+
+	var layerColor ebiten.ColorScale
+	layerColor.ScaleWithColor(layer.TintColor())
+	layerColor.ScaleAlpha(float32(layer.Opacity()))
+
+	layerBlend := layerBlendOf(LayerBlendMode(layer))
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	batch := acquireTileBatch(destImg)
+	defer releaseTileBatch(batch)
+	batch.shader = state.shader
+
+	var tileFunc TileDrawFunc
+	var overrides tiled.TileOverrides
+	if inst != nil {
+		tileFunc = tileFuncOf(inst, layer.Name())
+		overrides = inst.TileOverrides()
+	}
+
+	for _, ref := range tiles {
+		if len(sprites) > 0 {
+			sprites = drawSprites(mode, destImg, sprites, ref.block.base(ref.index), batch, region, view)
+		}
+
+		if state.fillerGID != 0 && ref.block.is(ref.index, filler) {
+			continue
+		}
+
+		decoded := ref.block.tile(ref.index)
+		tile := &decoded
+		if len(overrides) > 0 {
+			overridden, err := overrides.Apply(tile, inst.TMX.Tilesets)
+			if err != nil {
+				return err
+			}
+			tile = &overridden
+		}
+
+		var tileOpts *ebiten.DrawImageOptions
+		if tileFunc != nil {
+			var skip bool
+			if skip, tileOpts = tileFunc(tile, tile.Cell); skip {
+				continue
+			}
+		}
+
+		op.GeoM.Reset()
+		op.ColorScale = layerColor
+		op.Blend = layerBlend
+		state.tileColor(&op.ColorScale, tile.Cell)
+
+		flipGeoM(&op.GeoM, tile)
+		if tileOpts != nil {
+			op.GeoM.Concat(tileOpts.GeoM)
+			op.ColorScale.ScaleWithColorScale(tileOpts.ColorScale)
+			if tileOpts.Blend != (ebiten.Blend{}) {
+				op.Blend = tileOpts.Blend
+			}
+		}
+
+		switch mode {
+		case DrawModeNormal:
+			op.GeoM.Translate(tile.X, tile.Y)
+		case DrawModeRegional:
+			minx, miny := region.Min()
+			op.GeoM.Translate(tile.X-minx, tile.Y-miny)
+		case DrawModeScene:
+			op.GeoM.Translate(tile.X, tile.Y)
+			op.GeoM.Concat(*view)
+		default:
+			panic("unhandled draw mode")
+		}
+
+		src, err := tileSource(tile)
+		if err != nil {
+			return err
+		}
+
+		batch.add(src, op)
+	}
+
+	batch.flush()
+	drawSprites(mode, destImg, sprites, math.Inf(1), batch, region, view)
+	return nil
+}
+
+// flipGeoM applies the tile's flip flags to the transform, keeping the tile within its bounds.
+func flipGeoM(geoM *ebiten.GeoM, tile *tiled.Tile) {
+	// The order of operations is important here.
+	// See: https://doc.mapeditor.org/en/stable/reference/global-tile-ids/#tile-flipping
+	if tile.Flags&tiled.FLIP_DIAGONAL != 0 {
+		geoM.Rotate(fsys.HalfPi)
+		geoM.Scale(-1, 1)
+		geoM.Translate(float64(tile.Height-tile.Width), 0)
+	}
+	if tile.Flags&tiled.FLIP_HORIZONTAL != 0 {
+		geoM.Scale(-1, 1)
+		geoM.Translate(float64(tile.Width), 0)
+	}
+	if tile.Flags&tiled.FLIP_VERTICAL != 0 {
+		geoM.Scale(1, -1)
+		geoM.Translate(0, float64(tile.Height))
+	}
+}
+
+func drawEmptyCells(mode DrawMode, destImg *ebiten.Image, layer *tiled.Layer, state *layerState, region *geom.Rect64, view *ebiten.GeoM, cellWidth, cellHeight int, isInfinite bool) error {
+	if cellWidth <= 0 || cellHeight <= 0 {
+		return nil
+	}
+
+	minx, miny := region.Min()
+	maxx, maxy := region.Max()
+
+	startX, startY := int(math.Floor(minx/float64(cellWidth))), int(math.Floor(miny/float64(cellHeight)))
+	endX, endY := int(math.Ceil(maxx/float64(cellWidth))), int(math.Ceil(maxy/float64(cellHeight)))
+
+	if !isInfinite {
+		startX, startY = max(startX, 0), max(startY, 0)
+		endX, endY = min(endX, layer.Width()), min(endY, layer.Height())
+	}
+
+	var geoM ebiten.GeoM
+
+	for y := startY; y < endY; y++ {
+		for x := startX; x < endX; x++ {
+			data, err := layer.GetTileGID(x, y)
+			if err != nil {
+				return err
+			}
+
+			if gid := data & tiled.TILE_ID_MASK; gid != 0 && gid != state.fillerGID {
+				continue
+			}
+
+			geoM.Reset()
+			geoM.Translate(float64(x*cellWidth), float64(y*cellHeight))
+
+			switch mode {
+			case DrawModeNormal:
+			case DrawModeRegional:
+				geoM.Translate(-minx, -miny)
+			case DrawModeScene:
+				geoM.Concat(*view)
+			default:
+				panic("unhandled draw mode")
+			}
+
+			state.emptyCellFunc(destImg, tiled.Cell{X: x, Y: y}, geoM)
+		}
+	}
+
+	return nil
+}
+
+func drawImageLayer(mode DrawMode, destImg *ebiten.Image, layer *tiled.ImageLayer, inst *tiled.MapInstance, region *geom.Rect64, view *ebiten.GeoM) error {
+	if !layerVisible(inst, layer.Name(), layer.IsVisible()) || layer.Image == nil {
+		return nil
+	}
+
+	srcImg, err := GetImageLayerImg(layer)
+	if err != nil {
+		return err
+	}
+
+	imgWidth := float64(srcImg.Bounds().Dx())
+	imgHeight := float64(srcImg.Bounds().Dy())
+
+	if imgWidth == 0 || imgHeight == 0 {
+		return nil
+	}
+
+	minx, miny := region.Min()
+	maxx, maxy := region.Max()
+
+	// Repeating images are stepped across the region, starting from the first repetition that touches it.
+	startX, endX := layer.OffsetX(), layer.OffsetX()
+	if layer.RepeatX() {
+		startX += math.Floor((minx-layer.OffsetX())/imgWidth) * imgWidth
+		endX = maxx
+	}
+	startY, endY := layer.OffsetY(), layer.OffsetY()
+	if layer.RepeatY() {
+		startY += math.Floor((miny-layer.OffsetY())/imgHeight) * imgHeight
+		endY = maxy
+	}
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	op.ColorScale.ScaleWithColor(layer.TintColor())
+	op.ColorScale.ScaleAlpha(float32(layer.Opacity()))
+	op.Blend = layerBlendOf(ImageLayerBlendMode(layer))
+
+	for y := startY; y <= endY; y += imgHeight {
+		for x := startX; x <= endX; x += imgWidth {
+			op.GeoM.Reset()
+
+			switch mode {
+			case DrawModeNormal:
+				op.GeoM.Translate(x, y)
+			case DrawModeRegional:
+				op.GeoM.Translate(x-minx, y-miny)
+			case DrawModeScene:
+				op.GeoM.Translate(x, y)
+				op.GeoM.Concat(*view)
+			default:
+				panic("unhandled draw mode")
+			}
+
+			destImg.DrawImage(srcImg, op)
+		}
+	}
+
+	return nil
+}
+
+func drawTile(destImg *ebiten.Image, tile *tiled.Tile, tilesets []*tiled.Tileset, cellWidth, cellHeight int, op *ebiten.DrawImageOptions) error {
+	if tile == nil || len(tilesets) == 0 {
+		return nil
+	}
+
+	srcImg, err := tileImage(tile)
+	if err != nil {
+		return err
+	}
+
+	destImg.DrawImage(srcImg, op)
+	return nil
+}
+
+// tileImages caches the region of the tileset image each tile is drawn with, so the same region is
+// not cut out of the tileset image every frame. Entries remember the image they were cut from and are
+// cut again if the tileset's image is replaced.
+var (
+	tileImagesMu sync.RWMutex
+	tileImages   = make(map[tiled.TileKey]tileSubImage)
+)
+
+// tileSubImage is the image a tile is drawn with, along with the image it was cut from and where.
+type tileSubImage struct {
+	parent *ebiten.Image
+	img    *ebiten.Image
+	rect   image.Rectangle
+}
+
+// tileImage returns the image a tile is drawn with: either its region of the tileset image,
+// or its own image for image collection tilesets.
+func tileImage(tile *tiled.Tile) (*ebiten.Image, error) {
+	src, err := tileSource(tile)
+	if err != nil {
+		return nil, err
+	}
+	return src.img, nil
+}
+
+// tileSource returns the image a tile is drawn with and the region of its parent image it covers.
+func tileSource(tile *tiled.Tile) (tileSubImage, error) {
+	tsx, err := tile.TSX()
+	if err != nil {
+		return tileSubImage{}, err
+	}
+	if tile.SourceRect().Empty() && tsx.IsImageCollection() {
+		img, err := tsxTileImage(tsx, tile.TsxSrc, tile.GID)
+		if err != nil {
+			return tileSubImage{}, err
+		}
+		return tileSubImage{parent: img, img: img, rect: img.Bounds()}, nil
+	}
+
+	srcImg, err := tsxImage(tsx, tile.TsxSrc)
+	if err != nil {
+		return tileSubImage{}, err
+	}
+
+	key := tiled.TileKey{Source: tile.TsxSrc, ID: tile.GID}
+
+	tileImagesMu.RLock()
+	cached, exists := tileImages[key]
+	tileImagesMu.RUnlock()
+	if exists && cached.parent == srcImg {
+		return cached, nil
+	}
+
+	rect := tile.SourceRect()
+	if rect.Empty() {
+		tilesPerRow := float64(srcImg.Bounds().Dx()) / tile.Width
+		tileX := (int(tile.GID) % int(tilesPerRow)) * int(tile.Width)
+		tileY := (int(tile.GID) / int(tilesPerRow)) * int(tile.Height)
+		rect = image.Rect(tileX, tileY, tileX+int(tile.Width), tileY+int(tile.Height))
+	}
+
+	src := tileSubImage{parent: srcImg, img: srcImg.SubImage(rect).(*ebiten.Image), rect: rect}
+
+	tileImagesMu.Lock()
+	tileImages[key] = src
+	tileImagesMu.Unlock()
+
+	return src, nil
+}
+
+// processTiles decodes the tiles of the layer the region needs. When budgeted, decoding waits for a
+// frame with DecodeBudget left.
+func processTiles(layer *tiled.Layer, state *layerState, tilesets []*tiled.Tileset, region *geom.Rect64, layerWidth, layerHeight, cellWidth, cellHeight int, isInfinite, budgeted bool) error {
+	if isInfinite {
+		return processChunks(layer, state, tilesets, region, layerWidth, layerHeight, cellWidth, cellHeight, budgeted)
+	}
+
+	// Already processed
+	if state.tiles != nil {
+		statsCache(true)
+		return nil
+	}
+
+	if layer.Data == nil {
+		return nil
+	}
+	if budgeted && !decodeBudgetLeft() {
+		return nil
+	}
+	defer spendDecodeBudget(time.Now())
+
+	// The decoded cells are kept on the layer, so rebuilding the tiles, after an edit or when the
+	// tile cache is disabled, does not parse the layer data again.
+	gids, err := layer.GIDs()
+	if err != nil {
+		return err
+	}
+
+	statsCache(false)
+	tiles, err := decodeTiles(gids, tilesets, 0, 0, layerWidth, layerHeight, cellWidth, cellHeight)
+	if err != nil {
+		return err
+	}
+
+	state.tiles = tiles
+	return nil
+}
+
+func processChunks(layer *tiled.Layer, state *layerState, tilesets []*tiled.Tileset, region *geom.Rect64, layerWidth, layerHeight, cellWidth, cellHeight int, budgeted bool) error {
+	if layer.Data == nil || len(layer.Data.Chunks) == 0 {
+		return nil
+	}
+
+	if state.partitions == nil {
+		state.partitions = make(layerPartitions)
+		state.partitionUse = make(map[geom.Rect64]int)
+		state.partitionGrids = make(map[geom.Rect64]int)
+	}
+	if !state.inFrame {
+		state.partitionTick++
+	}
+
+	async := tiled.CurrentConfig().AsyncChunkDecode
+	if async {
+		if err := state.collectDecodedChunks(layer); err != nil {
+			return err
+		}
+	}
+
+	minx, miny := region.Min()
+	maxx, maxy := region.Max()
+
+	for i, chunk := range layer.Data.Chunks {
+		chunkX := float64(chunk.X() * cellWidth)
+		chunkY := float64(chunk.Y() * cellHeight)
+		chunkW := float64(chunk.Width() * cellWidth)
+		chunkH := float64(chunk.Height() * cellHeight)
+
+		cminx, cminy := chunkX, chunkY
+		cmaxx, cmaxy := cminx+chunkW, cminy+chunkH
+
+		if cmaxx < minx || cminx > maxx || cmaxy < miny || cminy > maxy {
+			continue
+		}
+
+		chunkRect := geom.NewRect64(cminx, cminy, cmaxx-cminx, cmaxy-cminy)
+		if !region.Intersects(chunkRect) {
+			continue
+		}
+		state.partitionUse[chunkRect] = state.partitionTick
+		state.partitionGrids[chunkRect] = i
+		if _, exists := state.partitions[chunkRect]; exists {
+			statsCache(true)
+			continue
+		}
+		statsCache(false)
+		if async {
+			// Drawn once decoded; until then the chunk is left empty.
+			state.requestChunk(layer, chunkRect, chunk, tilesets, cellWidth, cellHeight)
+			continue
+		}
+
+		if budgeted && !decodeBudgetLeft() {
+			// Left empty until a frame with budget to decode it.
+			continue
+		}
+		start := time.Now()
+
+		parsedData, err := layer.ChunkGIDs(i)
+		if err != nil {
+			return err
+		}
+
+		tiles, err := decodeTiles(parsedData, tilesets, int(chunkX), int(chunkY), int(chunkW), int(chunkH), cellWidth, cellHeight)
+		if err != nil {
+			return err
+		}
+		spendDecodeBudget(start)
+
+		state.partitions[chunkRect] = tiles
+		renderStats.chunksDecoded.Add(1)
+	}
+
+	return nil
+}
+
+// releaseLayerCache drops what was decoded for a draw when the configuration asks not to keep it.
+func releaseLayerCache(layer *tiled.Layer, state *layerState) {
+	tiled.AdvanceCacheTick()
+	if state.inFrame {
+		return
+	}
+
+	tiled.EnforceMemoryBudget()
+	cfg := tiled.CurrentConfig()
+	if cfg.DisableTileCache {
+		state.tiles = nil
+		state.partitions = nil
+		return
+	}
+	if cfg.ChunkCacheLimit > 0 {
+		evictPartitions(layer, state, cfg.ChunkCacheLimit)
+	}
+}
+
+// evictPartitions drops the least recently drawn chunks of an infinite layer until at most limit remain,
+// along with their decoded cells. Chunks drawn this frame are kept even when they alone exceed the
+// limit; dropped chunks are decoded again when they come back into view or are queried.
+func evictPartitions(layer *tiled.Layer, state *layerState, limit int) {
+	excess := len(state.partitions) - limit
+	if excess <= 0 {
+		return
+	}
+
+	stale := make([]geom.Rect64, 0, len(state.partitions))
+	for rect := range state.partitions {
+		if state.partitionUse[rect] != state.partitionTick {
+			stale = append(stale, rect)
+		}
+	}
+	slices.SortFunc(stale, func(a, b geom.Rect64) int {
+		return state.partitionUse[a] - state.partitionUse[b]
+	})
+
+	for _, rect := range stale[:min(excess, len(stale))] {
+		delete(state.partitions, rect)
+		delete(state.partitionUse, rect)
+		// The chunk's cells, and the collision segments built from them, go too.
+		if i, exists := state.partitionGrids[rect]; exists {
+			layer.ReleaseChunk(i)
+			delete(state.partitionGrids, rect)
+		}
+	}
+
+	// Pages of chunks that are no longer decoded go with them.
+	for rect, page := range state.pages {
+		if _, exists := state.partitions[rect]; !exists {
+			page.release()
+			delete(state.pages, rect)
+		}
+	}
+}
+
+// collectTiles returns the tiles of the layer that overlap the region, in the map's render order.
+func collectTiles(state *layerState, region *geom.Rect64, cellWidth, cellHeight int, isInfinite bool, renderOrder tiled.RenderOrder) []tileRef {
+	if state.tiles == nil && state.partitions == nil {
+		return nil
+	}
+
+	var blocks []*tileBlock
+	if isInfinite {
+		for chunkRect, block := range state.partitions {
+			if region.Intersects(chunkRect) {
+				blocks = append(blocks, block)
+			}
+		}
+	} else {
+		blocks = append(blocks, state.tiles)
+	}
+
+	var result []tileRef
+	for _, block := range blocks {
+		result = appendBlockTiles(result, block, region)
+	}
+
+	// Finite layers are decoded in right-down order already. Chunks are gathered from a map,
+	// so infinite layers are always sorted.
+	if isInfinite || renderOrder != tiled.TMXRightDown {
+		sortTiles(result, renderOrder)
+	}
+
+	return result
+}
+
+// appendBlockTiles appends the tiles of the block that overlap the region, in cell order.
+func appendBlockTiles(tiles []tileRef, block *tileBlock, region *geom.Rect64) []tileRef {
+	renderStats.tilesConsidered.Add(int64(block.len()))
+
+	minx, miny := region.Min()
+	maxx, maxy := region.Max()
+
+	for i := range block.len() {
+		tminx, tminy, w, h := block.bounds(i)
+		tmaxx, tmaxy := tminx+w, tminy+h
+
+		if tmaxx < minx || tminx > maxx || tmaxy < miny || tminy > maxy {
+			continue
+		}
+
+		tiles = append(tiles, tileRef{block: block, index: i})
+	}
+	return tiles
+}
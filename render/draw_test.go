@@ -0,0 +1,40 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+func TestEvictPartitionsReleasesChunkCells(t *testing.T) {
+	tmx := loadFixture(t, "infinite_csv_chunk32.tmx")
+	layer := tmx.LayerByName("ground")
+
+	// Collision and other queries keep the cells they decode.
+	if _, err := layer.GIDChunks(); err != nil {
+		t.Fatal(err)
+	}
+	if layer.DecodedBytes() == 0 {
+		t.Fatal("no cells decoded")
+	}
+
+	region := geom.NewRect64(0, 0, 512, 512)
+	if err := EachTileInRegion(tmx, "ground", region, func(TileView) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	state := stateOf(layer)
+	if len(state.partitions) == 0 {
+		t.Fatal("no chunks decoded")
+	}
+
+	// Chunks drawn in the current frame are kept, so the eviction happens a frame later.
+	state.partitionTick++
+	evictPartitions(layer, state, 0)
+
+	if len(state.partitions) != 0 {
+		t.Errorf("%d chunks left after evicting every chunk", len(state.partitions))
+	}
+	if n := layer.DecodedBytes(); n != 0 {
+		t.Errorf("evicted chunks left %d bytes of decoded cells", n)
+	}
+}
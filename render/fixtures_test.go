@@ -0,0 +1,24 @@
+package render
+
+import (
+	"os"
+	"testing"
+
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// testdata holds the fixture maps of package tiled under fixtures/ and the tilesets, images and
+// template they share.
+var testdata = os.DirFS("../tiled/testdata")
+
+// loadFixture loads a fixture map together with its tileset and template, leaving its images unloaded.
+func loadFixture(t *testing.T, name string) *tiled.TMX {
+	t.Helper()
+	t.Cleanup(func() { tiled.ReleaseFS(testdata) })
+
+	tmx, err := tiled.LoadTMX(testdata, "fixtures/"+name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmx
+}
@@ -0,0 +1,139 @@
+package render
+
+import (
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Memory Footprint
+// ======================================================
+
+// MemoryFootprint estimates the memory a map holds on to, in bytes, to help budget memory on
+// constrained targets. Sizes count the data itself; bookkeeping such as map overhead is left out.
+type MemoryFootprint struct {
+	// CellBytes is the decoded raw cell data of every tile layer.
+	CellBytes int64
+
+	// TileBytes is the decoded tiles of every tile layer.
+	TileBytes int64
+
+	// Tiles is how many decoded tiles there are.
+	Tiles int
+
+	// Partitions is how many chunks of infinite layers are decoded.
+	Partitions int
+
+	// BufferBytes is the images of static layer buffers and pages, with their downscaled copies.
+	BufferBytes int64
+
+	// Buffers is how many static layer buffers and pages there are.
+	Buffers int
+
+	// TilesetImageBytes is the loaded images of the map's tilesets and their keyed copies, each counted once.
+	TilesetImageBytes int64
+}
+
+// Total returns the bytes of every part of the footprint.
+func (f MemoryFootprint) Total() int64 {
+	return f.CellBytes + f.TileBytes + f.BufferBytes + f.TilesetImageBytes
+}
+
+// Footprint summarizes what the map currently holds: what its layers have decoded and rendered so far,
+// and the tileset images it references that are loaded.
+func Footprint(tmx *tiled.TMX) MemoryFootprint {
+	var f MemoryFootprint
+
+	for _, layer := range tmx.Layers {
+		f.CellBytes += layer.DecodedBytes()
+
+		state := layerStates.get(layer)
+		if state == nil {
+			continue
+		}
+
+		if state.tiles != nil {
+			f.TileBytes += state.tiles.bytes()
+			f.Tiles += state.tiles.len()
+		}
+		for _, block := range state.partitions {
+			f.TileBytes += block.bytes()
+			f.Tiles += block.len()
+		}
+		f.Partitions += len(state.partitions)
+
+		if state.static != nil {
+			f.BufferBytes += state.static.bytes()
+			f.Buffers++
+		}
+		for _, page := range state.pages {
+			f.BufferBytes += page.bytes()
+			f.Buffers++
+		}
+	}
+
+	// Only images that are already loaded are counted: the footprint never loads or keys an image.
+	seen := make(map[*ebiten.Image]bool)
+	count := func(img *tiled.Image) {
+		for _, loaded := range loadedImages(img) {
+			if !seen[loaded] {
+				seen[loaded] = true
+				f.TilesetImageBytes += imageBytes(loaded)
+			}
+		}
+	}
+	for _, tileset := range tmx.Tilesets {
+		tsx, err := tileset.TSX()
+		if err != nil {
+			continue
+		}
+		if tsx.Image != nil {
+			count(tsx.Image)
+		}
+		for _, tile := range tsx.Tiles {
+			if tile.Image != nil {
+				count(tile.Image)
+			}
+		}
+	}
+
+	return f
+}
+
+// bytes returns the size of the block's tile arrays.
+func (b *tileBlock) bytes() int64 {
+	return int64(cap(b.cells))*4 + int64(cap(b.ids))*4 + int64(cap(b.set))*2 +
+		int64(cap(b.flags)) + int64(cap(b.widths)+cap(b.heights))*4
+}
+
+// bytes returns the size of the buffer's image and its downscaled copies.
+func (buf *staticBuffer) bytes() int64 {
+	size := imageBytes(buf.img)
+	for _, mip := range buf.mips {
+		size += imageBytes(mip)
+	}
+	return size
+}
+
+// loadedImages returns the images the image element holds on to: its image file if it is loaded and
+// on the GPU, and the copy with its transparent color keyed out if one has been made.
+func loadedImages(img *tiled.Image) []*ebiten.Image {
+	src, err := img.Resolve()
+	if err != nil || src == nil {
+		return nil
+	}
+	loaded, ok := uploadedImage(src)
+	if !ok {
+		return nil
+	}
+	if keyed, ok := keyedImage(loaded, img); ok {
+		return []*ebiten.Image{loaded, keyed}
+	}
+	return []*ebiten.Image{loaded}
+}
+
+// imageBytes returns the size of an image's pixels on the GPU.
+func imageBytes(img *ebiten.Image) int64 {
+	bounds := img.Bounds()
+	return int64(bounds.Dx()) * int64(bounds.Dy()) * 4
+}
@@ -0,0 +1,28 @@
+package render
+
+import "testing"
+
+func TestFootprintDoesNotLoadImages(t *testing.T) {
+	tmx := loadFixture(t, "ortho_csv.tmx")
+
+	keyedImagesMu.Lock()
+	keyed := len(keyedImages)
+	keyedImagesMu.Unlock()
+
+	if f := Footprint(tmx); f.TilesetImageBytes != 0 {
+		t.Errorf("footprint counts %d bytes of tileset images that were never loaded", f.TilesetImageBytes)
+	}
+	tsx, err := tmx.Tilesets[0].TSX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsx.Image.Resolve(); err == nil {
+		t.Error("footprint loaded the tileset image")
+	}
+
+	keyedImagesMu.Lock()
+	defer keyedImagesMu.Unlock()
+	if len(keyedImages) != keyed {
+		t.Error("footprint keyed a tileset image")
+	}
+}
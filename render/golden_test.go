@@ -0,0 +1,147 @@
+//go:build golden
+
+package render
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Golden tests render the fixtures of package tiled and compare them with the PNGs under
+// testdata/golden. Rendering reads back from the GPU once the game is running, so they are built with
+// the golden tag and need a display:
+//
+//	go test -tags golden -run Golden ./render
+//	go test -tags golden -run Golden ./render -update-golden  # rewrite the PNGs
+
+var updateGolden = flag.Bool("update-golden", false, "write rendered fixtures to testdata/golden")
+
+// goldenGame runs the package's tests from its first Update, where images can be read back.
+type goldenGame struct {
+	m    *testing.M
+	code int
+}
+
+func (g *goldenGame) Update() error {
+	g.code = g.m.Run()
+	return ebiten.Termination
+}
+
+func (g *goldenGame) Draw(*ebiten.Image) {}
+
+func (g *goldenGame) Layout(int, int) (int, int) {
+	return 1, 1
+}
+
+func TestMain(m *testing.M) {
+	game := &goldenGame{m: m}
+	if err := ebiten.RunGame(game); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(game.code)
+}
+
+func TestGoldenImages(t *testing.T) {
+	for _, c := range []struct {
+		fixture string
+		golden  string
+	}{
+		{"ortho_csv.tmx", "ortho.png"},
+		{"ortho_base64.tmx", "ortho.png"},
+		{"ortho_gzip.tmx", "ortho.png"},
+		{"ortho_zlib.tmx", "ortho.png"},
+		{"ortho_zstd.tmx", "ortho.png"},
+		{"wang_corners.tmx", "wang_corners.png"},
+	} {
+		t.Run(c.fixture, func(t *testing.T) {
+			t.Cleanup(func() { tiled.ReleaseFS(testdata) })
+			tmx, err := tiled.LoadTMXWithImages(testdata, "fixtures/"+c.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ctx finch.Context
+			got, err := RenderImage(ctx, tmx, ExportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", "golden", c.golden)
+			if *updateGolden {
+				writeGolden(t, path, got)
+				return
+			}
+			compareGolden(t, path, got)
+		})
+	}
+}
+
+func writeGolden(t *testing.T, path string, img image.Image) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// compareGolden fails the test if a pixel of got differs from the golden image by more than one step
+// in any channel, which leaves room for rounding on different GPUs.
+func compareGolden(t *testing.T, path string, got *image.RGBA) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("invalid golden image %s: %v", path, err)
+	}
+
+	if got.Bounds().Size() != want.Bounds().Size() {
+		t.Fatalf("rendered %v, golden image %s is %v", got.Bounds().Size(), path, want.Bounds().Size())
+	}
+
+	mismatched := 0
+	var first image.Point
+	for y := 0; y < got.Bounds().Dy(); y++ {
+		for x := 0; x < got.Bounds().Dx(); x++ {
+			g := got.RGBAAt(x, y)
+			w := color.RGBAModel.Convert(want.At(want.Bounds().Min.X+x, want.Bounds().Min.Y+y)).(color.RGBA)
+			if channelDiff(g.R, w.R) > 1 || channelDiff(g.G, w.G) > 1 || channelDiff(g.B, w.B) > 1 || channelDiff(g.A, w.A) > 1 {
+				if mismatched == 0 {
+					first = image.Pt(x, y)
+				}
+				mismatched++
+			}
+		}
+	}
+	if mismatched > 0 {
+		t.Errorf("%d pixels differ from %s, first at %v: got %v, want %v", mismatched, path, first,
+			got.RGBAAt(first.X, first.Y), want.At(want.Bounds().Min.X+first.X, want.Bounds().Min.Y+first.Y))
+	}
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"image/color"
+	"log/slog"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Heatmap Rendering
+// ======================================================
+
+var (
+	HeatmapColdColor = color.RGBA{R: 0, G: 64, B: 255, A: 255}
+	HeatmapHotColor  = color.RGBA{R: 255, G: 32, B: 0, A: 255}
+)
+
+// DrawHeatmap renders one colored cell per map cell, shaded from cold to hot by how often
+// the most used tile in that cell appears in usage. If usage is nil, the map's own usage is used.
+func DrawHeatmap(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, usage tiled.TileUsage) {
+	if usage == nil {
+		var err error
+		if usage, err = tiled.CountTileUsage(tmx); err != nil {
+			logDraw(ctx, slog.LevelError, "tiled: error counting tile usage", "", slog.Any("error", err))
+			return
+		}
+	}
+
+	highest := usage.Max()
+	if highest == 0 {
+		return
+	}
+
+	heat := make(map[tiled.Cell]int)
+
+	for _, layer := range tmx.Layers {
+		chunks, err := layer.GIDChunks()
+		if err != nil {
+			logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+			continue
+		}
+		for _, chunk := range chunks {
+			for i, data := range chunk.GIDs {
+				key, ok := tiled.TileKeyOf(data, tmx.Tilesets)
+				if !ok {
+					continue
+				}
+				cell := tiled.Cell{X: chunk.X + i%chunk.Width, Y: chunk.Y + i/chunk.Width}
+				if count := usage[key]; count > heat[cell] {
+					heat[cell] = count
+				}
+			}
+		}
+	}
+
+	cellWidth := float64(tmx.TileWidth())
+	cellHeight := float64(tmx.TileHeight())
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	for c, count := range heat {
+		t := float32(count) / float32(highest)
+
+		op.GeoM.Reset()
+		op.GeoM.Scale(cellWidth, cellHeight)
+		op.GeoM.Translate(float64(c.X)*cellWidth, float64(c.Y)*cellHeight)
+		op.ColorScale.Reset()
+		op.ColorScale.Scale(
+			lerpChannel(HeatmapColdColor.R, HeatmapHotColor.R, t),
+			lerpChannel(HeatmapColdColor.G, HeatmapHotColor.G, t),
+			lerpChannel(HeatmapColdColor.B, HeatmapHotColor.B, t),
+			1,
+		)
+		img.DrawImage(whitePixel(), op)
+	}
+}
+
+func lerpChannel(from, to uint8, t float32) float32 {
+	return (float32(from) + (float32(to)-float32(from))*t) / 255
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Map Instances
+// ======================================================
+
+// instanceState is what the package keeps for a map instance: its tile draw hooks and decals.
+type instanceState struct {
+	tileFuncs map[string]TileDrawFunc
+	decals    map[string][]*Decal
+}
+
+var instanceStates sideTable[tiled.MapInstance, instanceState]
+
+// DrawInstance renders the instance like Draw renders a map.
+func DrawInstance(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance) {
+	region := geom.NewRect64(0, 0, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
+	drawLayers(ctx, DrawModeNormal, img, inst.TMX, inst, &region, &ebiten.GeoM{})
+}
+
+// DrawInstanceLayer renders a layer of the instance like DrawLayer renders a map layer.
+func DrawInstanceLayer(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, layerName string) {
+	region := geom.NewRect64(0, 0, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
+	drawNamedLayer(ctx, DrawModeNormal, img, inst.TMX, inst, layerName, &region, &ebiten.GeoM{})
+}
+
+// DrawInstanceRegion renders a region of the instance like DrawRegion renders a map region.
+func DrawInstanceRegion(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, region geom.Rect64) {
+	drawLayers(ctx, DrawModeRegional, img, inst.TMX, inst, &region, &ebiten.GeoM{})
+}
+
+// DrawInstanceLayerRegion renders a region of a layer of the instance like DrawLayerRegion renders a map layer region.
+func DrawInstanceLayerRegion(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, layerName string, region geom.Rect64) {
+	drawNamedLayer(ctx, DrawModeRegional, img, inst.TMX, inst, layerName, &region, &ebiten.GeoM{})
+}
+
+// DrawInstanceScene renders the instance as seen through a camera like DrawScene renders a map.
+func DrawInstanceScene(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, viewport geom.Rect64, viewMatrix ebiten.GeoM) {
+	drawLayers(ctx, DrawModeScene, img, inst.TMX, inst, &viewport, &viewMatrix)
+}
+
+// DrawInstanceSceneLayer renders a layer of the instance as seen through a camera like DrawSceneLayer renders a map layer.
+func DrawInstanceSceneLayer(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, layerName string, viewport geom.Rect64, viewMatrix ebiten.GeoM) {
+	drawNamedLayer(ctx, DrawModeScene, img, inst.TMX, inst, layerName, &viewport, &viewMatrix)
+}
+
+// ======================================================
+// Tile Draw Hooks
+// ======================================================
+
+// TileDrawFunc is called for every tile of a layer before it is drawn.
+// Returning skip hides the tile. Returned options are applied on top of the layer's own:
+// the GeoM is applied in tile-local space before the tile is positioned, the ColorScale
+// is multiplied into the layer's opacity and tint, and the Blend replaces the default blend.
+type TileDrawFunc func(tile *tiled.Tile, cell tiled.Cell) (skip bool, opts *ebiten.DrawImageOptions)
+
+// SetTileDrawFunc registers a hook invoked for every tile drawn from the named layer of the instance.
+// Passing a nil function removes the hook. A hook referring to the instance keeps the instance alive
+// until the hook is removed.
+func SetTileDrawFunc(inst *tiled.MapInstance, layerName string, fn TileDrawFunc) {
+	if fn == nil {
+		if state := instanceStates.get(inst); state != nil {
+			delete(state.tileFuncs, layerName)
+		}
+		return
+	}
+	state := instanceStates.getOrCreate(inst)
+	if state.tileFuncs == nil {
+		state.tileFuncs = make(map[string]TileDrawFunc)
+	}
+	state.tileFuncs[layerName] = fn
+}
+
+// tileFuncOf returns the hook registered for the named layer of the instance, if any.
+func tileFuncOf(inst *tiled.MapInstance, layerName string) TileDrawFunc {
+	if state := instanceStates.get(inst); state != nil {
+		return state.tileFuncs[layerName]
+	}
+	return nil
+}
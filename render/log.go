@@ -0,0 +1,107 @@
+package render
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/adm87/finch-core/finch"
+)
+
+// ======================================================
+// Draw Logging
+// ======================================================
+
+// DrawLogOptions controls how problems found while drawing are logged.
+// Draw paths run every frame, so without rate limiting a single broken tile would be logged
+// dozens of times per second.
+type DrawLogOptions struct {
+	// Interval is the minimum time between two logs with the same message and key.
+	// Occurrences in between are counted and reported with the next log. Zero logs every occurrence.
+	Interval time.Duration
+
+	// MinLevel drops logs below this severity.
+	MinLevel slog.Level
+
+	// Levels overrides the severity of specific messages, e.g. ErrWhileDrawingLayer.
+	Levels map[string]slog.Level
+}
+
+// DefaultDrawLogOptions logs each distinct problem at most once every five seconds.
+var DefaultDrawLogOptions = DrawLogOptions{
+	Interval: 5 * time.Second,
+	MinLevel: slog.LevelDebug,
+}
+
+var drawLog = struct {
+	sync.Mutex
+	opts    DrawLogOptions
+	entries map[drawLogKey]*drawLogEntry
+}{
+	opts: DefaultDrawLogOptions,
+}
+
+type drawLogKey struct {
+	msg string
+	key string
+}
+
+type drawLogEntry struct {
+	last       time.Time
+	suppressed int
+}
+
+// SetDrawLogOptions replaces the options used to log problems found while drawing,
+// and forgets every rate limit in progress.
+func SetDrawLogOptions(opts DrawLogOptions) {
+	drawLog.Lock()
+	defer drawLog.Unlock()
+	drawLog.opts = opts
+	drawLog.entries = nil
+}
+
+// logDraw logs a problem found while drawing, rate limited per message and key.
+// The key identifies what the problem is about, such as a layer name or a GID.
+func logDraw(ctx finch.Context, level slog.Level, msg, key string, attrs ...slog.Attr) {
+	drawLog.Lock()
+
+	if override, exists := drawLog.opts.Levels[msg]; exists {
+		level = override
+	}
+	if level < drawLog.opts.MinLevel {
+		drawLog.Unlock()
+		return
+	}
+
+	suppressed := 0
+	if interval := drawLog.opts.Interval; interval > 0 {
+		now := time.Now()
+		k := drawLogKey{msg: msg, key: key}
+
+		entry, exists := drawLog.entries[k]
+		if exists && now.Sub(entry.last) < interval {
+			entry.suppressed++
+			drawLog.Unlock()
+			return
+		}
+		if !exists {
+			if drawLog.entries == nil {
+				drawLog.entries = make(map[drawLogKey]*drawLogEntry)
+			}
+			entry = &drawLogEntry{}
+			drawLog.entries[k] = entry
+		}
+
+		suppressed = entry.suppressed
+		entry.last = now
+		entry.suppressed = 0
+	}
+
+	drawLog.Unlock()
+
+	if suppressed > 0 {
+		attrs = append(attrs, slog.Int("suppressed", suppressed))
+	}
+	ctx.Logger().LogAttrs(context.Background(), level, msg, attrs...)
+}
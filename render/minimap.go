@@ -0,0 +1,179 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+	"sync"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Minimaps
+// ======================================================
+
+// tileAverages caches the average color of each tile, premultiplied, so tileset images are read back
+// once rather than every time a minimap is drawn. Entries remember the image they were averaged from
+// and are averaged again if the tileset's image is replaced.
+var (
+	tileAveragesMu sync.Mutex
+	tileAverages   = make(map[tiled.TileKey]tileAverage)
+)
+
+type tileAverage struct {
+	parent *ebiten.Image
+	clr    color.RGBA
+}
+
+// MinimapBounds returns the cells DrawMinimap draws: the map's size for finite maps, or the area
+// covered by the chunks of every tile layer for infinite ones.
+func MinimapBounds(tmx *tiled.TMX) image.Rectangle {
+	if !tmx.IsInfinite() {
+		return image.Rect(0, 0, tmx.Width(), tmx.Height())
+	}
+
+	var bounds image.Rectangle
+	for _, layer := range tmx.Layers {
+		if layer.Data == nil {
+			continue
+		}
+		for _, chunk := range layer.Data.Chunks {
+			bounds = bounds.Union(image.Rect(chunk.X(), chunk.Y(), chunk.X()+chunk.Width(), chunk.Y()+chunk.Height()))
+		}
+	}
+	return bounds
+}
+
+// DrawMinimap writes one pixel per cell of the map onto the image, starting with the top-left cell of
+// MinimapBounds, colored with the average color of the cell's tiles. Visible tile layers are blended
+// in order with their tint and opacity; nothing is rendered, so it stays fast for very large maps.
+// The image's pixels are replaced; cells past its size are left out.
+func DrawMinimap(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX) {
+	bounds := MinimapBounds(tmx)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	pixels := make([]float32, 4*w*h)
+
+	colors := make(map[uint32]color.RGBA)
+	readBack := make(map[*ebiten.Image][]byte)
+
+	for _, layer := range tmx.Layers {
+		if !layer.IsVisible() {
+			continue
+		}
+
+		chunks, err := layer.GIDChunks()
+		if err != nil {
+			logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+			continue
+		}
+
+		tint := color.NRGBAModel.Convert(layer.TintColor()).(color.NRGBA)
+		scale := [4]float32{
+			float32(tint.R) / 0xff * float32(layer.Opacity()),
+			float32(tint.G) / 0xff * float32(layer.Opacity()),
+			float32(tint.B) / 0xff * float32(layer.Opacity()),
+			float32(tint.A) / 0xff * float32(layer.Opacity()),
+		}
+
+		for _, chunk := range chunks {
+			for i, data := range chunk.GIDs {
+				gid := data & tiled.TILE_ID_MASK
+				if gid == 0 {
+					continue
+				}
+
+				x, y := chunk.X+i%chunk.Width-bounds.Min.X, chunk.Y+i/chunk.Width-bounds.Min.Y
+				if x < 0 || y < 0 || x >= w || y >= h {
+					continue
+				}
+
+				clr, exists := colors[gid]
+				if !exists {
+					if clr, err = averageTileColor(gid, tmx.Tilesets, readBack); err != nil {
+						logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+					}
+					colors[gid] = clr
+				}
+
+				// Colors are premultiplied, so each layer is laid over the ones below it.
+				p := pixels[4*(y*w+x):]
+				sa := float32(clr.A) / 0xff * scale[3]
+				p[0] = float32(clr.R)/0xff*scale[0] + p[0]*(1-sa)
+				p[1] = float32(clr.G)/0xff*scale[1] + p[1]*(1-sa)
+				p[2] = float32(clr.B)/0xff*scale[2] + p[2]*(1-sa)
+				p[3] = sa + p[3]*(1-sa)
+			}
+		}
+	}
+
+	out := make([]byte, len(pixels))
+	for i, v := range pixels {
+		out[i] = uint8(min(max(v, 0), 1)*0xff + 0.5)
+	}
+	img.WritePixels(out)
+}
+
+// averageTileColor returns the average color, premultiplied, of the tile the GID references.
+// Tileset images read back to average are kept in readBack for the other tiles they hold.
+func averageTileColor(gid uint32, tilesets []*tiled.Tileset, readBack map[*ebiten.Image][]byte) (color.RGBA, error) {
+	tileset := tiled.TilesetOf(gid, tilesets)
+	if tileset == nil {
+		return color.RGBA{}, nil
+	}
+
+	tsx, err := tileset.TSX()
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	id := gid - tileset.FirstGID()
+	w, h := tsx.TileSize(id)
+	tile := tileset.Tile(id)
+	tile.Width, tile.Height = float64(w), float64(h)
+
+	src, err := tileSource(&tile)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	key := tiled.TileKey{Source: tileset.Source(), ID: id}
+
+	tileAveragesMu.Lock()
+	defer tileAveragesMu.Unlock()
+
+	if cached, exists := tileAverages[key]; exists && cached.parent == src.parent {
+		return cached.clr, nil
+	}
+
+	pixels, exists := readBack[src.parent]
+	if !exists {
+		bounds := src.parent.Bounds()
+		pixels = make([]byte, 4*bounds.Dx()*bounds.Dy())
+		src.parent.ReadPixels(pixels)
+		readBack[src.parent] = pixels
+	}
+
+	stride := src.parent.Bounds().Dx()
+	rect := src.rect.Sub(src.parent.Bounds().Min).Intersect(image.Rect(0, 0, stride, src.parent.Bounds().Dy()))
+
+	var sum [4]int
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			p := pixels[4*(y*stride+x):]
+			sum[0] += int(p[0])
+			sum[1] += int(p[1])
+			sum[2] += int(p[2])
+			sum[3] += int(p[3])
+		}
+	}
+
+	var clr color.RGBA
+	if n := rect.Dx() * rect.Dy(); n > 0 {
+		clr = color.RGBA{R: uint8(sum[0] / n), G: uint8(sum[1] / n), B: uint8(sum[2] / n), A: uint8(sum[3] / n)}
+	}
+	tileAverages[key] = tileAverage{parent: src.parent, clr: clr}
+	return clr, nil
+}
@@ -0,0 +1,169 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"slices"
+	"sync"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Releasing Resources
+// ======================================================
+
+// refCounts counts the maps acquired with AcquireMap that reference each file, including the maps
+// themselves, and remembers which files each map references.
+type refCounts struct {
+	sync.Mutex
+	counts map[finch.AssetFile]int
+	deps   map[finch.AssetFile][]finch.AssetFile
+}
+
+func newRefCounts() *refCounts {
+	return &refCounts{
+		counts: make(map[finch.AssetFile]int),
+		deps:   make(map[finch.AssetFile][]finch.AssetFile),
+	}
+}
+
+// acquire takes a reference to the map. The first reference also takes one to each of its
+// dependencies.
+func (rc *refCounts) acquire(file finch.AssetFile, deps []finch.AssetFile) {
+	rc.counts[file]++
+	if rc.counts[file] == 1 {
+		rc.deps[file] = deps
+		for _, dep := range deps {
+			rc.counts[dep]++
+		}
+	}
+}
+
+// release drops a reference to the map. Once the map has no references left, it reports whether the
+// map itself is unreferenced and which dependencies no other map references, in the order they must
+// be unloaded: images before the tilesets that name them, tilesets before the templates that name them.
+func (rc *refCounts) release(file finch.AssetFile) (last bool, unload []finch.AssetFile, err error) {
+	count, exists := rc.counts[file]
+	if !exists {
+		return false, nil, fmt.Errorf("map is not acquired: %s", file.Path())
+	}
+	if count > 1 {
+		rc.counts[file]--
+		return false, nil, nil
+	}
+	delete(rc.counts, file)
+
+	deps := rc.deps[file]
+	delete(rc.deps, file)
+	for _, dep := range slices.Backward(deps) {
+		if rc.counts[dep]--; rc.counts[dep] <= 0 {
+			delete(rc.counts, dep)
+			unload = append(unload, dep)
+		}
+	}
+	return true, unload, nil
+}
+
+var assetRefs = newRefCounts()
+
+// AcquireMap loads a map with everything it references, like LoadMapWithDependencies, and takes a
+// reference to it. Each call must be paired with a ReleaseMap.
+func AcquireMap(file finch.AssetFile) (*tiled.TMX, error) {
+	tmx, deps, err := loadMapDependencies(file)
+	if err != nil {
+		return nil, err
+	}
+
+	assetRefs.Lock()
+	defer assetRefs.Unlock()
+
+	assetRefs.acquire(file, deps)
+	return tmx, nil
+}
+
+// ReleaseMap drops a reference taken with AcquireMap. Once the map has no references left it is
+// released and unloaded, along with every tileset, template and image no other acquired map
+// references, so switching levels frees their GPU images and decoded tiles. Files shared with maps
+// that were loaded without AcquireMap are unloaded all the same, so acquire every map sharing them.
+func ReleaseMap(file finch.AssetFile) error {
+	assetRefs.Lock()
+	defer assetRefs.Unlock()
+
+	last, unload, err := assetRefs.release(file)
+	if err != nil || !last {
+		return err
+	}
+
+	var errs []error
+	if tmx, err := GetTMX(file); err == nil {
+		tmx.Release()
+	}
+	errs = append(errs, unloadAsset(file))
+	for _, dep := range unload {
+		errs = append(errs, unloadAsset(dep))
+	}
+	return errors.Join(errs...)
+}
+
+// unloadAsset unloads a file from finch, first dropping what this package cached from it.
+func unloadAsset(file finch.AssetFile) error {
+	asset, err := file.Get()
+	if err != nil {
+		return nil // Already unloaded.
+	}
+
+	switch asset := asset.(type) {
+	case *tiled.TSX:
+		forgetTileset(file.Path())
+	case image.Image:
+		forgetImage(asset)
+	}
+	return finch.UnloadAssets(file)
+}
+
+// forgetTileset drops the tile images and average colors cached for the tileset's tiles.
+func forgetTileset(source string) {
+	tileImagesMu.Lock()
+	for key := range tileImages {
+		if key.Source == source {
+			delete(tileImages, key)
+		}
+	}
+	tileImagesMu.Unlock()
+
+	tileAveragesMu.Lock()
+	for key := range tileAverages {
+		if key.Source == source {
+			delete(tileAverages, key)
+		}
+	}
+	tileAveragesMu.Unlock()
+}
+
+// forgetImage frees the copies of the image made to draw it: its GPU copy and the copies made to key
+// out its transparent color.
+func forgetImage(src image.Image) {
+	img, ok := uploadedImage(src)
+	if !ok {
+		return
+	}
+
+	keyedImagesMu.Lock()
+	for key, keyed := range keyedImages {
+		if key.src == img {
+			keyed.Deallocate()
+			delete(keyedImages, key)
+		}
+	}
+	keyedImagesMu.Unlock()
+
+	gpuImagesMu.Lock()
+	defer gpuImagesMu.Unlock()
+	if uploaded, exists := gpuImages[src]; exists {
+		uploaded.Deallocate()
+		delete(gpuImages, src)
+	}
+}
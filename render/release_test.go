@@ -0,0 +1,62 @@
+package render
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/adm87/finch-core/finch"
+)
+
+func TestRefCountsReacquire(t *testing.T) {
+	rc := newRefCounts()
+	level := finch.AssetFile("maps/level.tmx")
+	deps := []finch.AssetFile{"maps/ground.tsx", "maps/ground.png"}
+
+	for round := range 2 {
+		rc.acquire(level, deps)
+
+		last, unload, err := rc.release(level)
+		if err != nil {
+			t.Fatalf("round %d: release: %v", round, err)
+		}
+		if !last {
+			t.Fatalf("round %d: release of the only reference was not the last", round)
+		}
+		if want := []finch.AssetFile{"maps/ground.png", "maps/ground.tsx"}; !slices.Equal(unload, want) {
+			t.Fatalf("round %d: unloaded %v, want %v", round, unload, want)
+		}
+		if len(rc.counts) != 0 || len(rc.deps) != 0 {
+			t.Fatalf("round %d: references left after the last release: %v", round, rc.counts)
+		}
+	}
+}
+
+func TestRefCountsSharedDependencies(t *testing.T) {
+	rc := newRefCounts()
+	a, b := finch.AssetFile("a.tmx"), finch.AssetFile("b.tmx")
+	shared, own := finch.AssetFile("shared.tsx"), finch.AssetFile("own.tsx")
+
+	rc.acquire(a, []finch.AssetFile{shared, own})
+	rc.acquire(a, []finch.AssetFile{shared, own})
+	rc.acquire(b, []finch.AssetFile{shared})
+
+	if last, _, _ := rc.release(a); last {
+		t.Fatal("released a map that still has a reference")
+	}
+	last, unload, err := rc.release(a)
+	if err != nil || !last {
+		t.Fatalf("release a: last=%v err=%v", last, err)
+	}
+	if !slices.Equal(unload, []finch.AssetFile{own}) {
+		t.Fatalf("release a unloaded %v, want only %s", unload, own)
+	}
+
+	if _, _, err := rc.release(a); err == nil {
+		t.Fatal("releasing a map that is no longer acquired did not fail")
+	}
+
+	_, unload, _ = rc.release(b)
+	if !slices.Equal(unload, []finch.AssetFile{shared}) {
+		t.Fatalf("release b unloaded %v, want %s", unload, shared)
+	}
+}
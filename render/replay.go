@@ -0,0 +1,42 @@
+package render
+
+import (
+	"time"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Replays
+// ======================================================
+
+// RecordFrame records a frame drawn with the provided camera, dt after the previous frame.
+func RecordFrame(rec *tiled.ReplayRecorder, dt time.Duration, viewport geom.Rect64, viewMatrix ebiten.GeoM) error {
+	var view [6]float64
+	for i := range view {
+		view[i] = viewMatrix.Element(i/3, i%3)
+	}
+	return rec.Frame(dt, viewport, view)
+}
+
+// ReplayViewMatrix returns the view matrix a frame was recorded with.
+func ReplayViewMatrix(frame tiled.ReplayFrame) ebiten.GeoM {
+	var view ebiten.GeoM
+	for i := range frame.View {
+		view.SetElement(i/3, i%3, frame.View[i])
+	}
+	return view
+}
+
+// DrawReplayFrame plays the player's next frame and renders the instance as the recorded camera saw it.
+func DrawReplayFrame(ctx finch.Context, img *ebiten.Image, p *tiled.ReplayPlayer) error {
+	frame, err := p.Step()
+	if err != nil {
+		return err
+	}
+	DrawInstanceScene(ctx, img, p.Instance(), frame.Viewport, ReplayViewMatrix(frame))
+	return nil
+}
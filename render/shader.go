@@ -0,0 +1,58 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Layer Shaders
+// ======================================================
+
+// LayerShader draws a tile layer through a Kage shader instead of plainly, for effects such as heat
+// haze, water distortion or palette cycling. The layer's tiles, or its static buffer, are passed to the
+// shader as image 0, along with the layer's color scale as the vertex color.
+type LayerShader struct {
+	Shader *ebiten.Shader
+
+	// Uniforms are passed to the shader on every draw; change them between frames to animate the effect.
+	Uniforms map[string]any
+}
+
+// SetShader draws the layer through the shader. Passing nil draws it plainly again.
+func SetShader(layer *tiled.Layer, shader *LayerShader) {
+	stateOf(layer).shader = shader
+}
+
+// Shader returns the shader the layer is drawn through, or nil.
+func Shader(layer *tiled.Layer) *LayerShader {
+	if state := layerStates.get(layer); state != nil {
+		return state.shader
+	}
+	return nil
+}
+
+// SetLayerShader draws the named tile layer of the map through the shader. Passing nil draws it plainly again.
+func SetLayerShader(tmx *tiled.TMX, layerName string, shader *LayerShader) error {
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		return fmt.Errorf("layer not found: %s", layerName)
+	}
+	SetShader(layer, shader)
+	return nil
+}
+
+// SetLayerShaderByProperty draws every tile layer of the map that has the named property through the shader,
+// so maps can mark the layers an effect applies to in the editor. It returns how many layers were set.
+func SetLayerShaderByProperty(tmx *tiled.TMX, property string, shader *LayerShader) int {
+	count := 0
+	for _, layer := range tmx.Layers {
+		if layer.HasProperty(property) {
+			SetShader(layer, shader)
+			count++
+		}
+	}
+	return count
+}
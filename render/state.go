@@ -0,0 +1,172 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"maps"
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Render State
+// ======================================================
+
+// sideTable holds what the package keeps for values of package tiled, such as the decoded tiles of a
+// layer, without storing it on them. Entries are keyed weakly and dropped once their value is garbage
+// collected, so state must not refer back to the value it belongs to.
+type sideTable[K, V any] struct {
+	mu      sync.Mutex
+	entries map[weak.Pointer[K]]*V
+}
+
+// get returns the state kept for the key, or nil if there is none.
+func (t *sideTable[K, V]) get(key *K) *V {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries[weak.Make(key)]
+}
+
+// getOrCreate returns the state kept for the key, creating it on first use.
+func (t *sideTable[K, V]) getOrCreate(key *K) *V {
+	ptr := weak.Make(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if state, exists := t.entries[ptr]; exists {
+		return state
+	}
+	if t.entries == nil {
+		t.entries = make(map[weak.Pointer[K]]*V)
+	}
+	state := new(V)
+	t.entries[ptr] = state
+	runtime.AddCleanup(key, t.forget, ptr)
+	return state
+}
+
+// forget drops the state kept for a key.
+func (t *sideTable[K, V]) forget(ptr weak.Pointer[K]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, ptr)
+}
+
+// ======================================================
+// Layer State
+// ======================================================
+
+// layerPartitions holds the decoded tiles of an infinite layer by the area, in pixels, of their chunk.
+type layerPartitions map[geom.Rect64]*tileBlock
+
+// layerState is what the package keeps for a tile layer: the tiles decoded from its cells, the buffers
+// they are drawn into, and the draw settings made with SetShader, SetTileColor and the like.
+type layerState struct {
+	tiles      *tileBlock
+	partitions layerPartitions
+
+	// Frame in which each partition was last drawn, for evicting the least recently used.
+	partitionUse   map[geom.Rect64]int
+	partitionGrids map[geom.Rect64]int // Chunk each partition was decoded from, as ordered by GIDChunks.
+	partitionTick  int
+	inFrame        bool // Drawn to several views; the tick advances and caches are trimmed once for all of them.
+	decoder        *chunkDecoder
+	static         *staticBuffer
+	pages          map[geom.Rect64]*staticBuffer
+
+	emptyCellFunc EmptyCellFunc
+	fillerGID     uint32
+	shader        *LayerShader
+	blendMode     *tiled.BlendMode
+	tileColors    map[tiled.Cell]color.Color
+}
+
+var (
+	layerStates      sideTable[tiled.Layer, layerState]
+	imageLayerBlends sideTable[tiled.ImageLayer, tiled.BlendMode]
+	objectTiles      sideTable[tiled.Object, tiled.Tile]
+)
+
+// stateOf returns the state kept for the layer, creating it on first use.
+func stateOf(layer *tiled.Layer) *layerState {
+	return layerStates.getOrCreate(layer)
+}
+
+// dropDecoded drops everything decoded from the layer's cells, keeping its draw settings.
+func (state *layerState) dropDecoded() {
+	state.tiles = nil
+	state.partitions = nil
+	state.partitionUse = nil
+	state.partitionGrids = nil
+	state.decoder = nil
+	if state.static != nil {
+		state.static.release()
+		state.static = nil
+	}
+	for _, page := range state.pages {
+		page.release()
+	}
+	state.pages = nil
+}
+
+// ======================================================
+// Observer
+// ======================================================
+
+// observer keeps the package's state in step with changes made through package tiled.
+type observer struct{}
+
+func init() {
+	tiled.RegisterObserver(observer{})
+}
+
+func (observer) LayerCopied(src, dst *tiled.Layer) {
+	from := layerStates.get(src)
+	if from == nil {
+		return
+	}
+	to := stateOf(dst)
+	to.emptyCellFunc = from.emptyCellFunc
+	to.fillerGID = from.fillerGID
+	to.shader = from.shader
+	to.blendMode = from.blendMode
+	to.tileColors = maps.Clone(from.tileColors)
+}
+
+func (observer) LayerChanged(layer *tiled.Layer, cells []tiled.Cell) {
+	state := layerStates.get(layer)
+	if state == nil {
+		return
+	}
+	if cells == nil {
+		state.dropDecoded()
+		return
+	}
+	for _, cell := range cells {
+		state.markDirty(cell.X, cell.Y)
+	}
+	state.tiles = nil
+	state.partitions = nil
+}
+
+func (observer) MapReleased(tmx *tiled.TMX) {
+	for _, og := range tmx.ObjectGroups {
+		for _, obj := range og.Objects {
+			objectTiles.forget(weak.Make(obj))
+		}
+	}
+}
+
+func (observer) FileReleased(path string, file any) {
+	switch file := file.(type) {
+	case *tiled.TSX:
+		forgetTileset(path)
+	case image.Image:
+		forgetImage(file)
+	}
+}
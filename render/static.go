@@ -0,0 +1,337 @@
+package render
+
+import (
+	"cmp"
+	"image"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Static Layer Buffers
+// ======================================================
+
+// maxStaticBufferSize is the largest offscreen image, in pixels per side, a finite layer is rendered
+// into. Larger layers are drawn tile by tile.
+const maxStaticBufferSize = 8192
+
+// staticBuffer holds a block of cells of a tile layer rendered into an offscreen image: the whole
+// layer for finite maps, or a single chunk, a page, for infinite maps. Cells changed since the image
+// was rendered are tracked so only their area is rendered again.
+type staticBuffer struct {
+	img *ebiten.Image
+
+	// Cells the buffer renders.
+	cells image.Rectangle
+
+	// Layer position, in pixels, of the image's top-left corner.
+	origin image.Point
+
+	// How far the rendered tiles reach past their cells, in pixels: Min up and left, Max down and right.
+	overhang image.Rectangle
+
+	// Cells changed since the image was rendered.
+	dirty image.Rectangle
+
+	// Downscaled copies of the image, each half the size of the last, built as zoomed out views need them.
+	mips []*ebiten.Image
+}
+
+// markDirty records that a cell changed, so its area is rendered again on next draw.
+func (buf *staticBuffer) markDirty(x, y int) {
+	if buf == nil || !image.Pt(x, y).In(buf.cells) {
+		return
+	}
+	buf.dirty = buf.dirty.Union(image.Rect(x, y, x+1, y+1))
+}
+
+// markDirty records that a cell changed in whichever buffer renders it.
+func (state *layerState) markDirty(x, y int) {
+	state.static.markDirty(x, y)
+	for _, page := range state.pages {
+		page.markDirty(x, y)
+	}
+}
+
+// usesStaticBuffer reports whether the layer is drawn from static buffers: the configuration asks
+// for it and nothing changes how individual tiles are drawn.
+func (state *layerState) usesStaticBuffer(inst *tiled.MapInstance, layerName string) bool {
+	if !tiled.CurrentConfig().StaticLayerBuffers || state.emptyCellFunc != nil {
+		return false
+	}
+	return inst == nil || (len(inst.TileOverrides()) == 0 && tileFuncOf(inst, layerName) == nil)
+}
+
+// drawStaticLayer draws a finite tile layer from its static buffer.
+func drawStaticLayer(mode DrawMode, destImg *ebiten.Image, layer *tiled.Layer, state *layerState, region *geom.Rect64, view *ebiten.GeoM, cellWidth, cellHeight int, renderOrder tiled.RenderOrder) error {
+	if state.tiles == nil {
+		return nil
+	}
+
+	cells := image.Rect(0, 0, layer.Width(), layer.Height())
+	buf, err := updateStaticBuffer(state, state.static, cells, state.tiles.overhang, cellWidth, cellHeight, func(area *geom.Rect64) []tileRef {
+		return collectTiles(state, area, cellWidth, cellHeight, false, renderOrder)
+	})
+	if err != nil {
+		return err
+	}
+
+	state.static = buf
+	buf.draw(mode, destImg, layer, state, region, view)
+	return nil
+}
+
+// drawChunkPages draws the decoded chunks of an infinite tile layer that overlap the region, each
+// from its own page, in the map's render order.
+func drawChunkPages(mode DrawMode, destImg *ebiten.Image, layer *tiled.Layer, state *layerState, region *geom.Rect64, view *ebiten.GeoM, cellWidth, cellHeight int, renderOrder tiled.RenderOrder) error {
+	var rects []geom.Rect64
+	for rect := range state.partitions {
+		if region.Intersects(rect) {
+			rects = append(rects, rect)
+		}
+	}
+	sortChunks(rects, renderOrder)
+
+	if state.pages == nil {
+		state.pages = make(map[geom.Rect64]*staticBuffer)
+	}
+
+	for _, rect := range rects {
+		block := state.partitions[rect]
+		if state.pages[rect] == nil && !decodeBudgetLeft() {
+			// Left empty until a frame with budget to render its page.
+			continue
+		}
+		x, y := int(rect.X)/cellWidth, int(rect.Y)/cellHeight
+		cells := image.Rect(x, y, x+int(rect.Width)/cellWidth, y+int(rect.Height)/cellHeight)
+
+		page, err := updateStaticBuffer(state, state.pages[rect], cells, block.overhang, cellWidth, cellHeight, func(area *geom.Rect64) []tileRef {
+			tiles := appendBlockTiles(nil, block, area)
+			if renderOrder != tiled.TMXRightDown {
+				sortTiles(tiles, renderOrder)
+			}
+			return tiles
+		})
+		if err != nil {
+			return err
+		}
+
+		state.pages[rect] = page
+		page.draw(mode, destImg, layer, state, region, view)
+	}
+
+	return nil
+}
+
+// sortChunks orders chunks so that tiles reaching into neighboring chunks overlap them like the
+// editor draws them.
+func sortChunks(rects []geom.Rect64, renderOrder tiled.RenderOrder) {
+	rowDir, colDir := 1, 1
+	switch renderOrder {
+	case tiled.TMXRightUp:
+		rowDir = -1
+	case tiled.TMXLeftDown:
+		colDir = -1
+	case tiled.TMXLeftUp:
+		rowDir, colDir = -1, -1
+	}
+
+	slices.SortFunc(rects, func(a, b geom.Rect64) int {
+		if a.Y != b.Y {
+			return rowDir * cmp.Compare(a.Y, b.Y)
+		}
+		return colDir * cmp.Compare(a.X, b.X)
+	})
+}
+
+// updateStaticBuffer returns a buffer of the cells with every tile rendered. The buffer is created,
+// or rendered again at a new size when tiles reach further past their cells than it has room for.
+// Otherwise only the area of changed cells is rendered again. collect returns the tiles overlapping
+// an area, in render order.
+func updateStaticBuffer(state *layerState, buf *staticBuffer, cells, overhang image.Rectangle, cellWidth, cellHeight int, collect func(*geom.Rect64) []tileRef) (*staticBuffer, error) {
+	if buf == nil || !buf.fits(overhang) {
+		statsCache(false)
+		defer spendDecodeBudget(time.Now())
+
+		area := image.Rect(cells.Min.X*cellWidth, cells.Min.Y*cellHeight, cells.Max.X*cellWidth, cells.Max.Y*cellHeight)
+		area.Min = area.Min.Add(overhang.Min)
+		area.Max = area.Max.Add(overhang.Max)
+
+		if buf != nil {
+			buf.release()
+		}
+		buf = &staticBuffer{
+			img:      ebiten.NewImage(max(area.Dx(), 1), max(area.Dy(), 1)),
+			cells:    cells,
+			origin:   area.Min,
+			overhang: overhang,
+		}
+		return buf, buf.render(state, area, collect)
+	}
+
+	statsCache(buf.dirty.Empty())
+	if buf.dirty.Empty() {
+		return buf, nil
+	}
+
+	// Tiles of neighboring cells may reach into the changed cells, so they are drawn again as well.
+	dirty := buf.dirty
+	area := image.Rect(dirty.Min.X*cellWidth, dirty.Min.Y*cellHeight, dirty.Max.X*cellWidth, dirty.Max.Y*cellHeight)
+	area.Min = area.Min.Add(buf.overhang.Min)
+	area.Max = area.Max.Add(buf.overhang.Max)
+	buf.dirty = image.Rectangle{}
+	buf.dropMips()
+
+	return buf, buf.render(state, area, collect)
+}
+
+// render clears an area of the buffer, given in layer pixels, and draws the tiles overlapping it.
+func (buf *staticBuffer) render(state *layerState, area image.Rectangle, collect func(*geom.Rect64) []tileRef) error {
+	area = area.Intersect(buf.img.Bounds().Add(buf.origin))
+	if area.Empty() {
+		return nil
+	}
+
+	dest := buf.img.SubImage(area.Sub(buf.origin)).(*ebiten.Image)
+	dest.Clear()
+
+	region := geom.NewRect64(float64(area.Min.X), float64(area.Min.Y), float64(area.Dx()), float64(area.Dy()))
+	tiles := collect(&region)
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	batch := acquireTileBatch(dest)
+	defer releaseTileBatch(batch)
+
+	for _, ref := range tiles {
+		tile := ref.block.tile(ref.index)
+
+		src, err := tileSource(&tile)
+		if err != nil {
+			return err
+		}
+
+		op.ColorScale.Reset()
+		state.tileColor(&op.ColorScale, tile.Cell)
+
+		op.GeoM.Reset()
+		flipGeoM(&op.GeoM, &tile)
+		op.GeoM.Translate(tile.X-float64(buf.origin.X), tile.Y-float64(buf.origin.Y))
+
+		batch.add(src, op)
+	}
+
+	batch.flush()
+	return nil
+}
+
+// draw draws the buffer's image where its cells are, tinted by the layer.
+func (buf *staticBuffer) draw(mode DrawMode, destImg *ebiten.Image, layer *tiled.Layer, state *layerState, region *geom.Rect64, view *ebiten.GeoM) {
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	op.ColorScale.ScaleWithColor(layer.TintColor())
+	op.ColorScale.ScaleAlpha(float32(layer.Opacity()))
+	op.Blend = layerBlendOf(LayerBlendMode(layer))
+
+	img := buf.img
+	if mode == DrawModeScene && tiled.CurrentConfig().LayerLOD {
+		img = buf.mip(mipLevel(*view))
+		if img != buf.img {
+			// Scaled back up to the buffer's size; smoothed, since the copy holds averaged pixels.
+			op.GeoM.Scale(float64(buf.img.Bounds().Dx())/float64(img.Bounds().Dx()), float64(buf.img.Bounds().Dy())/float64(img.Bounds().Dy()))
+			op.Filter = ebiten.FilterLinear
+		}
+	}
+
+	x, y := float64(buf.origin.X), float64(buf.origin.Y)
+	switch mode {
+	case DrawModeNormal:
+		op.GeoM.Translate(x, y)
+	case DrawModeRegional:
+		minx, miny := region.Min()
+		op.GeoM.Translate(x-minx, y-miny)
+	case DrawModeScene:
+		op.GeoM.Translate(x, y)
+		op.GeoM.Concat(*view)
+	default:
+		panic("unhandled draw mode")
+	}
+
+	if shader := state.shader; shader != nil {
+		var shaderOpts ebiten.DrawRectShaderOptions
+		shaderOpts.GeoM = op.GeoM
+		shaderOpts.ColorScale = op.ColorScale
+		shaderOpts.Blend = op.Blend
+		shaderOpts.Uniforms = shader.Uniforms
+		shaderOpts.Images[0] = img
+		destImg.DrawRectShader(img.Bounds().Dx(), img.Bounds().Dy(), shader.Shader, &shaderOpts)
+	} else {
+		destImg.DrawImage(img, op)
+	}
+	renderStats.drawCalls.Add(1)
+}
+
+// mipLevel returns which downscaled copy suits a view: 0 for the full size image, then one level
+// for every halving of the view's zoom below one half.
+func mipLevel(view ebiten.GeoM) int {
+	a, b, c, d := view.Element(0, 0), view.Element(0, 1), view.Element(1, 0), view.Element(1, 1)
+	scale := math.Sqrt(math.Abs(a*d - b*c))
+	if scale <= 0 || scale >= 0.5 {
+		return 0
+	}
+	return int(math.Floor(math.Log2(1 / scale)))
+}
+
+// mip returns the downscaled copy of the image at the level, or the smallest copy there is when the
+// image cannot be halved that many times.
+func (buf *staticBuffer) mip(level int) *ebiten.Image {
+	img := buf.img
+	for i := range level {
+		if i < len(buf.mips) {
+			img = buf.mips[i]
+			continue
+		}
+
+		w, h := img.Bounds().Dx(), img.Bounds().Dy()
+		if w <= 1 && h <= 1 {
+			break
+		}
+
+		next := ebiten.NewImage(max((w+1)/2, 1), max((h+1)/2, 1))
+		op := acquireDrawOptions()
+		op.GeoM.Scale(float64(next.Bounds().Dx())/float64(w), float64(next.Bounds().Dy())/float64(h))
+		op.Filter = ebiten.FilterLinear
+		next.DrawImage(img, op)
+		releaseDrawOptions(op)
+
+		buf.mips = append(buf.mips, next)
+		img = next
+	}
+	return img
+}
+
+func (buf *staticBuffer) dropMips() {
+	for _, mip := range buf.mips {
+		mip.Deallocate()
+	}
+	buf.mips = nil
+}
+
+// release frees the buffer's images.
+func (buf *staticBuffer) release() {
+	buf.dropMips()
+	buf.img.Deallocate()
+}
+
+// fits reports whether the buffer has room for tiles reaching as far past their cells as the overhang.
+func (buf *staticBuffer) fits(overhang image.Rectangle) bool {
+	return overhang.Min.X >= buf.overhang.Min.X && overhang.Min.Y >= buf.overhang.Min.Y &&
+		overhang.Max.X <= buf.overhang.Max.X && overhang.Max.Y <= buf.overhang.Max.Y
+}
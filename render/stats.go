@@ -0,0 +1,94 @@
+package render
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Render Statistics
+// ======================================================
+
+// RenderStats counts what the draw functions did since the counters were last reset.
+// Reset them once per frame with ResetStats to profile culling and caching frame by frame.
+type RenderStats struct {
+	// TilesConsidered is how many decoded tiles were tested against the drawn region.
+	TilesConsidered int64
+
+	// TilesDrawn is how many tiles were drawn, to the target image or into a static buffer.
+	TilesDrawn int64
+
+	// ChunksDecoded is how many chunks of infinite layers were decoded into tiles.
+	ChunksDecoded int64
+
+	// CacheHits is how many times decoded tiles, a static buffer or a page were reused from a previous draw.
+	CacheHits int64
+
+	// CacheMisses is how many times they had to be built instead.
+	CacheMisses int64
+
+	// DrawCalls is how many DrawImage and DrawTriangles calls drew tiles or buffers.
+	DrawCalls int64
+}
+
+var renderStats struct {
+	tilesConsidered atomic.Int64
+	tilesDrawn      atomic.Int64
+	chunksDecoded   atomic.Int64
+	cacheHits       atomic.Int64
+	cacheMisses     atomic.Int64
+	drawCalls       atomic.Int64
+}
+
+// Stats returns the render counters accumulated since the last ResetStats.
+func Stats() RenderStats {
+	return RenderStats{
+		TilesConsidered: renderStats.tilesConsidered.Load(),
+		TilesDrawn:      renderStats.tilesDrawn.Load(),
+		ChunksDecoded:   renderStats.chunksDecoded.Load(),
+		CacheHits:       renderStats.cacheHits.Load(),
+		CacheMisses:     renderStats.cacheMisses.Load(),
+		DrawCalls:       renderStats.drawCalls.Load(),
+	}
+}
+
+// ResetStats returns the render counters accumulated since the last reset and sets them back to zero.
+func ResetStats() RenderStats {
+	return RenderStats{
+		TilesConsidered: renderStats.tilesConsidered.Swap(0),
+		TilesDrawn:      renderStats.tilesDrawn.Swap(0),
+		ChunksDecoded:   renderStats.chunksDecoded.Swap(0),
+		CacheHits:       renderStats.cacheHits.Swap(0),
+		CacheMisses:     renderStats.cacheMisses.Swap(0),
+		DrawCalls:       renderStats.drawCalls.Swap(0),
+	}
+}
+
+// statsCache counts a cached result being reused, or built when hit is false.
+func statsCache(hit bool) {
+	if hit {
+		renderStats.cacheHits.Add(1)
+	} else {
+		renderStats.cacheMisses.Add(1)
+	}
+}
+
+// metricsStart returns the time a draw started, or the zero time when package tiled's metrics are disabled.
+func metricsStart() time.Time {
+	if tiled.CurrentMetrics() == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// metricsObserve reports the duration of a draw started with metricsStart to package tiled's metrics.
+func metricsObserve(name string, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	if m := tiled.CurrentMetrics(); m != nil {
+		m.ObserveDuration(name, time.Since(start))
+	}
+}
@@ -0,0 +1,311 @@
+package render
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"math"
+	"slices"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+// ======================================================
+// Tile Blocks
+// ======================================================
+
+// tileBlock holds the decoded tiles of a block of cells, the whole layer or a single chunk, as
+// parallel arrays indexed by tile rather than as one heap allocated Tile each. Tiles are kept in
+// cell order; empty cells are left out. Positions and sizes are derived from the cell and tileset
+// when a tile is viewed, so each tile costs a handful of bytes.
+type tileBlock struct {
+	originX, originY int // Pixel position of the block's first cell.
+	columns          int
+	cellWidth        int
+	cellHeight       int
+
+	sets []tileBlockSet
+
+	// How far the tiles reach past their cells, in pixels: Min up and left, Max down and right.
+	overhang image.Rectangle
+
+	// Whether any tile is animated.
+	animated bool
+
+	cells   []int32 // Row-major index of the tile's cell within the block.
+	ids     []uint32
+	set     []uint16
+	flags   []tiled.FlipFlags
+	widths  []int32
+	heights []int32
+}
+
+// tileBlockSet is a tileset referenced by the tiles of a block.
+type tileBlockSet struct {
+	source           string
+	tileset          *tiled.Tileset
+	offsetX, offsetY float64
+	anims            map[uint32]*tileAnimation
+}
+
+// tileRef points at one tile of a block.
+type tileRef struct {
+	block *tileBlock
+	index int
+}
+
+func (b *tileBlock) len() int {
+	return len(b.ids)
+}
+
+// cell returns the tile coordinates of the i-th tile.
+func (b *tileBlock) cell(i int) tiled.Cell {
+	n := int(b.cells[i])
+	return tiled.Cell{
+		X: b.originX/b.cellWidth + n%b.columns,
+		Y: b.originY/b.cellHeight + n/b.columns,
+	}
+}
+
+// bounds returns the position and size, in pixels, of the i-th tile.
+func (b *tileBlock) bounds(i int) (x, y, w, h float64) {
+	n := int(b.cells[i])
+	set := &b.sets[b.set[i]]
+	w, h = float64(b.widths[i]), float64(b.heights[i])
+	x = float64(b.originX+(n%b.columns)*b.cellWidth) + set.offsetX
+	y = float64(b.originY+(n/b.columns)*b.cellHeight) + set.offsetY + float64(b.cellHeight) - h
+	return x, y, w, h
+}
+
+// id returns the ID of the tile shown for the i-th tile: its current frame if it is animated.
+func (b *tileBlock) id(i int) uint32 {
+	id := b.ids[i]
+	if anim := b.sets[b.set[i]].anims[id]; anim != nil {
+		return anim.frameAt(AnimationTime())
+	}
+	return id
+}
+
+// is reports whether the i-th tile is the tile with the ID in the tileset.
+func (b *tileBlock) is(i int, key tiled.TileKey) bool {
+	return b.ids[i] == key.ID && b.sets[b.set[i]].source == key.Source
+}
+
+// tile returns a view of the i-th tile. The view is a copy; changing it does not change the block.
+func (b *tileBlock) tile(i int) tiled.Tile {
+	set := &b.sets[b.set[i]]
+	x, y, w, h := b.bounds(i)
+
+	tile := set.tileset.Tile(b.id(i))
+	tile.X, tile.Y = x, y
+	tile.Width, tile.Height = w, h
+	tile.Flags = b.flags[i]
+	tile.Cell = b.cell(i)
+	return tile
+}
+
+// decodeTiles decodes a block of raw cells into a tileBlock. The block starts at the pixel position
+// localStartX, localStartY and is layerWidth by layerHeight pixels.
+func decodeTiles(parsedData []uint32, tilesets []*tiled.Tileset, localStartX, localStartY, layerWidth, layerHeight, cellWidth, cellHeight int) (*tileBlock, error) {
+	block := &tileBlock{
+		originX:    localStartX,
+		originY:    localStartY,
+		columns:    layerWidth / cellWidth,
+		cellWidth:  cellWidth,
+		cellHeight: cellHeight,
+	}
+
+	type resolved struct {
+		index int
+		tsx   *tiled.TSX
+	}
+	sets := make(map[*tiled.Tileset]resolved)
+
+	for i, data := range parsedData {
+		gid := data & tiled.TILE_ID_MASK
+		if gid == 0 {
+			continue
+		}
+
+		tileset := tiled.TilesetOf(gid, tilesets)
+		if tileset == nil {
+			return nil, fmt.Errorf("no tileset found for GID %d", gid)
+		}
+
+		set, exists := sets[tileset]
+		if !exists {
+			tsx, err := tileset.TSX()
+			if err != nil {
+				return nil, err
+			}
+			set = resolved{index: len(block.sets), tsx: tsx}
+			sets[tileset] = set
+
+			bs := tileBlockSet{source: tileset.Source(), tileset: tileset, anims: tileAnimations(tsx)}
+			if tsx.TileOffset != nil {
+				bs.offsetX, bs.offsetY = float64(tsx.TileOffset.X()), float64(tsx.TileOffset.Y())
+			}
+			block.sets = append(block.sets, bs)
+		}
+
+		id := gid - tileset.FirstGID()
+		w, h := set.tsx.TileSize(id)
+		if block.sets[set.index].anims[id] != nil {
+			block.animated = true
+		}
+
+		block.cells = append(block.cells, int32(i))
+		block.ids = append(block.ids, id)
+		block.set = append(block.set, uint16(set.index))
+		block.flags = append(block.flags, tiled.FlipFlagsOf(data))
+		block.widths = append(block.widths, int32(w))
+		block.heights = append(block.heights, int32(h))
+
+		// Tiles are anchored at the bottom-left of their cell, then moved by the tileset's offset.
+		offX, offY := int(block.sets[set.index].offsetX), int(block.sets[set.index].offsetY)
+		block.overhang.Min.X = min(block.overhang.Min.X, offX)
+		block.overhang.Min.Y = min(block.overhang.Min.Y, offY+cellHeight-h)
+		block.overhang.Max.X = max(block.overhang.Max.X, offX+w-cellWidth)
+		block.overhang.Max.Y = max(block.overhang.Max.Y, offY)
+	}
+
+	return block, nil
+}
+
+// sortTiles orders tiles by cell so that overlapping tiles are drawn like the editor draws them.
+func sortTiles(tiles []tileRef, renderOrder tiled.RenderOrder) {
+	rowDir, colDir := 1, 1
+	switch renderOrder {
+	case tiled.TMXRightUp:
+		rowDir = -1
+	case tiled.TMXLeftDown:
+		colDir = -1
+	case tiled.TMXLeftUp:
+		rowDir, colDir = -1, -1
+	}
+
+	slices.SortStableFunc(tiles, func(a, b tileRef) int {
+		ac, bc := a.block.cell(a.index), b.block.cell(b.index)
+		if ac.Y != bc.Y {
+			return rowDir * cmp.Compare(ac.Y, bc.Y)
+		}
+		return colDir * cmp.Compare(ac.X, bc.X)
+	})
+}
+
+// ======================================================
+// Tile Iteration
+// ======================================================
+
+// TileView is a decoded tile of a layer, read in place from the layer's decoded tiles. It is only
+// valid during the callback it is passed to; call Tile to keep a copy.
+type TileView struct {
+	block *tileBlock
+	index int
+}
+
+// Cell returns the tile coordinates of the tile's cell.
+func (v TileView) Cell() tiled.Cell {
+	return v.block.cell(v.index)
+}
+
+// ID returns the tile's ID within its tileset. Animated tiles report the tile of their current frame.
+func (v TileView) ID() uint32 {
+	return v.block.id(v.index)
+}
+
+// TilesetSource returns the source of the tile's tileset.
+func (v TileView) TilesetSource() string {
+	return v.block.sets[v.block.set[v.index]].source
+}
+
+// Flags returns the tile's flip flags.
+func (v TileView) Flags() tiled.FlipFlags {
+	return v.block.flags[v.index]
+}
+
+// Bounds returns the area the tile is drawn over, in pixels.
+func (v TileView) Bounds() geom.Rect64 {
+	x, y, w, h := v.block.bounds(v.index)
+	return geom.NewRect64(x, y, w, h)
+}
+
+// Tile returns a copy of the tile.
+func (v TileView) Tile() tiled.Tile {
+	return v.block.tile(v.index)
+}
+
+// EachDecodedTileInRegion calls fn for every decoded tile of the layer drawn over the region, in
+// pixels, until fn returns false. Tiles are visited in cell order within each block of the layer, but
+// chunks of infinite layers come in no particular order. Nothing is allocated.
+//
+// Only tiles that are already decoded are visited: a layer that has not been drawn, or chunks that
+// are not decoded or were evicted, are silently skipped. The layer cannot decode its tiles without
+// its map's tilesets; use EachTileInRegion, which decodes the region first, unless the region was
+// just drawn.
+func EachDecodedTileInRegion(layer *tiled.Layer, region geom.Rect64, fn func(TileView) bool) {
+	state := layerStates.get(layer)
+	if state == nil {
+		return
+	}
+	if state.tiles != nil {
+		eachBlockTile(state.tiles, region, fn)
+		return
+	}
+	for rect, block := range state.partitions {
+		if region.Intersects(rect) && !eachBlockTile(block, region, fn) {
+			return
+		}
+	}
+}
+
+// EachTileInRegion decodes the tiles of the named layer of the map drawn over the region, in pixels,
+// and calls fn for each like EachDecodedTileInRegion.
+func EachTileInRegion(tmx *tiled.TMX, layerName string, region geom.Rect64, fn func(TileView) bool) error {
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		return fmt.Errorf("layer not found: %s", layerName)
+	}
+
+	tw, th := tmx.TileWidth(), tmx.TileHeight()
+	if err := processTiles(layer, stateOf(layer), tmx.Tilesets, &region, layer.Width()*tw, layer.Height()*th, tw, th, tmx.IsInfinite(), false); err != nil {
+		return err
+	}
+
+	EachDecodedTileInRegion(layer, region, fn)
+	return nil
+}
+
+// eachBlockTile calls fn for the tiles of the block overlapping the region, reporting whether fn
+// asked to carry on. Only the cells that can hold a tile reaching into the region are looked at.
+func eachBlockTile(block *tileBlock, region geom.Rect64, fn func(TileView) bool) bool {
+	if block.len() == 0 {
+		return true
+	}
+	minx, miny := region.Min()
+	maxx, maxy := region.Max()
+
+	// Tiles reach past their cells by at most the block's overhang.
+	cw, ch := float64(block.cellWidth), float64(block.cellHeight)
+	ox, oy := float64(block.originX), float64(block.originY)
+	c0 := max(0, int(math.Ceil((minx-ox-cw-float64(block.overhang.Max.X))/cw)))
+	c1 := min(block.columns-1, int(math.Floor((maxx-ox-float64(block.overhang.Min.X))/cw)))
+	r0 := max(0, int(math.Ceil((miny-oy-ch-float64(block.overhang.Max.Y))/ch)))
+	r1 := min(int(block.cells[block.len()-1])/block.columns, int(math.Floor((maxy-oy-float64(block.overhang.Min.Y))/ch)))
+
+	for row := r0; row <= r1 && c0 <= c1; row++ {
+		first, last := int32(row*block.columns+c0), int32(row*block.columns+c1)
+		i, _ := slices.BinarySearch(block.cells, first)
+		for ; i < block.len() && block.cells[i] <= last; i++ {
+			tminx, tminy, w, h := block.bounds(i)
+			if tminx+w < minx || tminx > maxx || tminy+h < miny || tminy > maxy {
+				continue
+			}
+			if !fn(TileView{block: block, index: i}) {
+				return false
+			}
+		}
+	}
+	return true
+}
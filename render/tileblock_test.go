@@ -0,0 +1,70 @@
+package render
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+)
+
+func TestEachTileInRegion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"level.tmx": {Data: []byte(`<map version="1.10" orientation="orthogonal" renderorder="right-down" width="6" height="5" tilewidth="16" tileheight="16" infinite="0">
+ <tileset firstgid="1" source="tall.tsx"/>
+ <layer id="1" name="ground" width="6" height="5">
+  <data encoding="csv">
+1,0,1,1,0,1,
+0,1,0,0,1,0,
+1,1,1,0,0,1,
+0,0,0,1,1,0,
+1,0,1,0,1,1
+</data>
+ </layer>
+</map>`)},
+		// Tiles are twice as tall as their cells and shifted right, so they reach into neighbouring cells.
+		"tall.tsx": {Data: []byte(`<tileset name="tall" tilewidth="16" tileheight="32" tilecount="1" columns="1"><tileoffset x="6" y="-3"/><image source="tall.png" width="16" height="32"/></tileset>`)},
+	}
+	defer tiled.ReleaseFS(fsys)
+
+	tmx, err := tiled.LoadTMX(fsys, "level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := tmx.LayerByName("ground")
+	if err := EachTileInRegion(tmx, "ground", geom.NewRect64(0, 0, 96, 80), func(TileView) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	block := stateOf(layer).tiles
+
+	regions := []geom.Rect64{
+		geom.NewRect64(0, 0, 96, 80),
+		geom.NewRect64(20, 20, 1, 1),
+		geom.NewRect64(33, 5, 30, 40),
+		geom.NewRect64(-50, -50, 60, 60),
+		geom.NewRect64(90, 70, 40, 40),
+		geom.NewRect64(200, 200, 10, 10),
+		geom.NewRect64(47, 0, 0, 80),
+	}
+	for _, region := range regions {
+		var want []int
+		minx, miny := region.Min()
+		maxx, maxy := region.Max()
+		for i := range block.len() {
+			x, y, w, h := block.bounds(i)
+			if x+w >= minx && x <= maxx && y+h >= miny && y <= maxy {
+				want = append(want, i)
+			}
+		}
+
+		var got []int
+		EachDecodedTileInRegion(layer, region, func(v TileView) bool {
+			got = append(got, v.index)
+			return true
+		})
+		if !slices.Equal(got, want) {
+			t.Errorf("region %v visited tiles %v, want %v", region, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Tile Colors
+// ======================================================
+
+// SetTileColor tints the tile at the given tile coordinates with the color when it is drawn, on top of
+// the layer's tint and opacity, e.g. to highlight reachable tiles or flash a damaged one. The color
+// stays on the cell when its tile changes. Passing nil removes the tint.
+func SetTileColor(layer *tiled.Layer, x, y int, clr color.Color) {
+	cell := tiled.Cell{X: x, Y: y}
+	state := stateOf(layer)
+	if clr == nil {
+		if _, exists := state.tileColors[cell]; !exists {
+			return
+		}
+		delete(state.tileColors, cell)
+	} else {
+		if state.tileColors == nil {
+			state.tileColors = make(map[tiled.Cell]color.Color)
+		}
+		state.tileColors[cell] = clr
+	}
+	state.markDirty(x, y)
+}
+
+// TileColor returns the tint set on the cell with SetTileColor, if any.
+func TileColor(layer *tiled.Layer, x, y int) (color.Color, bool) {
+	state := layerStates.get(layer)
+	if state == nil {
+		return nil, false
+	}
+	clr, exists := state.tileColors[tiled.Cell{X: x, Y: y}]
+	return clr, exists
+}
+
+// ClearTileColors removes every tint set with SetTileColor.
+func ClearTileColors(layer *tiled.Layer) {
+	state := layerStates.get(layer)
+	if state == nil {
+		return
+	}
+	for cell := range state.tileColors {
+		state.markDirty(cell.X, cell.Y)
+	}
+	state.tileColors = nil
+}
+
+// tileColor scales the color scale by the tint set on the cell, if any.
+func (state *layerState) tileColor(scale *ebiten.ColorScale, cell tiled.Cell) {
+	if tint, exists := state.tileColors[cell]; exists {
+		scale.ScaleWithColor(tint)
+	}
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// GPU Images
+// ======================================================
+
+// gpuImages caches the GPU copies of decoded images that are not ebiten images already, such as the
+// images of maps loaded with LoadTMXWithImages, so each is uploaded once.
+var (
+	gpuImagesMu sync.Mutex
+	gpuImages   = make(map[image.Image]*ebiten.Image)
+)
+
+// gpuImage returns the image as an ebiten image, uploading it on first use.
+func gpuImage(src image.Image) *ebiten.Image {
+	if img, ok := src.(*ebiten.Image); ok {
+		return img
+	}
+
+	gpuImagesMu.Lock()
+	defer gpuImagesMu.Unlock()
+
+	if img, exists := gpuImages[src]; exists {
+		return img
+	}
+	img := ebiten.NewImageFromImage(src)
+	gpuImages[src] = img
+	return img
+}
+
+// uploadedImage returns the image as an ebiten image if it is one or has already been uploaded.
+func uploadedImage(src image.Image) (*ebiten.Image, bool) {
+	if img, ok := src.(*ebiten.Image); ok {
+		return img, img != nil
+	}
+
+	gpuImagesMu.Lock()
+	defer gpuImagesMu.Unlock()
+
+	img, exists := gpuImages[src]
+	return img, exists
+}
+
+// ======================================================
+// Transparent Colors
+// ======================================================
+
+// keyedImages caches the copies of images with their transparent color keyed out, so each image is
+// keyed once rather than every time it is retrieved. Entries are keyed by the loaded image, so an
+// image reloaded as a new asset is keyed again.
+var (
+	keyedImagesMu sync.Mutex
+	keyedImages   = make(map[keyedImageKey]*ebiten.Image)
+)
+
+type keyedImageKey struct {
+	src   *ebiten.Image
+	trans color.NRGBA
+}
+
+// transparentImage returns the image with the color named by the image's trans attribute made
+// transparent, or the image itself when it has none.
+func transparentImage(src *ebiten.Image, img *tiled.Image) *ebiten.Image {
+	if img == nil {
+		return src
+	}
+	trans, ok := img.Trans()
+	if !ok {
+		return src
+	}
+
+	key := keyedImageKey{src: src, trans: trans}
+
+	keyedImagesMu.Lock()
+	defer keyedImagesMu.Unlock()
+
+	if keyed, exists := keyedImages[key]; exists {
+		return keyed
+	}
+
+	bounds := src.Bounds()
+	pixels := make([]byte, 4*bounds.Dx()*bounds.Dy())
+	src.ReadPixels(pixels)
+
+	// Pixels are premultiplied, so only fully opaque pixels can match the color exactly.
+	for i := 0; i < len(pixels); i += 4 {
+		if pixels[i] == trans.R && pixels[i+1] == trans.G && pixels[i+2] == trans.B && pixels[i+3] == 0xff {
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = 0, 0, 0, 0
+		}
+	}
+
+	keyed := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	keyed.WritePixels(pixels)
+	keyedImages[key] = keyed
+	return keyed
+}
+
+// keyedImage returns the copy of the image with the image's transparent color keyed out, if it has
+// already been made.
+func keyedImage(src *ebiten.Image, img *tiled.Image) (*ebiten.Image, bool) {
+	trans, ok := img.Trans()
+	if !ok {
+		return nil, false
+	}
+
+	keyedImagesMu.Lock()
+	defer keyedImagesMu.Unlock()
+
+	keyed, exists := keyedImages[keyedImageKey{src: src, trans: trans}]
+	return keyed, exists
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Multiple Views
+// ======================================================
+
+// View is one of several places a map is drawn to in the same frame, such as each player's half of
+// a split screen.
+type View struct {
+	Target     *ebiten.Image
+	Viewport   geom.Rect64
+	ViewMatrix ebiten.GeoM
+}
+
+// DrawViews renders the TMX map into each view like DrawScene, as a single frame. Layer caches are
+// only trimmed once every view is drawn, so chunks one view needs are not evicted by drawing another.
+func DrawViews(ctx finch.Context, tmx *tiled.TMX, views []View) {
+	drawViews(ctx, tmx, nil, views)
+}
+
+// DrawInstanceViews renders the instance into each view like DrawViews renders a map.
+func DrawInstanceViews(ctx finch.Context, inst *tiled.MapInstance, views []View) {
+	drawViews(ctx, inst.TMX, inst, views)
+}
+
+func drawViews(ctx finch.Context, tmx *tiled.TMX, inst *tiled.MapInstance, views []View) {
+	for _, layer := range tmx.Layers {
+		state := stateOf(layer)
+		state.partitionTick++
+		state.inFrame = true
+	}
+	defer func() {
+		for _, layer := range tmx.Layers {
+			state := stateOf(layer)
+			state.inFrame = false
+			releaseLayerCache(layer, state)
+		}
+	}()
+
+	for i := range views {
+		view := &views[i]
+		if view.Target == nil {
+			continue
+		}
+		drawLayers(ctx, DrawModeScene, view.Target, tmx, inst, &view.Viewport, &view.ViewMatrix)
+	}
+}
@@ -0,0 +1,132 @@
+package render
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+
+	"github.com/adm87/finch-core/finch"
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ======================================================
+// Y-Sorted Drawing
+// ======================================================
+
+// Sprite is an image drawn among the tiles of a layer by the sorted draw functions, such as a
+// character walking behind and in front of props.
+type Sprite struct {
+	Image *ebiten.Image
+
+	// X and Y position the sprite in world space. Y is also where the sprite stands, its feet: the
+	// sprite is drawn in front of tiles whose bottom edge is above Y and behind the rest.
+	X, Y float64
+
+	// Transform is applied in sprite-local space before the sprite is positioned, e.g. to move its
+	// feet from the image's top-left corner to its bottom center.
+	Transform ebiten.GeoM
+
+	// ColorScale scales the sprite's colors. The zero value leaves them unchanged.
+	ColorScale ebiten.ColorScale
+}
+
+// DrawLayerSorted renders a tile layer of the TMX map together with the sprites, back to front by
+// the bottom edge of each tile and the feet of each sprite, like DrawLayer.
+func DrawLayerSorted(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, layerName string, sprites []Sprite) {
+	region := geom.NewRect64(0, 0, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
+	drawSortedLayer(ctx, DrawModeNormal, img, tmx, nil, layerName, sprites, &region, &ebiten.GeoM{})
+}
+
+// DrawSceneLayerSorted renders a tile layer of the TMX map together with the sprites, back to front
+// by the bottom edge of each tile and the feet of each sprite, as seen through a camera like DrawSceneLayer.
+func DrawSceneLayerSorted(ctx finch.Context, img *ebiten.Image, tmx *tiled.TMX, layerName string, sprites []Sprite, viewport geom.Rect64, viewMatrix ebiten.GeoM) {
+	drawSortedLayer(ctx, DrawModeScene, img, tmx, nil, layerName, sprites, &viewport, &viewMatrix)
+}
+
+// DrawInstanceSceneLayerSorted renders a tile layer of the instance together with the sprites like DrawSceneLayerSorted.
+func DrawInstanceSceneLayerSorted(ctx finch.Context, img *ebiten.Image, inst *tiled.MapInstance, layerName string, sprites []Sprite, viewport geom.Rect64, viewMatrix ebiten.GeoM) {
+	drawSortedLayer(ctx, DrawModeScene, img, inst.TMX, inst, layerName, sprites, &viewport, &viewMatrix)
+}
+
+func drawSortedLayer(ctx finch.Context, mode DrawMode, img *ebiten.Image, tmx *tiled.TMX, inst *tiled.MapInstance, layerName string, sprites []Sprite, region *geom.Rect64, view *ebiten.GeoM) {
+	defer metricsObserve(tiled.MetricDrawTime, metricsStart())
+
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		logDraw(ctx, slog.LevelWarn, ErrLayerNotFound, layerName, slog.String("layer", layerName))
+		return
+	}
+
+	sorted := slices.Clone(sprites)
+	slices.SortStableFunc(sorted, func(a, b Sprite) int {
+		return cmp.Compare(a.Y, b.Y)
+	})
+
+	if err := drawMapLayer(mode, img, layer, inst, tmx.Tilesets, region, view, tmx.TileWidth(), tmx.TileHeight(), tmx.IsInfinite(), tmx.RenderOrder(), sorted); err != nil {
+		logDraw(ctx, slog.LevelError, ErrWhileDrawingLayer, layer.Name(), slog.String("layer", layer.Name()), slog.Any("error", err))
+	}
+	if layerVisible(inst, layer.Name(), layer.IsVisible()) {
+		drawDecals(mode, img, inst, layer.Name(), region, view)
+	}
+}
+
+// sortTilesByBase orders tiles by their bottom edge, keeping the render order of tiles on the same row.
+func sortTilesByBase(tiles []tileRef) {
+	slices.SortStableFunc(tiles, func(a, b tileRef) int {
+		return cmp.Compare(a.block.base(a.index), b.block.base(b.index))
+	})
+}
+
+// base returns the bottom edge, in pixels, of the i-th tile.
+func (b *tileBlock) base(i int) float64 {
+	_, y, _, h := b.bounds(i)
+	return y + h
+}
+
+// drawSprites draws the sprites, sorted by Y, that stand above the given Y, and returns the rest.
+// Tiles queued in the batch, if any, are drawn first so the sprites land on top of them.
+func drawSprites(mode DrawMode, destImg *ebiten.Image, sprites []Sprite, until float64, batch *tileBatch, region *geom.Rect64, view *ebiten.GeoM) []Sprite {
+	n := 0
+	for n < len(sprites) && sprites[n].Y < until {
+		n++
+	}
+	if n == 0 {
+		return sprites
+	}
+
+	if batch != nil {
+		batch.flush()
+	}
+
+	op := acquireDrawOptions()
+	defer releaseDrawOptions(op)
+
+	for i := range n {
+		sprite := &sprites[i]
+		if sprite.Image == nil {
+			continue
+		}
+
+		op.GeoM = sprite.Transform
+		op.ColorScale = sprite.ColorScale
+
+		switch mode {
+		case DrawModeNormal:
+			op.GeoM.Translate(sprite.X, sprite.Y)
+		case DrawModeRegional:
+			minx, miny := region.Min()
+			op.GeoM.Translate(sprite.X-minx, sprite.Y-miny)
+		case DrawModeScene:
+			op.GeoM.Translate(sprite.X, sprite.Y)
+			op.GeoM.Concat(*view)
+		default:
+			panic("unhandled draw mode")
+		}
+
+		destImg.DrawImage(sprite.Image, op)
+		renderStats.drawCalls.Add(1)
+	}
+	return sprites[n:]
+}
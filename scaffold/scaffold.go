@@ -0,0 +1,308 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// ======================================================
+// Scaffold Options
+// ======================================================
+
+// Options describes the game to scaffold.
+type Options struct {
+	// Dir is the directory the game is written to. It is created if it doesn't exist.
+	Dir string
+
+	// Module is the Go module path of the generated game.
+	Module string
+
+	// Map is the path of the TMX map to display. It is copied into the game's assets directory
+	// together with the tilesets, templates and images it references.
+	Map string
+
+	// Width and Height are the window size in pixels. Zero uses 640x480.
+	Width, Height int
+
+	// TiledDir is the directory of a finch-tiled checkout the generated go.mod points at with a
+	// replace directive. Empty uses the checkout this package was built from.
+	TiledDir string
+}
+
+// ======================================================
+// Generate
+// ======================================================
+
+// Generate writes a minimal runnable ebiten game that loads the map and its images from the
+// game's assets directory with tiled.LoadTMXWithImages and draws a tiled.MapInstance of it with
+// package render through a camera that can be panned with the arrow keys. finch-tiled is not
+// published yet, so the generated go.mod replaces it with a local checkout; run go mod tidy in the
+// game's directory to resolve the other dependencies and write go.sum.
+// Existing files are never overwritten.
+func Generate(opts Options) error {
+	if opts.Dir == "" || opts.Module == "" || opts.Map == "" {
+		return fmt.Errorf("scaffold requires a directory, a module path and a map")
+	}
+	if opts.Width == 0 {
+		opts.Width = 640
+	}
+	if opts.Height == 0 {
+		opts.Height = 480
+	}
+	if opts.TiledDir == "" {
+		opts.TiledDir = sourceDir()
+		if opts.TiledDir == "" {
+			return fmt.Errorf("scaffold cannot find a finch-tiled checkout; set its directory")
+		}
+	}
+	tiledDir, err := filepath.Abs(opts.TiledDir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(tiledDir, "go.mod")); err != nil {
+		return fmt.Errorf("%s is not a finch-tiled checkout: %w", tiledDir, err)
+	}
+	opts.TiledDir = filepath.ToSlash(tiledDir)
+
+	assets := filepath.Join(opts.Dir, "assets")
+	if err := os.MkdirAll(assets, 0o755); err != nil {
+		return err
+	}
+
+	if err := copyMapFiles(opts.Map, assets); err != nil {
+		return err
+	}
+
+	data := struct {
+		Options
+		MapFile string
+	}{
+		Options: opts,
+		MapFile: filepath.Base(opts.Map),
+	}
+
+	var main bytes.Buffer
+	if err := mainTemplate.Execute(&main, data); err != nil {
+		return err
+	}
+	source, err := format.Source(main.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated main.go is invalid: %w", err)
+	}
+
+	var mod bytes.Buffer
+	if err := modTemplate.Execute(&mod, data); err != nil {
+		return err
+	}
+
+	if err := writeNew(filepath.Join(opts.Dir, "main.go"), source); err != nil {
+		return err
+	}
+	return writeNew(filepath.Join(opts.Dir, "go.mod"), mod.Bytes())
+}
+
+// sourceDir returns the root of the finch-tiled checkout this package was built from, or "" when
+// it was built without one, such as with -trimpath.
+func sourceDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok || !filepath.IsAbs(file) {
+		return ""
+	}
+	dir := filepath.Dir(filepath.Dir(file))
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// copyMapFiles copies the map into dir, following the tilesets, templates and images it references
+// so the copied map keeps resolving them through the same relative paths.
+func copyMapFiles(mapPath, dir string) error {
+	seen := make(map[string]bool)
+
+	var copyFile func(src, dst string) error
+	copyFile = func(src, dst string) error {
+		if seen[src] {
+			return nil
+		}
+		seen[src] = true
+
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := writeNew(dst, data); err != nil && !os.IsExist(err) {
+			return err
+		}
+
+		switch strings.ToLower(filepath.Ext(src)) {
+		case ".tmx", ".tsx", ".tx":
+		default:
+			return nil
+		}
+
+		for _, ref := range referencedFiles(data) {
+			if filepath.IsAbs(ref) || strings.HasPrefix(filepath.Clean(ref), "..") {
+				return fmt.Errorf("%s references %s outside of its directory; move it next to the map first", src, ref)
+			}
+			if err := copyFile(filepath.Join(filepath.Dir(src), ref), filepath.Join(filepath.Dir(dst), ref)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return copyFile(mapPath, filepath.Join(dir, filepath.Base(mapPath)))
+}
+
+// referencedFiles returns the values of every source and template attribute in a Tiled XML file.
+func referencedFiles(data []byte) []string {
+	var refs []string
+	for _, attr := range []string{`source="`, `template="`} {
+		rest := string(data)
+		for {
+			i := strings.Index(rest, attr)
+			if i < 0 {
+				break
+			}
+			rest = rest[i+len(attr):]
+			end := strings.IndexByte(rest, '"')
+			if end < 0 {
+				break
+			}
+			refs = append(refs, rest[:end])
+			rest = rest[end:]
+		}
+	}
+	return refs
+}
+
+// writeNew writes a file, failing if it already exists.
+func writeNew(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, bytes.NewReader(data)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ======================================================
+// Templates
+// ======================================================
+
+var modTemplate = template.Must(template.New("go.mod").Parse(`module {{.Module}}
+
+go 1.25
+
+require (
+	github.com/adm87/finch-core v0.0.10
+	github.com/adm87/finch-tiled v0.0.0
+	github.com/hajimehoshi/ebiten/v2 v2.8.8
+)
+
+replace github.com/adm87/finch-tiled => {{printf "%q" .TiledDir}}
+`))
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`package main
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-tiled/render"
+	"github.com/adm87/finch-tiled/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	screenWidth  = {{.Width}}
+	screenHeight = {{.Height}}
+	mapFile      = "{{.MapFile}}"
+	panSpeed     = 4
+)
+
+// gameContext provides the logger drawing reports problems through.
+type gameContext struct {
+	logger *slog.Logger
+}
+
+func (c gameContext) Logger() *slog.Logger {
+	return c.logger
+}
+
+type Game struct {
+	ctx      gameContext
+	instance *tiled.MapInstance
+	camX     float64
+	camY     float64
+}
+
+func (g *Game) Update() error {
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		g.camX -= panSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		g.camX += panSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+		g.camY -= panSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		g.camY += panSpeed
+	}
+	return g.instance.Update(time.Second / time.Duration(ebiten.TPS()))
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	viewport := geom.NewRect64(g.camX, g.camY, screenWidth, screenHeight)
+
+	var view ebiten.GeoM
+	view.Translate(-g.camX, -g.camY)
+
+	render.DrawInstanceScene(g.ctx, screen, g.instance, viewport, view)
+}
+
+func (g *Game) Layout(int, int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+func main() {
+	ctx := gameContext{logger: slog.New(slog.NewTextHandler(os.Stderr, nil))}
+
+	// The map, its tilesets and their images are read from the assets directory, relative to the
+	// directory the game is run from.
+	tmx, err := tiled.LoadTMXWithImages(os.DirFS("assets"), mapFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	instance := tiled.NewMapInstance(tmx)
+
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle("{{.Module}}")
+
+	game := &Game{
+		ctx:      ctx,
+		instance: instance,
+	}
+	if err := ebiten.RunGame(game); err != nil {
+		log.Fatal(err)
+	}
+}
+`))
@@ -0,0 +1,79 @@
+package tiled
+
+import "github.com/adm87/finch-core/enum"
+
+// ======================================================
+// Blend Mode
+// ======================================================
+
+// BlendModeProperty is the custom property a tile or image layer sets, in the editor, to the name of
+// the BlendMode it is drawn with, such as "add" for light layers or "multiply" for shadow layers.
+const BlendModeProperty = "blendmode"
+
+type BlendMode int
+
+const (
+	BlendModeNormal BlendMode = iota
+	BlendModeAdd
+	BlendModeMultiply
+	BlendModeScreen
+	BlendModeSubtract
+)
+
+func (bm BlendMode) String() string {
+	switch bm {
+	case BlendModeNormal:
+		return "normal"
+	case BlendModeAdd:
+		return "add"
+	case BlendModeMultiply:
+		return "multiply"
+	case BlendModeScreen:
+		return "screen"
+	case BlendModeSubtract:
+		return "subtract"
+	default:
+		return "unknown"
+	}
+}
+
+func (bm BlendMode) IsValid() bool {
+	return bm >= BlendModeNormal && bm <= BlendModeSubtract
+}
+
+func (bm BlendMode) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(bm)
+}
+
+func (bm *BlendMode) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[BlendMode](data)
+	if err != nil {
+		return err
+	}
+	*bm = val
+	return nil
+}
+
+// blendModeOf returns the blend mode named by the BlendModeProperty of a layer's properties. Layers
+// without the property, or naming an unknown mode, are drawn normally.
+func blendModeOf(props []*Property) BlendMode {
+	prop := findProperty(props, BlendModeProperty)
+	if prop == nil {
+		return BlendModeNormal
+	}
+	bm, err := enum.Value[BlendMode](prop.Value())
+	if err != nil {
+		return BlendModeNormal
+	}
+	return bm
+}
+
+// BlendMode returns the mode the layer's BlendModeProperty names.
+func (layer *Layer) BlendMode() BlendMode {
+	return blendModeOf(layer.Properties)
+}
+
+// BlendMode returns the mode the image layer's BlendModeProperty names.
+func (il *ImageLayer) BlendMode() BlendMode {
+	return blendModeOf(il.Properties)
+}
@@ -0,0 +1,128 @@
+package tiled
+
+import "slices"
+
+// ======================================================
+// Map Builder
+// ======================================================
+
+// TMXFormatVersion is the map format version written into maps built in code.
+const TMXFormatVersion = "1.10"
+
+// NewTMX returns an empty finite map of width by height tiles, ready to have tilesets, layers and
+// object groups added before it is drawn or written out.
+func NewTMX(orientation Orientation, width, height, tileWidth, tileHeight int) *TMX {
+	return &TMX{
+		Attrs: TiledXMLAttrTable{
+			VersionAttr:      AttrString(TMXFormatVersion),
+			OrientationAttr:  AttrString(orientation.String()),
+			RenderOrderAttr:  AttrString(TMXRightDown.String()),
+			WidthAttr:        AttrInt(width),
+			HeightAttr:       AttrInt(height),
+			TileWidthAttr:    AttrInt(tileWidth),
+			TileHeightAttr:   AttrInt(tileHeight),
+			InfiniteAttr:     AttrBool(false),
+			NextLayerIDAttr:  AttrInt(1),
+			NextObjectIDAttr: AttrInt(1),
+		},
+	}
+}
+
+// NewTileset returns a reference to the external tileset at source, whose first tile takes firstGID.
+// Tilesets are drawn from the loaded TSX asset, so the source must be a file the asset system can load.
+func NewTileset(source string, firstGID uint32) *Tileset {
+	return &Tileset{
+		Attrs: TiledXMLAttrTable{
+			FirstGIDAttr: AttrInt(int(firstGID)),
+			SourceAttr:   AttrString(source),
+		},
+	}
+}
+
+// NewLayer returns a finite tile layer of width by height empty cells, stored as CSV.
+func NewLayer(name string, width, height int) *Layer {
+	layer := &Layer{
+		Attrs: TiledXMLAttrTable{NameAttr: AttrString(name)},
+	}
+	setLayerCells(layer, make([]uint32, width*height), width, height, DataFormatCSV) // CSV encoding cannot fail.
+	return layer
+}
+
+// NewObject returns a rectangle object at x, y of width by height pixels.
+func NewObject(name string, x, y, width, height float64) *Object {
+	return &Object{
+		Attrs: TiledXMLAttrTable{
+			NameAttr:   AttrString(name),
+			XAttr:      numberAttr(x),
+			YAttr:      numberAttr(y),
+			WidthAttr:  numberAttr(width),
+			HeightAttr: numberAttr(height),
+		},
+	}
+}
+
+// AddTileset adds a tileset to the map, keeping tilesets ordered by first GID.
+func (tmx *TMX) AddTileset(tileset *Tileset) *Tileset {
+	tmx.Tilesets = append(tmx.Tilesets, tileset)
+	slices.SortStableFunc(tmx.Tilesets, func(a, b *Tileset) int {
+		return int(a.FirstGID()) - int(b.FirstGID())
+	})
+	return tileset
+}
+
+// AddLayer adds a tile layer on top of the map's other layers and gives it the next layer ID.
+func (tmx *TMX) AddLayer(layer *Layer) *Layer {
+	if layer.Attrs == nil {
+		layer.Attrs = make(TiledXMLAttrTable)
+	}
+	layer.Attrs[IDAttr] = AttrInt(tmx.nextID(NextLayerIDAttr))
+	tmx.appendOrdered(layer)
+	tmx.Layers = append(tmx.Layers, layer)
+	return layer
+}
+
+// AddObjectGroup adds an empty object group on top of the map's other layers and gives it the next layer ID.
+func (tmx *TMX) AddObjectGroup(name string) *ObjectGroup {
+	og := &ObjectGroup{
+		Attrs: TiledXMLAttrTable{
+			IDAttr:   AttrInt(tmx.nextID(NextLayerIDAttr)),
+			NameAttr: AttrString(name),
+		},
+	}
+	tmx.appendOrdered(og)
+	tmx.ObjectGroups = append(tmx.ObjectGroups, og)
+	return og
+}
+
+// AddObject adds an object to one of the map's object groups and gives it the next object ID.
+func (tmx *TMX) AddObject(og *ObjectGroup, obj *Object) *Object {
+	if obj.Attrs == nil {
+		obj.Attrs = make(TiledXMLAttrTable)
+	}
+	obj.Attrs[IDAttr] = AttrInt(tmx.nextID(NextObjectIDAttr))
+	og.Objects = append(og.Objects, obj)
+	if tmx.objects != nil {
+		tmx.objects.Add(obj)
+	}
+	return obj
+}
+
+// nextID returns the map's next layer or object ID and advances it.
+func (tmx *TMX) nextID(name string) int {
+	if tmx.Attrs == nil {
+		tmx.Attrs = make(TiledXMLAttrTable)
+	}
+
+	id := 1
+	if attr, ok := tmx.Attrs[name].(AttrInt); ok {
+		id = max(attr.Int(), 1)
+	}
+	tmx.Attrs[name] = AttrInt(id + 1)
+	return id
+}
+
+// appendOrdered records a new layer on top of the document order, first bringing the order in line
+// with layers added to or removed from the map's slices directly.
+func (tmx *TMX) appendOrdered(layer any) {
+	tmx.order = append(tmx.OrderedLayers(), layer)
+}
@@ -0,0 +1,74 @@
+package tiled
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultChunkSize is the chunk width and height, in tiles, Tiled uses for infinite maps.
+const DefaultChunkSize = 16
+
+// CompactChunks rewrites the chunks of an infinite layer so that every chunk is aligned to
+// DefaultChunkSize, duplicated or fragmented chunks are merged, and chunks with no tiles are dropped.
+// The layer keeps its data format. Decoded tiles are discarded and rebuilt from the compacted data
+// on the next draw.
+func CompactChunks(layer *Layer) error {
+	if layer.Data == nil || len(layer.Data.Chunks) == 0 {
+		return nil
+	}
+
+	type chunkKey struct{ x, y int }
+	merged := make(map[chunkKey][]uint32)
+
+	err := forEachCell(layer, func(x, y int, data uint32) {
+		if data&TILE_ID_MASK == 0 {
+			return
+		}
+		key := chunkKey{floorDiv(x, DefaultChunkSize) * DefaultChunkSize, floorDiv(y, DefaultChunkSize) * DefaultChunkSize}
+		cells, exists := merged[key]
+		if !exists {
+			cells = make([]uint32, DefaultChunkSize*DefaultChunkSize)
+			merged[key] = cells
+		}
+		cells[(y-key.y)*DefaultChunkSize+(x-key.x)] = data
+	})
+	if err != nil {
+		return fmt.Errorf("failed to compact layer %s: %w", layer.Name(), err)
+	}
+
+	keys := make([]chunkKey, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].y != keys[j].y {
+			return keys[i].y < keys[j].y
+		}
+		return keys[i].x < keys[j].x
+	})
+
+	format := layer.Data.Format()
+
+	chunks := make([]*DataChunk, 0, len(keys))
+	for _, key := range keys {
+		raw, err := encodeData(merged[key], DefaultChunkSize, format)
+		if err != nil {
+			return fmt.Errorf("failed to compact layer %s: %w", layer.Name(), err)
+		}
+		chunks = append(chunks, &DataChunk{
+			Attrs: TiledXMLAttrTable{
+				XAttr:      AttrInt(key.x),
+				YAttr:      AttrInt(key.y),
+				WidthAttr:  AttrInt(DefaultChunkSize),
+				HeightAttr: AttrInt(DefaultChunkSize),
+			},
+			Data: raw,
+		})
+	}
+
+	layer.Data.Chunks = chunks
+
+	layer.invalidate()
+
+	return nil
+}
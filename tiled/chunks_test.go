@@ -0,0 +1,40 @@
+package tiled
+
+import "testing"
+
+func TestCompactChunksKeepsDataFormat(t *testing.T) {
+	tmx := loadFixture(t, "infinite_gzip.tmx")
+	layer := tmx.LayerByName("ground")
+
+	want := make(map[Cell]uint32)
+	if err := forEachCell(layer, func(x, y int, data uint32) {
+		if data != 0 {
+			want[Cell{X: x, Y: y}] = data
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CompactChunks(layer); err != nil {
+		t.Fatal(err)
+	}
+
+	if format := layer.Data.Format(); format != DataFormatBase64Gzip {
+		t.Fatalf("compacted layer is %s/%s, want base64/gzip", format.Encoding, format.Compression)
+	}
+	got := 0
+	if err := forEachCell(layer, func(x, y int, data uint32) {
+		if data == 0 {
+			return
+		}
+		got++
+		if want[Cell{X: x, Y: y}] != data {
+			t.Errorf("cell %d,%d is %#x after compacting, want %#x", x, y, data, want[Cell{X: x, Y: y}])
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got != len(want) {
+		t.Errorf("%d tiles after compacting, want %d", got, len(want))
+	}
+}
@@ -0,0 +1,279 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Collision Grid
+// ======================================================
+
+// SolidFunc reports whether a tile blocks movement.
+type SolidFunc func(key TileKey) bool
+
+// AnyTileSolid treats every non-empty cell as solid.
+func AnyTileSolid(TileKey) bool {
+	return true
+}
+
+// CollisionGrid answers solidity queries for a tile layer.
+// Solidity is built one decoded block at a time - the whole layer for finite maps, or a single
+// chunk for infinite maps - only when a query first touches it, decoding only that block.
+// Segments are dropped together with the decoded chunks they were built from.
+type CollisionGrid struct {
+	layer      *Layer
+	tilesets   []*Tileset
+	solid      SolidFunc
+	cellWidth  int
+	cellHeight int
+
+	segments   map[*cellGrid][]bool
+	solidity   map[TileKey]bool
+	generation int
+	gridDrops  int
+
+	slope  SlopeFunc
+	slopes map[TileKey]*Slope
+
+	oneWay  OneWayFunc
+	oneWays map[TileKey]bool
+}
+
+// NewCollisionGrid creates a collision grid for the named tile layer of the map.
+// If solid is nil, every non-empty cell is solid.
+func NewCollisionGrid(tmx *TMX, layerName string, solid SolidFunc) (*CollisionGrid, error) {
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		return nil, fmt.Errorf("layer not found: %s", layerName)
+	}
+	if solid == nil {
+		solid = AnyTileSolid
+	}
+	return &CollisionGrid{
+		layer:      layer,
+		tilesets:   tmx.Tilesets,
+		solid:      solid,
+		cellWidth:  tmx.TileWidth(),
+		cellHeight: tmx.TileHeight(),
+		generation: layer.generation,
+		gridDrops:  layer.gridDrops,
+	}, nil
+}
+
+// CellSize returns the size of a cell in world units.
+func (cg *CollisionGrid) CellSize() (int, int) {
+	return cg.cellWidth, cg.cellHeight
+}
+
+// IsSolid reports whether the cell at the given tile coordinates is solid.
+// Cells outside the layer are not solid.
+func (cg *CollisionGrid) IsSolid(x, y int) (bool, error) {
+	segment, g, err := cg.segment(x, y)
+	if err != nil || segment == nil {
+		return false, err
+	}
+	return segment[g.index(x, y)], nil
+}
+
+// IsSolidAt reports whether the cell under a world position is solid.
+func (cg *CollisionGrid) IsSolidAt(worldX, worldY float64) (bool, error) {
+	return cg.IsSolid(cg.cellOf(worldX, worldY))
+}
+
+// Invalidate drops every built segment so solidity is rebuilt from the layer on the next query.
+func (cg *CollisionGrid) Invalidate() {
+	cg.segments = nil
+	cg.solidity = nil
+	cg.slopes = nil
+	cg.oneWays = nil
+}
+
+// Segments returns how many decoded blocks currently have solidity built.
+func (cg *CollisionGrid) Segments() int {
+	return len(cg.segments)
+}
+
+func (cg *CollisionGrid) cellOf(worldX, worldY float64) (int, int) {
+	return int(math.Floor(worldX / float64(cg.cellWidth))), int(math.Floor(worldY / float64(cg.cellHeight)))
+}
+
+// segment returns the solidity of the decoded block containing the cell, building it on first use.
+func (cg *CollisionGrid) segment(x, y int) ([]bool, *cellGrid, error) {
+	if cg.generation != cg.layer.generation {
+		cg.segments = nil
+		cg.generation = cg.layer.generation
+	}
+	if cg.gridDrops != cg.layer.gridDrops {
+		cg.dropStaleSegments()
+		cg.gridDrops = cg.layer.gridDrops
+	}
+
+	g, err := cg.layer.gridAt(x, y)
+	if err != nil || g == nil {
+		return nil, nil, err
+	}
+
+	if segment, exists := cg.segments[g]; exists {
+		return segment, g, nil
+	}
+
+	segment := make([]bool, len(g.data))
+	for i := range g.data {
+		segment[i] = cg.isSolidData(g.data[i])
+	}
+
+	if cg.segments == nil {
+		cg.segments = make(map[*cellGrid][]bool)
+	}
+	cg.segments[g] = segment
+
+	return segment, g, nil
+}
+
+// dropStaleSegments drops the segments of blocks the layer no longer has decoded, such as chunks
+// evicted by ChunkCacheLimit.
+func (cg *CollisionGrid) dropStaleSegments() {
+	for g := range cg.segments {
+		if !slices.Contains(cg.layer.grids, g) {
+			delete(cg.segments, g)
+		}
+	}
+}
+
+func (cg *CollisionGrid) isSolidData(data uint32) bool {
+	key, ok := TileKeyOf(data, cg.tilesets)
+	if !ok {
+		return false
+	}
+	if cg.slopeOf(key) != nil || cg.isOneWay(key) {
+		return false
+	}
+	if solid, exists := cg.solidity[key]; exists {
+		return solid
+	}
+	if cg.solidity == nil {
+		cg.solidity = make(map[TileKey]bool)
+	}
+	solid := cg.solid(key)
+	cg.solidity[key] = solid
+	return solid
+}
+
+// ======================================================
+// Slopes
+// ======================================================
+
+// Slope describes the walkable surface of a slope tile as heights at the cell's left and right edges,
+// from 0 at the bottom of the cell to 1 at the top. A 45 degree slope rising to the right is {0, 1}.
+type Slope struct {
+	Left, Right float64
+}
+
+// HeightAt returns the surface height, from 0 to 1, at a horizontal position from 0 to 1 across the cell.
+func (s Slope) HeightAt(t float64) float64 {
+	t = math.Max(0, math.Min(1, t))
+	return s.Left + (s.Right-s.Left)*t
+}
+
+// Normal returns the unit normal of the slope surface for a cell of the given size, pointing out of the surface.
+func (s Slope) Normal(cellWidth, cellHeight float64) geom.Point64 {
+	tx, ty := cellWidth, -(s.Right-s.Left)*cellHeight
+	length := math.Hypot(tx, ty)
+	return geom.NewPoint64(ty/length, -tx/length)
+}
+
+// SlopeFunc returns the slope of a tile, or false if the tile is not a slope.
+// Slope tiles are not solid; bodies resting on them follow their surface instead.
+type SlopeFunc func(key TileKey) (Slope, bool)
+
+// SetSlopeFunc configures which tiles are slopes. Passing nil disables slopes.
+func (cg *CollisionGrid) SetSlopeFunc(fn SlopeFunc) {
+	cg.slope = fn
+	cg.Invalidate()
+}
+
+// SlopeAt returns the slope of the cell at the given tile coordinates, or false if the cell isn't a slope.
+func (cg *CollisionGrid) SlopeAt(x, y int) (Slope, bool, error) {
+	if cg.slope == nil {
+		return Slope{}, false, nil
+	}
+	data, err := cg.layer.cellAt(x, y)
+	if err != nil {
+		return Slope{}, false, err
+	}
+	key, ok := TileKeyOf(data, cg.tilesets)
+	if !ok {
+		return Slope{}, false, nil
+	}
+	if slope := cg.slopeOf(key); slope != nil {
+		return *slope, true, nil
+	}
+	return Slope{}, false, nil
+}
+
+func (cg *CollisionGrid) slopeOf(key TileKey) *Slope {
+	if cg.slope == nil {
+		return nil
+	}
+	if slope, exists := cg.slopes[key]; exists {
+		return slope
+	}
+	if cg.slopes == nil {
+		cg.slopes = make(map[TileKey]*Slope)
+	}
+	var slope *Slope
+	if s, ok := cg.slope(key); ok {
+		slope = &s
+	}
+	cg.slopes[key] = slope
+	return slope
+}
+
+// ======================================================
+// One-Way Platforms
+// ======================================================
+
+// OneWayFunc reports whether a tile is a one-way platform.
+// One-way platforms only block bodies falling onto them from above.
+type OneWayFunc func(key TileKey) bool
+
+// SetOneWayFunc configures which tiles are one-way platforms. Passing nil disables one-way platforms.
+func (cg *CollisionGrid) SetOneWayFunc(fn OneWayFunc) {
+	cg.oneWay = fn
+	cg.Invalidate()
+}
+
+// IsOneWay reports whether the cell at the given tile coordinates is a one-way platform.
+func (cg *CollisionGrid) IsOneWay(x, y int) (bool, error) {
+	if cg.oneWay == nil {
+		return false, nil
+	}
+	data, err := cg.layer.cellAt(x, y)
+	if err != nil {
+		return false, err
+	}
+	key, ok := TileKeyOf(data, cg.tilesets)
+	if !ok {
+		return false, nil
+	}
+	return cg.isOneWay(key), nil
+}
+
+func (cg *CollisionGrid) isOneWay(key TileKey) bool {
+	if cg.oneWay == nil {
+		return false
+	}
+	if oneWay, exists := cg.oneWays[key]; exists {
+		return oneWay
+	}
+	if cg.oneWays == nil {
+		cg.oneWays = make(map[TileKey]bool)
+	}
+	oneWay := cg.oneWay(key)
+	cg.oneWays[key] = oneWay
+	return oneWay
+}
@@ -0,0 +1,119 @@
+package tiled
+
+import "testing"
+
+func decodedGrids(layer *Layer) int {
+	n := 0
+	for _, g := range layer.grids {
+		if g != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCollisionGridDecodesQueriedChunk(t *testing.T) {
+	// Three 4x4 chunks side by side, each with one tile.
+	tmx := loadTestMap(t, "collision/chunks.tmx")
+	cg, err := NewCollisionGrid(tmx, "ground", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if solid, err := cg.IsSolid(5, 0); err != nil || !solid {
+		t.Fatalf("cell 5,0: solid=%v err=%v, want solid", solid, err)
+	}
+	if solid, _ := cg.IsSolid(4, 0); solid {
+		t.Error("cell 4,0 is solid")
+	}
+	if n := decodedGrids(tmx.Layers[0]); n != 1 {
+		t.Errorf("%d chunks decoded for queries within one chunk, want 1", n)
+	}
+	if solid, _ := cg.IsSolid(20, 0); solid {
+		t.Error("cell outside every chunk is solid")
+	}
+	if n := decodedGrids(tmx.Layers[0]); n != 1 {
+		t.Errorf("%d chunks decoded after querying outside every chunk, want 1", n)
+	}
+}
+
+func TestCollisionGridDropsReleasedChunks(t *testing.T) {
+	// Three 4x4 chunks side by side, each with one tile.
+	tmx := loadTestMap(t, "collision/chunks.tmx")
+	layer := tmx.Layers[0]
+	cg, err := NewCollisionGrid(tmx, "ground", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cg.IsSolid(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	first := layer.grids[0]
+
+	layer.ReleaseChunk(0)
+
+	if layer.grids[0] != nil {
+		t.Fatal("cells of the released chunk are still decoded")
+	}
+	if solid, err := cg.IsSolid(9, 0); err != nil || solid {
+		t.Fatalf("cell 9,0: solid=%v err=%v, want not solid", solid, err)
+	}
+	if _, exists := cg.segments[first]; exists {
+		t.Error("segment of the released chunk was kept")
+	}
+	if solid, err := cg.IsSolid(0, 0); err != nil || !solid {
+		t.Errorf("cell 0,0 after release: solid=%v err=%v, want solid", solid, err)
+	}
+}
+
+func TestMemoryBudgetDropsLeastRecentlyUsedChunks(t *testing.T) {
+	configMutex.Lock()
+	saved := config
+	config.MemoryBudget = 1
+	configMutex.Unlock()
+	defer func() {
+		configMutex.Lock()
+		config = saved
+		configMutex.Unlock()
+	}()
+
+	// Three 4x4 chunks side by side, each with one tile.
+	tmx := loadTestMap(t, "collision/chunks.tmx")
+	layer := tmx.Layers[0]
+	defer tmx.Release()
+	cg, err := NewCollisionGrid(tmx, "ground", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, x := range []int{0, 4, 8} {
+		if _, err := cg.IsSolid(x, 0); err != nil {
+			t.Fatal(err)
+		}
+		budgetTick.Add(1)
+	}
+	if _, err := cg.IsSolid(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	generation := layer.generation
+
+	// Just under what is held, so only the least recently used chunk has to go.
+	configMutex.Lock()
+	config.MemoryBudget = cacheBytes.Load() - 1
+	configMutex.Unlock()
+	EnforceMemoryBudget()
+
+	if layer.grids[1] != nil || layer.grids[0] == nil || layer.grids[2] == nil {
+		t.Fatalf("decoded chunks after enforcing the budget: %v, want chunk 1 dropped", layer.grids)
+	}
+	if layer.generation != generation {
+		t.Error("enforcing the budget invalidated the layer")
+	}
+	if solid, err := cg.IsSolid(8, 0); err != nil || solid {
+		t.Errorf("cell 8,0: solid=%v err=%v, want not solid", solid, err)
+	}
+	if cg.Segments() != 2 {
+		t.Errorf("%d segments kept, want 2", cg.Segments())
+	}
+}
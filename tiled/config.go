@@ -0,0 +1,149 @@
+package tiled
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ======================================================
+// Config
+// ======================================================
+
+// ConfigVersion is the newest Config version understood by this package.
+// New options are only added behind a new version, and default to the previous behavior,
+// so existing configurations keep working unchanged.
+const ConfigVersion = 2
+
+// Config controls package-wide behavior, including how package render draws maps. It is applied with
+// Configure, or when package render registers the asset importers.
+type Config struct {
+	// Version is the config version the options were written against. Zero means ConfigVersion.
+	Version int
+
+	// DisableTileCache drops decoded tiles after every draw instead of keeping them between frames,
+	// trading draw time for memory.
+	DisableTileCache bool
+
+	// StrictParsing makes unknown XML attributes an import error instead of a logged warning.
+	StrictParsing bool
+
+	// Orientations limits which map orientations can be imported. Nil allows every orientation.
+	Orientations []Orientation
+
+	// MemoryBudget caps, in bytes, the decoded cell data kept across all layers, as reported by
+	// MetricCacheBytes. When a draw leaves the cache over budget, the least recently used blocks of
+	// cells, whole finite layers or single chunks, are dropped until it is back within budget and are
+	// decoded again when next needed. Decoded tiles and static buffers are kept. Zero means no budget.
+	MemoryBudget int64
+
+	// ChunkCacheLimit caps how many decoded chunks each layer of an infinite map keeps between frames.
+	// The least recently drawn chunks are dropped first and decoded again when they come back into view.
+	// Zero keeps every chunk. Requires version 2.
+	ChunkCacheLimit int
+
+	// AsyncChunkDecode decodes the chunks of infinite maps on background goroutines instead of inside
+	// the draw call. Chunks are left empty for the frames it takes to decode them. Requires version 2.
+	AsyncChunkDecode bool
+
+	// PredecodeLayers decodes the cell data of every tile layer in parallel when a map is imported,
+	// instead of on first draw. Requires version 2.
+	PredecodeLayers bool
+
+	// StaticLayerBuffers makes package render draw each tile layer of a finite map once into an
+	// offscreen image and draw that image every frame, re-rendering only the areas whose tiles changed.
+	// Infinite maps get an image, a page, per decoded chunk instead, dropped along with the chunk. Layers
+	// drawn with tile overrides, a render.TileDrawFunc or a render.EmptyCellFunc are drawn tile by tile
+	// as usual. Requires version 2.
+	StaticLayerBuffers bool
+
+	// LayerLOD makes render.DrawScene draw static layer buffers and pages from downscaled copies, each
+	// half the size of the last, once the view zooms out far enough for tiles to shrink below half their
+	// size. Requires StaticLayerBuffers and version 2.
+	LayerLOD bool
+
+	// DecodeBudget caps how long each frame spends decoding tile layers and chunks and rendering static
+	// layer buffers when they first come into view, spreading the work of entering a new area over several
+	// frames. Layers and chunks are left empty, and layers drawn tile by tile, until their turn comes. The
+	// budget is renewed by render.Advance, so call it once per update. Zero means no budget. Requires version 2.
+	DecodeBudget time.Duration
+}
+
+// DefaultConfig returns the configuration used when none is provided.
+func DefaultConfig() Config {
+	return Config{Version: ConfigVersion}
+}
+
+// Validate reports whether the configuration can be used by this version of the package.
+func (c Config) Validate() error {
+	if c.Version < 0 || c.Version > ConfigVersion {
+		return fmt.Errorf("unsupported config version %d, newest supported is %d", c.Version, ConfigVersion)
+	}
+	for _, o := range c.Orientations {
+		if !o.IsValid() {
+			return fmt.Errorf("invalid orientation in config: %d", o)
+		}
+	}
+	if c.MemoryBudget < 0 {
+		return fmt.Errorf("invalid memory budget: %d", c.MemoryBudget)
+	}
+	if c.ChunkCacheLimit < 0 {
+		return fmt.Errorf("invalid chunk cache limit: %d", c.ChunkCacheLimit)
+	}
+	if c.Version == 1 && c.ChunkCacheLimit != 0 {
+		return fmt.Errorf("chunk cache limit requires config version 2")
+	}
+	if c.Version == 1 && c.AsyncChunkDecode {
+		return fmt.Errorf("async chunk decode requires config version 2")
+	}
+	if c.Version == 1 && c.PredecodeLayers {
+		return fmt.Errorf("predecoding layers requires config version 2")
+	}
+	if c.Version == 1 && c.StaticLayerBuffers {
+		return fmt.Errorf("static layer buffers require config version 2")
+	}
+	if c.DecodeBudget < 0 {
+		return fmt.Errorf("invalid decode budget: %s", c.DecodeBudget)
+	}
+	if c.Version == 1 && c.DecodeBudget != 0 {
+		return fmt.Errorf("decode budget requires config version 2")
+	}
+	if c.LayerLOD && !c.StaticLayerBuffers {
+		return fmt.Errorf("layer LOD requires static layer buffers")
+	}
+	return nil
+}
+
+// AllowsOrientation reports whether maps of the orientation can be imported.
+func (c Config) AllowsOrientation(o Orientation) bool {
+	return c.Orientations == nil || slices.Contains(c.Orientations, o)
+}
+
+var (
+	config      = DefaultConfig()
+	configMutex sync.RWMutex
+)
+
+// CurrentConfig returns the configuration applied with Configure, or DefaultConfig if none was.
+func CurrentConfig() Config {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return config
+}
+
+// Configure validates the configuration and applies it to the package. A zero Version is taken to be
+// ConfigVersion.
+func Configure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.Version == 0 {
+		cfg.Version = ConfigVersion
+	}
+
+	configMutex.Lock()
+	config = cfg
+	configMutex.Unlock()
+	return nil
+}
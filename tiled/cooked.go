@@ -0,0 +1,261 @@
+package tiled
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"image"
+	"io"
+)
+
+// ======================================================
+// Cooked Maps
+// ======================================================
+
+// CookedAssetType is the asset type of maps written with WriteCooked.
+const CookedAssetType = "tmxc"
+
+// CookedFormatVersion is the version of the cooked format written by WriteCooked.
+// ReadCooked rejects any other version, so cooked maps must be rebuilt after upgrading.
+const CookedFormatVersion = 1
+
+var cookedMagic = [4]byte{'T', 'M', 'X', 'C'}
+
+func init() {
+	gob.Register(AttrString(""))
+	gob.Register(AttrInt(0))
+	gob.Register(AttrFloat(0))
+	gob.Register(AttrBool(false))
+	gob.Register(AttrColor{})
+}
+
+// cookedMap mirrors a TMX with its tile layers already decoded. Objects are mirrored separately
+// because their shape markers are empty structs, which gob cannot encode.
+type cookedMap struct {
+	Attrs        TiledXMLAttrTable
+	Properties   []*Property
+	Tilesets     []cookedTileset
+	Layers       []cookedLayer
+	ImageLayers  []*ImageLayer
+	ObjectGroups []cookedObjectGroup
+	Order        []cookedRef
+}
+
+// cookedTileset holds a tileset reference along with the source rectangle of each of its tiles in
+// the tileset image, indexed by tile ID. Image collections have no rectangles.
+type cookedTileset struct {
+	Attrs TiledXMLAttrTable
+	Rects []image.Rectangle
+}
+
+type cookedLayer struct {
+	Layer *Layer
+	Grids []cookedGrid
+}
+
+type cookedGrid struct {
+	X, Y, Width, Height int
+	Data                []uint32
+}
+
+type cookedObjectGroup struct {
+	Attrs      TiledXMLAttrTable
+	Properties []*Property
+	Objects    []cookedObject
+}
+
+type cookedObject struct {
+	Attrs          TiledXMLAttrTable
+	Properties     []*Property
+	Tileset        *Tileset
+	Polygon        *Polyline
+	Polyline       *Polyline
+	Text           *TextObject
+	Ellipse, Point bool
+}
+
+// cookedRef places a layer in document order: Kind selects the tile layer, image layer or object group
+// slice, and Index the layer within it.
+type cookedRef struct {
+	Kind  uint8
+	Index int
+}
+
+const (
+	cookedTileLayer uint8 = iota
+	cookedImageLayer
+	cookedObjectGroupLayer
+)
+
+// WriteCooked writes the map in the cooked binary format: every tile layer as flat arrays of raw cell
+// data, and the source rectangle of every tile of each loaded tileset. ReadCooked loads it without
+// parsing XML or decoding layer data. Tilesets that are not loaded are written without rectangles,
+// and their tiles are located in the tileset image when drawn, as usual.
+func WriteCooked(w io.Writer, tmx *TMX) error {
+	cm := cookedMap{
+		Attrs:       tmx.Attrs,
+		Properties:  tmx.Properties,
+		ImageLayers: tmx.ImageLayers,
+	}
+
+	for _, tileset := range tmx.Tilesets {
+		cm.Tilesets = append(cm.Tilesets, cookedTileset{Attrs: tileset.Attrs, Rects: cookTileRects(tileset)})
+	}
+
+	index := make(map[any]cookedRef)
+	for i, layer := range tmx.Layers {
+		grids, err := layer.cellGrids()
+		if err != nil {
+			return fmt.Errorf("failed to decode layer %s: %w", layer.Name(), err)
+		}
+		cl := cookedLayer{Layer: layer}
+		for _, g := range grids {
+			cl.Grids = append(cl.Grids, cookedGrid{X: g.x, Y: g.y, Width: g.width, Height: g.height, Data: g.data})
+		}
+		cm.Layers = append(cm.Layers, cl)
+		index[layer] = cookedRef{Kind: cookedTileLayer, Index: i}
+	}
+	for i, layer := range tmx.ImageLayers {
+		index[layer] = cookedRef{Kind: cookedImageLayer, Index: i}
+	}
+	for i, og := range tmx.ObjectGroups {
+		cog := cookedObjectGroup{Attrs: og.Attrs, Properties: og.Properties}
+		for _, obj := range og.Objects {
+			cog.Objects = append(cog.Objects, cookedObject{
+				Attrs:      obj.Attrs,
+				Properties: obj.Properties,
+				Tileset:    obj.Tileset,
+				Polygon:    obj.Polygon,
+				Polyline:   obj.Polyline,
+				Text:       obj.Text,
+				Ellipse:    obj.Ellipse != nil,
+				Point:      obj.Point != nil,
+			})
+		}
+		cm.ObjectGroups = append(cm.ObjectGroups, cog)
+		index[og] = cookedRef{Kind: cookedObjectGroupLayer, Index: i}
+	}
+	for _, layer := range tmx.OrderedLayers() {
+		cm.Order = append(cm.Order, index[layer])
+	}
+
+	if _, err := w.Write(cookedMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(CookedFormatVersion)); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(&cm)
+}
+
+// cookTileRects returns the source rectangle of every tile of a loaded tileset, located the same way
+// drawing locates them, or nil for image collections and tilesets that are not loaded.
+func cookTileRects(tileset *Tileset) []image.Rectangle {
+	tsx, err := tileset.TSX()
+	if err != nil || tsx == nil || tsx.IsImageCollection() {
+		return nil
+	}
+
+	rects := make([]image.Rectangle, tsx.TileCount())
+	for id := range rects {
+		w, h := tsx.TileSize(uint32(id))
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		tilesPerRow := tsx.Image.Width() / w
+		if tilesPerRow <= 0 {
+			return nil
+		}
+		x, y := (id%tilesPerRow)*w, (id/tilesPerRow)*h
+		rects[id] = image.Rect(x, y, x+w, y+h)
+	}
+	return rects
+}
+
+// ReadCooked loads a map written with WriteCooked. Its tile layers come back already decoded.
+func ReadCooked(r io.Reader) (*TMX, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	if magic != cookedMagic {
+		return nil, fmt.Errorf("not a cooked map")
+	}
+
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, err
+	}
+	if version != CookedFormatVersion {
+		return nil, fmt.Errorf("unsupported cooked map version %d, expected %d", version, CookedFormatVersion)
+	}
+
+	var cm cookedMap
+	if err := gob.NewDecoder(r).Decode(&cm); err != nil {
+		return nil, fmt.Errorf("invalid cooked map: %w", err)
+	}
+
+	tmx := &TMX{
+		Attrs:       cm.Attrs,
+		Properties:  cm.Properties,
+		ImageLayers: cm.ImageLayers,
+	}
+
+	for _, ct := range cm.Tilesets {
+		tmx.Tilesets = append(tmx.Tilesets, &Tileset{Attrs: ct.Attrs, rects: ct.Rects})
+	}
+
+	for _, cl := range cm.Layers {
+		layer := cl.Layer
+		if layer == nil {
+			return nil, fmt.Errorf("invalid cooked map: missing layer")
+		}
+		if layer.Data != nil {
+			grids := make([]*cellGrid, 0, len(cl.Grids))
+			for _, g := range cl.Grids {
+				grids = append(grids, &cellGrid{x: g.X, y: g.Y, width: g.Width, height: g.Height, data: g.Data})
+			}
+			layer.grids = grids
+			metricsCacheChanged(grids, 1)
+			layer.trackGrids()
+		}
+		tmx.Layers = append(tmx.Layers, layer)
+	}
+
+	for _, cog := range cm.ObjectGroups {
+		og := &ObjectGroup{Attrs: cog.Attrs, Properties: cog.Properties}
+		for _, co := range cog.Objects {
+			obj := &Object{
+				Attrs:      co.Attrs,
+				Properties: co.Properties,
+				Tileset:    co.Tileset,
+				Polygon:    co.Polygon,
+				Polyline:   co.Polyline,
+				Text:       co.Text,
+			}
+			if co.Ellipse {
+				obj.Ellipse = &Marker{}
+			}
+			if co.Point {
+				obj.Point = &Marker{}
+			}
+			og.Objects = append(og.Objects, obj)
+		}
+		tmx.ObjectGroups = append(tmx.ObjectGroups, og)
+	}
+
+	for _, ref := range cm.Order {
+		switch {
+		case ref.Kind == cookedTileLayer && ref.Index < len(tmx.Layers):
+			tmx.order = append(tmx.order, tmx.Layers[ref.Index])
+		case ref.Kind == cookedImageLayer && ref.Index < len(tmx.ImageLayers):
+			tmx.order = append(tmx.order, tmx.ImageLayers[ref.Index])
+		case ref.Kind == cookedObjectGroupLayer && ref.Index < len(tmx.ObjectGroups):
+			tmx.order = append(tmx.order, tmx.ObjectGroups[ref.Index])
+		default:
+			return nil, fmt.Errorf("invalid cooked map: bad layer reference %d/%d", ref.Kind, ref.Index)
+		}
+	}
+
+	return tmx, nil
+}
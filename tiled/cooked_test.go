@@ -0,0 +1,49 @@
+package tiled
+
+import (
+	"bytes"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestCookedRoundTrip(t *testing.T) {
+	for _, name := range []string{"ortho_zstd.tmx", "infinite_gzip.tmx"} {
+		tmx := loadFixture(t, name)
+		want, err := SummarizeMap(tmx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteCooked(&buf, tmx); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		cooked, err := ReadCooked(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		got, err := SummarizeMap(cooked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: cooked map summarizes as %+v, want %+v", name, got, want)
+		}
+		if len(cooked.order) != len(tmx.order) {
+			t.Errorf("%s: cooked map has %d layers in draw order, want %d", name, len(cooked.order), len(tmx.order))
+		}
+
+		// The tileset image is four tiles in a row.
+		if rects := cooked.Tilesets[0].rects; len(rects) != 4 || rects[2] != image.Rect(32, 0, 48, 16) {
+			t.Errorf("%s: cooked tile rects are %v", name, rects)
+		}
+	}
+}
+
+func TestReadCookedRejectsOtherData(t *testing.T) {
+	if _, err := ReadCooked(bytes.NewReader([]byte("<map/>"))); err == nil {
+		t.Error("read a TMX file as a cooked map")
+	}
+}
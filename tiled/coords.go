@@ -0,0 +1,250 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Coordinates
+// ======================================================
+
+// WorldToTile returns the cell under a world position, following the map's orientation.
+// Isometric maps place cell 0,0 at the top corner of the map, and staggered and hexagonal maps
+// shift every other row or column by the map's stagger axis and index, like Tiled.
+func (tmx TMX) WorldToTile(worldX, worldY float64) Cell {
+	tw, th := float64(tmx.TileWidth()), float64(tmx.TileHeight())
+
+	switch tmx.Orientation() {
+	case Isometric:
+		x := worldX - float64(tmx.Height())*tw/2
+		return Cell{
+			X: int(math.Floor(worldY/th + x/tw)),
+			Y: int(math.Floor(worldY/th - x/tw)),
+		}
+	case Staggered:
+		return tmx.stagger().staggeredToTile(worldX, worldY)
+	case Hexagonal:
+		return tmx.stagger().hexagonalToTile(worldX, worldY)
+	default:
+		return Cell{X: int(math.Floor(worldX / tw)), Y: int(math.Floor(worldY / th))}
+	}
+}
+
+// TileToWorld returns the top-left corner of the area a cell covers in world space, which is
+// where the layer draws its tile. For isometric, staggered and hexagonal maps this is the corner
+// of the cell's bounding box.
+func (tmx TMX) TileToWorld(cell Cell) geom.Point64 {
+	tw, th := float64(tmx.TileWidth()), float64(tmx.TileHeight())
+
+	switch tmx.Orientation() {
+	case Isometric:
+		return geom.Point64{
+			X: float64(cell.X-cell.Y)*tw/2 + float64(tmx.Height()-1)*tw/2,
+			Y: float64(cell.X+cell.Y) * th / 2,
+		}
+	case Staggered, Hexagonal:
+		return tmx.stagger().tileToWorld(cell)
+	default:
+		return geom.Point64{X: float64(cell.X) * tw, Y: float64(cell.Y) * th}
+	}
+}
+
+// staggerLayout holds the measurements shared by staggered and hexagonal maps.
+// Staggered maps are laid out as hexagonal maps whose side length is zero.
+type staggerLayout struct {
+	tileWidth, tileHeight    float64
+	sideLengthX, sideLengthY float64
+	sideOffsetX, sideOffsetY float64
+	columnWidth, rowHeight   float64
+	staggerX, staggerEven    bool
+}
+
+func (tmx TMX) stagger() staggerLayout {
+	l := staggerLayout{
+		tileWidth:   float64(tmx.TileWidth() &^ 1),
+		tileHeight:  float64(tmx.TileHeight() &^ 1),
+		staggerX:    tmx.StaggerAxis() == StaggerAxisX,
+		staggerEven: tmx.StaggerIndex() == StaggerIndexEven,
+	}
+
+	if tmx.Orientation() == Hexagonal {
+		if l.staggerX {
+			l.sideLengthX = float64(tmx.HexSideLength())
+		} else {
+			l.sideLengthY = float64(tmx.HexSideLength())
+		}
+	}
+
+	l.sideOffsetX = math.Floor((l.tileWidth - l.sideLengthX) / 2)
+	l.sideOffsetY = math.Floor((l.tileHeight - l.sideLengthY) / 2)
+	l.columnWidth = l.sideOffsetX + l.sideLengthX
+	l.rowHeight = l.sideOffsetY + l.sideLengthY
+
+	return l
+}
+
+// isShifted reports whether the cell's row or column is the one pushed along the stagger axis.
+func (l staggerLayout) isShifted(cell Cell) bool {
+	if l.staggerX {
+		return (cell.X&1 != 0) != l.staggerEven
+	}
+	return (cell.Y&1 != 0) != l.staggerEven
+}
+
+func (l staggerLayout) tileToWorld(cell Cell) geom.Point64 {
+	if l.staggerX {
+		p := geom.Point64{X: float64(cell.X) * l.columnWidth, Y: float64(cell.Y) * (l.tileHeight + l.sideLengthY)}
+		if l.isShifted(cell) {
+			p.Y += l.rowHeight
+		}
+		return p
+	}
+
+	p := geom.Point64{X: float64(cell.X) * (l.tileWidth + l.sideLengthX), Y: float64(cell.Y) * l.rowHeight}
+	if l.isShifted(cell) {
+		p.X += l.columnWidth
+	}
+	return p
+}
+
+// reference returns the cell at the top-left of the repeating block under a world position, along
+// with the position relative to that block. Blocks are blockWidth by blockHeight in size.
+func (l staggerLayout) reference(x, y, blockWidth, blockHeight float64) (Cell, float64, float64) {
+	rx, ry := math.Floor(x/blockWidth), math.Floor(y/blockHeight)
+	ref := Cell{X: int(rx), Y: int(ry)}
+
+	if l.staggerX {
+		ref.X *= 2
+		if l.staggerEven {
+			ref.X++
+		}
+	} else {
+		ref.Y *= 2
+		if l.staggerEven {
+			ref.Y++
+		}
+	}
+
+	return ref, x - rx*blockWidth, y - ry*blockHeight
+}
+
+// staggeredToTile picks the diamond under a world position by testing which of its four
+// neighbours' edges the position falls outside of.
+func (l staggerLayout) staggeredToTile(x, y float64) Cell {
+	if l.staggerX && l.staggerEven {
+		x -= l.sideOffsetX
+	} else if !l.staggerX && l.staggerEven {
+		y -= l.sideOffsetY
+	}
+
+	ref, relX, relY := l.reference(x, y, l.tileWidth, l.tileHeight)
+	slope := relX * (l.tileHeight / l.tileWidth)
+
+	switch {
+	case l.sideOffsetY-slope > relY:
+		return l.neighbour(ref, -1, -1)
+	case -l.sideOffsetY+slope > relY:
+		return l.neighbour(ref, 1, -1)
+	case l.sideOffsetY+slope < relY:
+		return l.neighbour(ref, -1, 1)
+	case l.sideOffsetY*3-slope < relY:
+		return l.neighbour(ref, 1, 1)
+	}
+	return ref
+}
+
+// neighbour returns the diagonal neighbour of a staggered cell in the direction dx, dy.
+func (l staggerLayout) neighbour(cell Cell, dx, dy int) Cell {
+	shifted := l.isShifted(cell)
+
+	if l.staggerX {
+		if shifted == (dy > 0) {
+			return Cell{X: cell.X + dx, Y: cell.Y + dy}
+		}
+		return Cell{X: cell.X + dx, Y: cell.Y}
+	}
+
+	if shifted == (dx > 0) {
+		return Cell{X: cell.X + dx, Y: cell.Y + dy}
+	}
+	return Cell{X: cell.X, Y: cell.Y + dy}
+}
+
+// hexagonalToTile picks the hexagon under a world position as the nearest of the four hexagon
+// centers that can overlap its block.
+func (l staggerLayout) hexagonalToTile(x, y float64) Cell {
+	if l.staggerX {
+		if l.staggerEven {
+			x -= l.tileWidth
+		} else {
+			x -= l.sideOffsetX
+		}
+	} else {
+		if l.staggerEven {
+			y -= l.tileHeight
+		} else {
+			y -= l.sideOffsetY
+		}
+	}
+
+	ref, relX, relY := l.reference(x, y, l.columnWidth*2, l.rowHeight*2)
+
+	var centers [4]geom.Point64
+	var offsets [4]Cell
+	if l.staggerX {
+		left := math.Floor(l.sideLengthX / 2)
+		centerX, centerY := left+l.columnWidth, l.tileHeight/2
+		centers = [4]geom.Point64{{X: left, Y: centerY}, {X: centerX, Y: centerY - l.rowHeight}, {X: centerX, Y: centerY + l.rowHeight}, {X: centerX + l.columnWidth, Y: centerY}}
+		offsets = [4]Cell{{0, 0}, {1, -1}, {1, 0}, {2, 0}}
+	} else {
+		top := math.Floor(l.sideLengthY / 2)
+		centerX, centerY := l.tileWidth/2, top+l.rowHeight
+		centers = [4]geom.Point64{{X: centerX, Y: top}, {X: centerX - l.columnWidth, Y: centerY}, {X: centerX + l.columnWidth, Y: centerY}, {X: centerX, Y: centerY + l.rowHeight}}
+		offsets = [4]Cell{{0, 0}, {-1, 1}, {0, 1}, {0, 2}}
+	}
+
+	nearest, best := 0, math.Inf(1)
+	for i, c := range centers {
+		if d := (c.X-relX)*(c.X-relX) + (c.Y-relY)*(c.Y-relY); d < best {
+			nearest, best = i, d
+		}
+	}
+
+	return Cell{X: ref.X + offsets[nearest].X, Y: ref.Y + offsets[nearest].Y}
+}
+
+// ======================================================
+// Picking
+// ======================================================
+
+// TileAt returns the tile of the named layer under a world position, or nil if the cell is empty.
+// The returned tile is positioned at TileToWorld of its cell, with its cell and flip flags set.
+// Cells of infinite maps are looked up in whichever chunk contains them.
+func (tmx TMX) TileAt(layerName string, worldX, worldY float64) (*Tile, error) {
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		return nil, fmt.Errorf("layer not found: %s", layerName)
+	}
+
+	cell := tmx.WorldToTile(worldX, worldY)
+
+	data, err := layer.cellAt(cell.X, cell.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	tile, err := TileOf(data, tmx.Tilesets, tmx.TileHeight())
+	if err != nil || tile == nil {
+		return nil, err
+	}
+
+	pos := tmx.TileToWorld(cell)
+	tile.X += pos.X
+	tile.Y += pos.Y
+	tile.Cell = cell
+
+	return tile, nil
+}
@@ -0,0 +1,195 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Cropping
+// ======================================================
+
+// Crop cuts every tile layer of the map down to the provided region, expressed in tiles,
+// and shifts objects so they keep their position relative to the remaining tiles.
+// Objects that end up entirely outside the region are removed.
+// Infinite maps are converted into finite maps of the region's size.
+func Crop(tmx *TMX, region geom.Rect64) error {
+	x0 := int(math.Floor(region.X))
+	y0 := int(math.Floor(region.Y))
+	w := int(math.Ceil(region.X+region.Width)) - x0
+	h := int(math.Ceil(region.Y+region.Height)) - y0
+
+	if w <= 0 || h <= 0 {
+		return fmt.Errorf("invalid crop region: %v", region)
+	}
+
+	// Every layer is cropped before any is changed, so a layer that fails leaves the map as it was.
+	croppedLayers := make([]layerCells, 0, len(tmx.Layers))
+	for _, layer := range tmx.Layers {
+		format := DataFormatCSV
+		if layer.Data != nil {
+			format = layer.Data.Format()
+		}
+
+		cropped := make([]uint32, w*h)
+		err := forEachCell(layer, func(x, y int, data uint32) {
+			if x >= x0 && x < x0+w && y >= y0 && y < y0+h {
+				cropped[(y-y0)*w+(x-x0)] = data
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to crop layer %s: %w", layer.Name(), err)
+		}
+		cells, err := encodeLayerCells(layer, cropped, w, h, format)
+		if err != nil {
+			return fmt.Errorf("failed to crop layer %s: %w", layer.Name(), err)
+		}
+		croppedLayers = append(croppedLayers, cells)
+	}
+	for _, cells := range croppedLayers {
+		cells.apply()
+	}
+
+	offsetX := x0 * tmx.TileWidth()
+	offsetY := y0 * tmx.TileHeight()
+	pixelW := w * tmx.TileWidth()
+	pixelH := h * tmx.TileHeight()
+
+	for _, og := range tmx.ObjectGroups {
+		objects := og.Objects[:0]
+		for _, obj := range og.Objects {
+			bounds := tmx.ObjectBounds(obj)
+			bounds.X -= float64(offsetX)
+			bounds.Y -= float64(offsetY)
+			if bounds.X+bounds.Width < 0 || bounds.Y+bounds.Height < 0 || bounds.X > float64(pixelW) || bounds.Y > float64(pixelH) {
+				continue
+			}
+			obj.Attrs[XAttr] = numberAttr(obj.X64() - float64(offsetX))
+			obj.Attrs[YAttr] = numberAttr(obj.Y64() - float64(offsetY))
+			objects = append(objects, obj)
+		}
+		og.Objects = objects
+	}
+	tmx.objects = nil
+
+	tmx.Attrs[WidthAttr] = AttrInt(w)
+	tmx.Attrs[HeightAttr] = AttrInt(h)
+	tmx.Attrs[InfiniteAttr] = AttrBool(false)
+
+	return nil
+}
+
+// TrimEmpty crops the map to the smallest region containing every non-empty tile and every object.
+// It returns the trimmed region in tiles, relative to the map's original origin.
+func TrimEmpty(tmx *TMX) (geom.Rect64, error) {
+	used, ok, err := usedRegion(tmx)
+	if err != nil {
+		return geom.Rect64{}, err
+	}
+	if !ok {
+		return geom.Rect64{}, fmt.Errorf("map is empty")
+	}
+	return used, Crop(tmx, used)
+}
+
+func usedRegion(tmx *TMX) (geom.Rect64, bool, error) {
+	minX, minY := math.MaxInt, math.MaxInt
+	maxX, maxY := math.MinInt, math.MinInt
+
+	include := func(x, y int) {
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+
+	for _, layer := range tmx.Layers {
+		err := forEachCell(layer, func(x, y int, data uint32) {
+			if data&TILE_ID_MASK != 0 {
+				include(x, y)
+			}
+		})
+		if err != nil {
+			return geom.Rect64{}, false, err
+		}
+	}
+
+	if tw, th := tmx.TileWidth(), tmx.TileHeight(); tw > 0 && th > 0 {
+		for _, og := range tmx.ObjectGroups {
+			for _, obj := range og.Objects {
+				// Objects cover the tiles their bounds overlap. Points and other objects without
+				// a size cover the tile they are in.
+				bounds := tmx.ObjectBounds(obj)
+				x0 := int(math.Floor(bounds.X / float64(tw)))
+				y0 := int(math.Floor(bounds.Y / float64(th)))
+				x1 := max(x0, int(math.Ceil((bounds.X+bounds.Width)/float64(tw)))-1)
+				y1 := max(y0, int(math.Ceil((bounds.Y+bounds.Height)/float64(th)))-1)
+				include(x0, y0)
+				include(x1, y1)
+			}
+		}
+	}
+
+	if minX > maxX || minY > maxY {
+		return geom.Rect64{}, false, nil
+	}
+
+	return geom.NewRect64(float64(minX), float64(minY), float64(maxX-minX+1), float64(maxY-minY+1)), true, nil
+}
+
+// setLayerCells replaces the layer's data with a finite grid encoded in the format and drops any
+// decoded tiles.
+func setLayerCells(layer *Layer, data []uint32, width, height int, format DataFormat) error {
+	cells, err := encodeLayerCells(layer, data, width, height, format)
+	if err != nil {
+		return err
+	}
+	cells.apply()
+	return nil
+}
+
+// layerCells is a finite grid of cells encoded for a layer, ready to replace the layer's data.
+// Edits spanning several layers encode every layer first and apply them once nothing can fail.
+type layerCells struct {
+	layer         *Layer
+	raw           string
+	width, height int
+	format        DataFormat
+}
+
+// encodeLayerCells encodes a finite grid of cells for the layer without changing the layer.
+func encodeLayerCells(layer *Layer, data []uint32, width, height int, format DataFormat) (layerCells, error) {
+	raw, err := encodeData(data, width, format)
+	if err != nil {
+		return layerCells{}, err
+	}
+	return layerCells{layer: layer, raw: raw, width: width, height: height, format: format}, nil
+}
+
+// apply replaces the layer's data with the encoded cells and drops any decoded tiles.
+func (c layerCells) apply() {
+	layer := c.layer
+	if layer.Data == nil {
+		layer.Data = &LayerData{}
+	}
+	if layer.Attrs == nil {
+		layer.Attrs = make(TiledXMLAttrTable)
+	}
+
+	layer.Data.setFormat(c.format)
+	layer.Data.Chunks = nil
+	layer.Data.Data = c.raw
+
+	layer.Attrs[WidthAttr] = AttrInt(c.width)
+	layer.Attrs[HeightAttr] = AttrInt(c.height)
+
+	layer.invalidate()
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
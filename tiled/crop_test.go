@@ -0,0 +1,110 @@
+package tiled
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+const cropTestMap = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="8" height="8" tilewidth="16" tileheight="16" infinite="0">
+ <tileset firstgid="1" source="tiles.tsx"/>
+ <layer id="1" name="ground" width="8" height="8">
+  <data encoding="csv">
+0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,
+0,0,0,3,0,0,0,0,
+0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0,
+0,0,0,0,0,0,0,0
+</data>
+ </layer>
+ <objectgroup id="2" name="things">
+  <object id="1" name="spawn" x="80" y="80"><point/></object>
+  <object id="2" name="crate" gid="1" x="32" y="48" width="16" height="16"/>
+ </objectgroup>
+</map>`
+
+func TestTrimEmptyObjectBounds(t *testing.T) {
+	tmx, err := ParseTMX(strings.NewReader(cropTestMap), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tile is at 3,4, the point in tile 5,5 and the bottom-aligned crate covers tile 2,2.
+	used, err := TrimEmpty(tmx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := geom.NewRect64(2, 2, 4, 4); used != want {
+		t.Fatalf("trimmed to %v, want %v", used, want)
+	}
+
+	objects := tmx.ObjectGroups[0].Objects
+	if len(objects) != 2 {
+		t.Fatalf("%d objects left after trimming, want 2", len(objects))
+	}
+	if x, y := objects[0].X64(), objects[0].Y64(); x != 48 || y != 48 {
+		t.Errorf("spawn moved to %v,%v, want 48,48", x, y)
+	}
+	if x, y := objects[1].X64(), objects[1].Y64(); x != 0 || y != 16 {
+		t.Errorf("crate moved to %v,%v, want 0,16", x, y)
+	}
+}
+
+func TestCropKeepsDataFormat(t *testing.T) {
+	tmx, err := ParseTMX(strings.NewReader(cropTestMap), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := tmx.Layers[0]
+
+	raw, err := encodeData(mustDecodeLayer(t, layer), layer.Width(), DataFormatBase64Zlib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer.Data.setFormat(DataFormatBase64Zlib)
+	layer.Data.Data = raw
+
+	if err := Crop(tmx, geom.NewRect64(3, 4, 2, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if format := layer.Data.Format(); format != DataFormatBase64Zlib {
+		t.Fatalf("cropped layer is %s/%s, want base64/zlib", format.Encoding, format.Compression)
+	}
+	if cells := mustDecodeLayer(t, layer); len(cells) != 2 || cells[0] != 3 || cells[1] != 0 {
+		t.Fatalf("cropped cells are %v, want [3 0]", cells)
+	}
+}
+
+func mustDecodeLayer(t *testing.T, layer *Layer) []uint32 {
+	t.Helper()
+	cells, err := layer.Data.decode(layer.Data.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cells
+}
+
+func TestCropFailureLeavesMapUntouched(t *testing.T) {
+	// The ground layer crops cleanly, but the broken layer's data can't be decoded.
+	tmx := loadTestMap(t, "resize/broken_layer.tmx")
+	ground := tmx.LayerByName("ground")
+
+	if err := Crop(tmx, geom.NewRect64(1, 1, 1, 1)); err == nil {
+		t.Fatal("cropping a map with an undecodable layer succeeded")
+	}
+
+	if tmx.Width() != 2 || tmx.Height() != 2 {
+		t.Errorf("failed crop changed the map to %dx%d", tmx.Width(), tmx.Height())
+	}
+	if ground.Width() != 2 || ground.Height() != 2 {
+		t.Errorf("failed crop changed ground to %dx%d", ground.Width(), ground.Height())
+	}
+	if n := len(tmx.ObjectGroups[0].Objects); n != 1 {
+		t.Errorf("failed crop left %d objects, want 1", n)
+	}
+}
@@ -0,0 +1,335 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adm87/finch-core/enum"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ======================================================
+// Compression
+// ======================================================
+
+type Compression int
+
+const (
+	TMXCompressionNone Compression = iota
+	TMXCompressionGzip
+	TMXCompressionZlib
+	TMXCompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case TMXCompressionNone:
+		return "none"
+	case TMXCompressionGzip:
+		return "gzip"
+	case TMXCompressionZlib:
+		return "zlib"
+	case TMXCompressionZstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+func (c Compression) IsValid() bool {
+	return c >= TMXCompressionNone && c <= TMXCompressionZstd
+}
+
+func (c Compression) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(c)
+}
+
+func (c *Compression) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[Compression](data)
+	if err != nil {
+		return err
+	}
+	*c = val
+	return nil
+}
+
+// ======================================================
+// Data Format
+// ======================================================
+
+// DataFormat describes how tile layer data is encoded and compressed.
+// Compression only applies to base64 encoded data.
+type DataFormat struct {
+	Encoding    Encoding
+	Compression Compression
+
+	// Level is the compression level used when writing, from 1 (fastest) to 9 (smallest) for gzip and
+	// zlib, or to 22 for zstd. Zero uses the default level.
+	Level int
+}
+
+// maxCompressionLevel returns the highest level the compression accepts.
+func maxCompressionLevel(compression Compression) int {
+	if compression == TMXCompressionZstd {
+		return 22
+	}
+	return 9
+}
+
+// Validate reports whether data can be written in the format.
+func (format DataFormat) Validate() error {
+	switch format.Encoding {
+	case TMXEncodingCSV:
+		return nil
+	case TMXEncodingBase64:
+	default:
+		return fmt.Errorf("unsupported layer data encoding: %s", format.Encoding)
+	}
+
+	switch format.Compression {
+	case TMXCompressionNone:
+		return nil
+	case TMXCompressionGzip, TMXCompressionZlib, TMXCompressionZstd:
+		if format.Level < 0 || format.Level > maxCompressionLevel(format.Compression) {
+			return fmt.Errorf("invalid %s compression level: %d", format.Compression, format.Level)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported layer data compression: %s", format.Compression)
+	}
+}
+
+var (
+	DataFormatCSV        = DataFormat{Encoding: TMXEncodingCSV}
+	DataFormatBase64     = DataFormat{Encoding: TMXEncodingBase64}
+	DataFormatBase64Gzip = DataFormat{Encoding: TMXEncodingBase64, Compression: TMXCompressionGzip}
+	DataFormatBase64Zlib = DataFormat{Encoding: TMXEncodingBase64, Compression: TMXCompressionZlib}
+	DataFormatBase64Zstd = DataFormat{Encoding: TMXEncodingBase64, Compression: TMXCompressionZstd}
+)
+
+// Format returns the encoding and compression of the layer data.
+func (data LayerData) Format() DataFormat {
+	return DataFormat{Encoding: data.Encoding(), Compression: data.Compression()}
+}
+
+// decode parses raw layer or chunk data according to the layer data's format.
+func (data LayerData) decode(raw string) ([]uint32, error) {
+	return DecodeData(raw, data.Format())
+}
+
+// clone returns a copy of the layer data that can be edited without changing the original.
+func (data *LayerData) clone() *LayerData {
+	cp := &LayerData{Attrs: maps.Clone(data.Attrs), Data: data.Data}
+	for _, chunk := range data.Chunks {
+		cp.Chunks = append(cp.Chunks, &DataChunk{Attrs: maps.Clone(chunk.Attrs), Data: chunk.Data})
+	}
+	return cp
+}
+
+// setFormat records the format of the layer data's attributes.
+func (data *LayerData) setFormat(format DataFormat) {
+	if data.Attrs == nil {
+		data.Attrs = make(TiledXMLAttrTable)
+	}
+	data.Attrs[EncodingAttr] = AttrString(format.Encoding.String())
+	if format.Encoding == TMXEncodingBase64 && format.Compression != TMXCompressionNone {
+		data.Attrs[CompressionAttr] = AttrString(format.Compression.String())
+	} else {
+		delete(data.Attrs, CompressionAttr)
+	}
+}
+
+// DecodeData parses raw layer or chunk data, as held by LayerData and DataChunk, in the format.
+func DecodeData(raw string, format DataFormat) ([]uint32, error) {
+	switch format.Encoding {
+	case TMXEncodingCSV:
+		return parseCsvData(raw)
+	case TMXEncodingBase64:
+		return parseBase64Data(raw, format.Compression)
+	default:
+		return nil, fmt.Errorf("unsupported layer data encoding: %s", format.Encoding)
+	}
+}
+
+func encodeData(data []uint32, width int, format DataFormat) (string, error) {
+	switch format.Encoding {
+	case TMXEncodingCSV:
+		return encodeCsvData(data, width), nil
+	case TMXEncodingBase64:
+		return encodeBase64Data(data, format.Compression, format.Level)
+	default:
+		return "", fmt.Errorf("unsupported layer data encoding: %s", format.Encoding)
+	}
+}
+
+func parseBase64Data(raw string, compression Compression) ([]uint32, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 layer data: %w", err)
+	}
+
+	var r io.Reader = bytes.NewReader(decoded)
+
+	switch compression {
+	case TMXCompressionNone:
+	case TMXCompressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip layer data: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	case TMXCompressionZlib:
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zlib layer data: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	case TMXCompressionZstd:
+		b, err := zstdDecoder().DecodeAll(decoded, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd layer data: %w", err)
+		}
+		r = bytes.NewReader(b)
+	default:
+		return nil, fmt.Errorf("unsupported layer data compression: %s", compression)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress layer data: %w", err)
+	}
+
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("invalid layer data length: %d bytes", len(b))
+	}
+
+	data := make([]uint32, len(b)/4)
+	for i := range data {
+		data[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	return data, nil
+}
+
+func encodeBase64Data(data []uint32, compression Compression, level int) (string, error) {
+	b := make([]byte, len(data)*4)
+	for i := range data {
+		binary.LittleEndian.PutUint32(b[i*4:], data[i])
+	}
+
+	var buf bytes.Buffer
+
+	switch compression {
+	case TMXCompressionNone:
+		buf.Write(b)
+	case TMXCompressionGzip:
+		gz, err := gzip.NewWriterLevel(&buf, flateLevel(level))
+		if err != nil {
+			return "", err
+		}
+		if _, err := gz.Write(b); err != nil {
+			return "", err
+		}
+		if err := gz.Close(); err != nil {
+			return "", err
+		}
+	case TMXCompressionZlib:
+		zw, err := zlib.NewWriterLevel(&buf, flateLevel(level))
+		if err != nil {
+			return "", err
+		}
+		if _, err := zw.Write(b); err != nil {
+			return "", err
+		}
+		if err := zw.Close(); err != nil {
+			return "", err
+		}
+	case TMXCompressionZstd:
+		buf.Write(zstdEncoder(level).EncodeAll(b, nil))
+	default:
+		return "", fmt.Errorf("unsupported layer data compression: %s", compression)
+	}
+
+	return "\n   " + base64.StdEncoding.EncodeToString(buf.Bytes()) + "\n  ", nil
+}
+
+// flateLevel returns the gzip or zlib level to write with, zero meaning the default level.
+func flateLevel(level int) int {
+	if level == 0 {
+		return flate.DefaultCompression
+	}
+	return level
+}
+
+// zstdDecoder returns the decoder shared by every zstd layer. DecodeAll is safe for concurrent use,
+// so chunks decoded in parallel share it too.
+var zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	return dec
+})
+
+var (
+	zstdEncoders   = make(map[int]*zstd.Encoder)
+	zstdEncodersMu sync.Mutex
+)
+
+// zstdEncoder returns the encoder shared by every zstd layer written at the level, zero meaning the
+// default level. EncodeAll is safe for concurrent use.
+func zstdEncoder(level int) *zstd.Encoder {
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+
+	enc, exists := zstdEncoders[level]
+	if !exists {
+		encLevel := zstd.SpeedDefault
+		if level != 0 {
+			encLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		enc, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(encLevel))
+		zstdEncoders[level] = enc
+	}
+	return enc
+}
+
+func parseCsvData(dataStr string) ([]uint32, error) {
+	var data []uint32
+	for _, s := range strings.Split(dataStr, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		tileIndex, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV layer data: %w", err)
+		}
+		data = append(data, uint32(tileIndex))
+	}
+	return data, nil
+}
+
+func encodeCsvData(data []uint32, width int) string {
+	var sb strings.Builder
+	sb.WriteByte('\n')
+	for i := range data {
+		sb.WriteString(strconv.FormatUint(uint64(data[i]), 10))
+		if i < len(data)-1 {
+			sb.WriteByte(',')
+			if width > 0 && (i+1)%width == 0 {
+				sb.WriteByte('\n')
+			}
+		}
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package tiled
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDataRoundTrip(t *testing.T) {
+	cells := []uint32{0, 1, 2, 3, 4 | TILE_FLIP_HORIZONTAL, 5, 0, 7 | TILE_FLIP_DIAGONAL, 8}
+
+	formats := []DataFormat{
+		DataFormatCSV,
+		DataFormatBase64,
+		DataFormatBase64Gzip,
+		DataFormatBase64Zlib,
+		DataFormatBase64Zstd,
+		{Encoding: TMXEncodingBase64, Compression: TMXCompressionGzip, Level: 9},
+		{Encoding: TMXEncodingBase64, Compression: TMXCompressionZstd, Level: 19},
+	}
+	for _, format := range formats {
+		if err := format.Validate(); err != nil {
+			t.Errorf("%s/%s: %v", format.Encoding, format.Compression, err)
+			continue
+		}
+		encoded, err := encodeData(cells, 3, format)
+		if err != nil {
+			t.Errorf("%s/%s: encode: %v", format.Encoding, format.Compression, err)
+			continue
+		}
+		decoded, err := DecodeData(encoded, format)
+		if err != nil {
+			t.Errorf("%s/%s: decode: %v", format.Encoding, format.Compression, err)
+			continue
+		}
+		if !slices.Equal(decoded, cells) {
+			t.Errorf("%s/%s: decoded %v, want %v", format.Encoding, format.Compression, decoded, cells)
+		}
+	}
+}
+
+func TestDataFormatLevels(t *testing.T) {
+	if err := (DataFormat{Encoding: TMXEncodingBase64, Compression: TMXCompressionGzip, Level: 10}).Validate(); err == nil {
+		t.Error("gzip level 10 was accepted")
+	}
+	if err := (DataFormat{Encoding: TMXEncodingBase64, Compression: TMXCompressionZstd, Level: 22}).Validate(); err != nil {
+		t.Errorf("zstd level 22 was rejected: %v", err)
+	}
+	if err := (DataFormat{Encoding: TMXEncodingBase64, Compression: TMXCompressionZstd, Level: 23}).Validate(); err == nil {
+		t.Error("zstd level 23 was accepted")
+	}
+}
+
+func TestDecodeInvalidData(t *testing.T) {
+	if _, err := DecodeData("not base64!", DataFormatBase64Zstd); err == nil {
+		t.Error("invalid base64 was decoded")
+	}
+	if _, err := DecodeData("AAAA", DataFormatBase64Zstd); err == nil {
+		t.Error("invalid zstd data was decoded")
+	}
+	if _, err := DecodeData("AQID", DataFormatBase64); err == nil {
+		t.Error("data that is not a whole number of cells was decoded")
+	}
+}
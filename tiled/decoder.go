@@ -0,0 +1,83 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// ======================================================
+// Parallel Layer Decoding
+// ======================================================
+
+// Predecode decodes the cell data of every tile layer of the map up front, spreading layers and chunks
+// over a pool of GOMAXPROCS workers, so the first draw only has to build tiles from decoded cells.
+// Blocks that are already decoded are skipped.
+func (tmx *TMX) Predecode() error {
+	defer metricsObserve(MetricDecodeTime, metricsStart())
+
+	type job struct {
+		layer *Layer
+		grids []*cellGrid
+		index int
+	}
+
+	var jobs []job
+	decoded := make(map[*Layer][]*cellGrid)
+	for _, layer := range tmx.Layers {
+		if layer.Data == nil || (layer.grids != nil && !slices.Contains(layer.grids, nil)) {
+			continue
+		}
+		grids := slices.Clone(layer.grids)
+		if grids == nil {
+			grids = make([]*cellGrid, layer.gridCount())
+		}
+		decoded[layer] = grids
+		for i := range grids {
+			if grids[i] == nil {
+				jobs = append(jobs, job{layer: layer, grids: grids, index: i})
+			}
+		}
+	}
+
+	queue := make(chan job)
+	var errs []error
+	var errMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for range min(runtime.GOMAXPROCS(0), len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				g, err := j.layer.decodeGrid(j.index)
+				if err != nil {
+					errMu.Lock()
+					errs = append(errs, fmt.Errorf("layer %s: %w", j.layer.Name(), err))
+					errMu.Unlock()
+					continue
+				}
+				j.grids[j.index] = g
+			}
+		}()
+	}
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for layer, grids := range decoded {
+		metricsCacheChanged(layer.grids, -1)
+		layer.grids = grids
+		metricsCacheChanged(grids, 1)
+		layer.trackGrids()
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Damage Shapes
+// ======================================================
+
+// DamageShape is a world-space area used to select tiles, such as an explosion radius or a projectile's hull.
+type DamageShape interface {
+	Bounds() geom.Rect64
+	IntersectsRect(rect geom.Rect64) bool
+}
+
+// Circle is a DamageShape covering every point within Radius of Center.
+type Circle struct {
+	Center geom.Point64
+	Radius float64
+}
+
+func (c Circle) Bounds() geom.Rect64 {
+	return geom.NewRect64(c.Center.X-c.Radius, c.Center.Y-c.Radius, c.Radius*2, c.Radius*2)
+}
+
+func (c Circle) IntersectsRect(rect geom.Rect64) bool {
+	dx := c.Center.X - math.Max(rect.X, math.Min(c.Center.X, rect.X+rect.Width))
+	dy := c.Center.Y - math.Max(rect.Y, math.Min(c.Center.Y, rect.Y+rect.Height))
+	return dx*dx+dy*dy < c.Radius*c.Radius
+}
+
+// Polygon is a DamageShape covering the inside of a closed polygon.
+type Polygon []geom.Point64
+
+func (p Polygon) Bounds() geom.Rect64 {
+	return pointBounds(p)
+}
+
+func (p Polygon) IntersectsRect(rect geom.Rect64) bool {
+	if len(p) == 0 {
+		return false
+	}
+
+	corners := []geom.Point64{
+		geom.NewPoint64(rect.X, rect.Y),
+		geom.NewPoint64(rect.X+rect.Width, rect.Y),
+		geom.NewPoint64(rect.X+rect.Width, rect.Y+rect.Height),
+		geom.NewPoint64(rect.X, rect.Y+rect.Height),
+	}
+
+	for _, pt := range p {
+		if pt.X > rect.X && pt.X < rect.X+rect.Width && pt.Y > rect.Y && pt.Y < rect.Y+rect.Height {
+			return true
+		}
+	}
+	for _, corner := range corners {
+		if p.contains(corner) {
+			return true
+		}
+	}
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		for j := range corners {
+			if segmentsCross(a, b, corners[j], corners[(j+1)%len(corners)]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contains reports whether the point is inside the polygon, using the even-odd rule.
+func (p Polygon) contains(pt geom.Point64) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func segmentsCross(a, b, c, d geom.Point64) bool {
+	cross := func(o, p, q geom.Point64) float64 {
+		return (p.X-o.X)*(q.Y-o.Y) - (p.Y-o.Y)*(q.X-o.X)
+	}
+	d1, d2 := cross(c, d, a), cross(c, d, b)
+	d3, d4 := cross(a, b, c), cross(a, b, d)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// ======================================================
+// Tile Destruction
+// ======================================================
+
+const (
+	// DestructibleProperty is the bool tileset tile property marking a tile as destructible.
+	DestructibleProperty = "destructible"
+
+	// DestroyedProperty is the int tileset tile property naming the local tile id a destroyed tile is replaced with.
+	// Tiles without it are cleared.
+	DestroyedProperty = "destroyed"
+)
+
+// DestroyRules controls which tiles DestroyTilesInShape affects and what they become.
+type DestroyRules struct {
+	// Destructible reports whether a tile can be destroyed.
+	// If nil, tiles are destructible when their DestructibleProperty is true.
+	Destructible func(key TileKey) bool
+
+	// Replacement returns the tile a destroyed tile becomes, or false to clear the cell.
+	// If nil, the tile's DestroyedProperty is used.
+	Replacement func(key TileKey) (TileKey, bool)
+}
+
+// DestroyResult describes the cells changed by DestroyTilesInShape.
+type DestroyResult struct {
+	// Cells lists every changed cell.
+	Cells []Cell
+
+	// Region is the smallest region, in tiles, containing every changed cell.
+	Region geom.Rect64
+}
+
+// DestroyTilesInShape clears or replaces the destructible tiles of a layer that intersect shape,
+// which is expressed in world space. All cells are changed together, so collision grids and draws
+// never observe a partial result; collision grids over the layer pick up the change on their next query.
+func DestroyTilesInShape(inst *MapInstance, layerName string, shape DamageShape, rules DestroyRules) (DestroyResult, error) {
+	tmx := inst.TMX
+
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		return DestroyResult{}, fmt.Errorf("layer not found: %s", layerName)
+	}
+
+	if rules.Destructible == nil {
+		rules.Destructible = func(key TileKey) bool {
+			prop := tileProperty(key, tmx.Tilesets, DestructibleProperty)
+			if prop == nil {
+				return false
+			}
+			destructible, err := prop.Bool()
+			return err == nil && destructible
+		}
+	}
+	if rules.Replacement == nil {
+		rules.Replacement = func(key TileKey) (TileKey, bool) {
+			prop := tileProperty(key, tmx.Tilesets, DestroyedProperty)
+			if prop == nil {
+				return TileKey{}, false
+			}
+			id, err := prop.Int()
+			if err != nil || id < 0 {
+				return TileKey{}, false
+			}
+			return TileKey{Source: key.Source, ID: uint32(id)}, true
+		}
+	}
+
+	cw, ch := float64(tmx.TileWidth()), float64(tmx.TileHeight())
+	bounds := shape.Bounds()
+
+	minX, minY := int(math.Floor(bounds.X/cw)), int(math.Floor(bounds.Y/ch))
+	maxX, maxY := int(math.Ceil((bounds.X+bounds.Width)/cw)), int(math.Ceil((bounds.Y+bounds.Height)/ch))
+
+	changes := make(map[Cell]uint32)
+	var result DestroyResult
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			if !shape.IntersectsRect(geom.NewRect64(float64(x)*cw, float64(y)*ch, cw, ch)) {
+				continue
+			}
+
+			data, err := layer.cellAt(x, y)
+			if err != nil {
+				return DestroyResult{}, err
+			}
+			key, ok := TileKeyOf(data, tmx.Tilesets)
+			if !ok || !rules.Destructible(key) {
+				continue
+			}
+
+			var replacement uint32
+			if to, ok := rules.Replacement(key); ok {
+				gid, ok := gidOf(to, tmx.Tilesets)
+				if !ok {
+					return DestroyResult{}, fmt.Errorf("tileset not used by map: %s", to.Source)
+				}
+				// Replacements keep the destroyed tile's orientation.
+				replacement = gid | data&^TILE_ID_MASK
+			}
+
+			cell := Cell{X: x, Y: y}
+			changes[cell] = replacement
+			result.Cells = append(result.Cells, cell)
+		}
+	}
+
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	if err := inst.writeCells(layer, changes); err != nil {
+		return DestroyResult{}, fmt.Errorf("failed to destroy tiles in layer %s: %w", layerName, err)
+	}
+
+	region := geom.NewRect64(float64(result.Cells[0].X), float64(result.Cells[0].Y), 1, 1)
+	for _, cell := range result.Cells[1:] {
+		region = region.Union(geom.NewRect64(float64(cell.X), float64(cell.Y), 1, 1))
+	}
+	result.Region = region
+
+	return result, nil
+}
+
+// tileProperty returns a property authored on a tileset tile, or nil if the tile or property doesn't exist.
+// The key resolves against the map's tilesets, if provided.
+func tileProperty(key TileKey, tilesets []*Tileset, name string) *Property {
+	tile := tilesetTile(key, tilesets)
+	if tile == nil {
+		return nil
+	}
+	return findProperty(tile.Properties, name)
+}
+
+// tilesetTile returns the tileset's definition of a tile, or nil if the tileset isn't loaded
+// or has no definition for the tile. The key resolves against the map's tilesets, if provided.
+func tilesetTile(key TileKey, tilesets []*Tileset) *TilesetTile {
+	tsx, _, err := resolveTileKey(key, tilesets)
+	if err != nil {
+		return nil
+	}
+	return tsx.Tile(key.ID)
+}
@@ -0,0 +1,28 @@
+package tiled
+
+import (
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+func TestDestroyTilesInShapeReadsTileProperties(t *testing.T) {
+	tmx := loadTestMap(t, "destroy/crates.tmx")
+	inst := NewMapInstance(tmx)
+
+	shape := Polygon{geom.NewPoint64(0, 0), geom.NewPoint64(64, 0), geom.NewPoint64(64, 16), geom.NewPoint64(0, 16)}
+	result, err := DestroyTilesInShape(inst, "crates", shape, DestroyRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Cells) != 2 {
+		t.Errorf("destroyed %d cells, want 2", len(result.Cells))
+	}
+
+	// Tile 0 turns into tile 1, tiles 1 and 2 are not destructible and tile 3 is cleared.
+	for x, want := range []uint32{2, 2, 3, 0} {
+		if gid, _ := inst.GetTileGID("crates", x, 0); gid != want {
+			t.Errorf("cell %d is %d, want %d", x, gid, want)
+		}
+	}
+}
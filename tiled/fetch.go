@@ -0,0 +1,95 @@
+package tiled
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ======================================================
+// HTTP Loading
+// ======================================================
+
+// httpFS is a read-only file system whose files are fetched over HTTP from below a base URL.
+type httpFS struct {
+	base   string
+	client *http.Client
+}
+
+// NewHTTPFS returns a file system whose files are fetched with GET requests from below the base URL,
+// so maps can be loaded with LoadTMX and LoadTMXWithImages over HTTP. A nil client uses
+// http.DefaultClient, which fetches with the browser's fetch API under wasm.
+func NewHTTPFS(base string, client *http.Client) fs.FS {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpFS{base: strings.TrimSuffix(base, "/"), client: client}
+}
+
+func (h *httpFS) Open(name string) (fs.File, error) {
+	data, err := h.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &httpFile{name: name, Reader: bytes.NewReader(data)}, nil
+}
+
+func (h *httpFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	u := h.base + "/" + (&url.URL{Path: name}).EscapedPath()
+	resp, err := h.client.Get(u)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case resp.StatusCode != http.StatusOK:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("unexpected status: %s", resp.Status)}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// httpFile is a fetched file, held in memory.
+type httpFile struct {
+	name string
+	*bytes.Reader
+}
+
+func (f *httpFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *httpFile) Close() error               { return nil }
+
+func (f *httpFile) Name() string       { return path.Base(f.name) }
+func (f *httpFile) Size() int64        { return f.Reader.Size() }
+func (f *httpFile) Mode() fs.FileMode  { return 0o444 }
+func (f *httpFile) ModTime() time.Time { return time.Time{} }
+func (f *httpFile) IsDir() bool        { return false }
+func (f *httpFile) Sys() any           { return nil }
+
+// FetchTMX loads a TMX map from the file system along with its tilesets, templates and images, like
+// LoadTMXWithImages, and calls done once it is loaded or failed. Loading happens in the background,
+// since files cannot be read synchronously from a file system returned by NewHTTPFS under wasm; done
+// is called on another goroutine, so hand the map over to the game loop rather than using it from done
+// directly. Maps fetched from the same file system share their tilesets and images; call ReleaseFS
+// with it once none of them are needed anymore.
+func FetchTMX(fsys fs.FS, name string, done func(*TMX, error)) {
+	go func() {
+		done(LoadTMXWithImages(fsys, name))
+	}()
+}
@@ -0,0 +1,194 @@
+package tiled
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// ======================================================
+// Fixture Summaries
+// ======================================================
+
+// MapSummary captures what parsing a map produced, in a form that can be stored next to a fixture
+// and compared against after upgrading Tiled or changing the parser.
+type MapSummary struct {
+	Orientation  Orientation          `json:"orientation"`
+	RenderOrder  RenderOrder          `json:"renderorder"`
+	Infinite     bool                 `json:"infinite"`
+	Width        int                  `json:"width"`
+	Height       int                  `json:"height"`
+	TileWidth    int                  `json:"tilewidth"`
+	TileHeight   int                  `json:"tileheight"`
+	Tilesets     []string             `json:"tilesets"`
+	Layers       []LayerSummary       `json:"layers"`
+	ImageLayers  []string             `json:"imagelayers,omitempty"`
+	ObjectGroups []ObjectGroupSummary `json:"objectgroups,omitempty"`
+}
+
+// LayerSummary captures a decoded tile layer. Checksum covers every cell, including flip flags,
+// in chunk order, so any change to the decoded data changes it.
+type LayerSummary struct {
+	Name        string      `json:"name"`
+	Encoding    Encoding    `json:"encoding"`
+	Compression Compression `json:"compression"`
+	Chunks      int         `json:"chunks"`
+	Cells       int         `json:"cells"`
+	Checksum    uint32      `json:"checksum"`
+}
+
+// ObjectGroupSummary captures an object group.
+type ObjectGroupSummary struct {
+	Name      string `json:"name"`
+	Objects   int    `json:"objects"`
+	Templated int    `json:"templated"`
+	Tiles     int    `json:"tiles"`
+}
+
+// SummarizeMap decodes every tile layer of the map and summarizes the result.
+func SummarizeMap(tmx *TMX) (*MapSummary, error) {
+	summary := &MapSummary{
+		Orientation: tmx.Orientation(),
+		RenderOrder: tmx.RenderOrder(),
+		Infinite:    tmx.IsInfinite(),
+		Width:       tmx.Width(),
+		Height:      tmx.Height(),
+		TileWidth:   tmx.TileWidth(),
+		TileHeight:  tmx.TileHeight(),
+		Tilesets:    []string{},
+		Layers:      []LayerSummary{},
+	}
+
+	for _, tileset := range tmx.Tilesets {
+		summary.Tilesets = append(summary.Tilesets, tileset.Source())
+	}
+
+	for _, layer := range tmx.Layers {
+		grids, err := layer.cellGrids()
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layer.Name(), err)
+		}
+
+		ls := LayerSummary{Name: layer.Name()}
+		if layer.Data != nil {
+			ls.Encoding = layer.Data.Encoding()
+			ls.Compression = layer.Data.Compression()
+			ls.Chunks = len(layer.Data.Chunks)
+		}
+
+		hash := fnv.New32a()
+		var buf [4]byte
+		for _, g := range grids {
+			for _, data := range g.data {
+				if data != 0 {
+					ls.Cells++
+				}
+				buf[0], buf[1], buf[2], buf[3] = byte(data), byte(data>>8), byte(data>>16), byte(data>>24)
+				hash.Write(buf[:])
+			}
+		}
+		ls.Checksum = hash.Sum32()
+
+		summary.Layers = append(summary.Layers, ls)
+	}
+
+	for _, layer := range tmx.ImageLayers {
+		summary.ImageLayers = append(summary.ImageLayers, layer.Name())
+	}
+
+	for _, group := range tmx.ObjectGroups {
+		gs := ObjectGroupSummary{Name: group.Name(), Objects: len(group.Objects)}
+		for _, obj := range group.Objects {
+			if obj.Template() != "" {
+				gs.Templated++
+			}
+			if obj.GID() != 0 {
+				gs.Tiles++
+			}
+		}
+		summary.ObjectGroups = append(summary.ObjectGroups, gs)
+	}
+
+	return summary, nil
+}
+
+// ======================================================
+// Fixture Checks
+// ======================================================
+
+// FixtureSummarySuffix is appended to a fixture map's path to name its expected summary.
+const FixtureSummarySuffix = ".summary.json"
+
+// FixtureResult reports the outcome of checking a single fixture map.
+type FixtureResult struct {
+	Path    string
+	Summary *MapSummary
+	Err     error
+}
+
+// CheckFixtures parses every TMX file under fsys and compares its summary against the
+// summary stored next to it. Maps without a stored summary only have to parse and decode.
+// With update set, stored summaries are not compared but returned so the caller can write them.
+func CheckFixtures(fsys fs.FS, update bool) ([]FixtureResult, error) {
+	var results []FixtureResult
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), "."+TMXAssetType) {
+			return nil
+		}
+		results = append(results, checkFixture(fsys, p, update))
+		return nil
+	})
+
+	return results, err
+}
+
+func checkFixture(fsys fs.FS, p string, update bool) FixtureResult {
+	result := FixtureResult{Path: p}
+
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	var tmx TMX
+	if err := xml.Unmarshal(data, &tmx); err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Summary, result.Err = SummarizeMap(&tmx)
+	if result.Err != nil || update {
+		return result
+	}
+
+	expected, err := fs.ReadFile(fsys, p+FixtureSummarySuffix)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			result.Err = err
+		}
+		return result
+	}
+
+	var want MapSummary
+	if err := json.Unmarshal(expected, &want); err != nil {
+		result.Err = fmt.Errorf("invalid summary: %w", err)
+		return result
+	}
+	if !reflect.DeepEqual(&want, result.Summary) {
+		got, _ := json.MarshalIndent(result.Summary, "", "  ")
+		result.Err = fmt.Errorf("summary mismatch, got:\n%s", got)
+	}
+
+	return result
+}
@@ -0,0 +1,133 @@
+package tiled
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata holds the fixture maps under fixtures/ and their renders under golden/, the tilesets,
+// images and template they share, and the maps written for individual tests.
+var testdata = os.DirFS("testdata")
+
+// loadTestMap loads a map under testdata together with its tilesets and templates.
+func loadTestMap(t *testing.T, name string) *TMX {
+	t.Helper()
+	t.Cleanup(func() { ReleaseFS(testdata) })
+
+	tmx, err := LoadTMX(testdata, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmx
+}
+
+// loadFixture loads a fixture map together with its tileset and template.
+func loadFixture(t *testing.T, name string) *TMX {
+	t.Helper()
+	return loadTestMap(t, "fixtures/"+name)
+}
+
+func TestFixtures(t *testing.T) {
+	fixtures, err := fs.Sub(testdata, "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := CheckFixtures(fixtures, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.Path, result.Err)
+			continue
+		}
+		if _, err := fs.Stat(fixtures, result.Path+FixtureSummarySuffix); err != nil {
+			t.Errorf("%s has no stored summary: %v", result.Path, err)
+		}
+	}
+}
+
+func TestFixtureEncodingsDecodeAlike(t *testing.T) {
+	fixtures, err := fs.Sub(testdata, "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := CheckFixtures(fixtures, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ortho fixtures hold the same level, each exported with a different layer format.
+	var want *MapSummary
+	formats := 0
+	for _, result := range results {
+		if !strings.HasPrefix(result.Path, "ortho_") {
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("%s: %v", result.Path, result.Err)
+		}
+		formats++
+		if want == nil {
+			want = result.Summary
+			continue
+		}
+		for i, layer := range result.Summary.Layers {
+			if layer.Cells != want.Layers[i].Cells || layer.Checksum != want.Layers[i].Checksum {
+				t.Errorf("%s: layer %s decodes differently from %s/%s", result.Path, layer.Name, want.Layers[i].Encoding, want.Layers[i].Compression)
+			}
+		}
+	}
+	if formats != 5 {
+		t.Errorf("%d ortho fixtures, want one per data format", formats)
+	}
+}
+
+func TestFixtureDecodesFlipFlags(t *testing.T) {
+	for _, name := range []string{"ortho_csv.tmx", "ortho_zstd.tmx"} {
+		tmx := loadFixture(t, name)
+
+		data, err := tmx.LayerByName("walls").cellAt(0, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data != 2|TILE_FLIP_HORIZONTAL {
+			t.Errorf("%s: cell 0,5 is %#x, want gid 2 flipped horizontally", name, data)
+		}
+	}
+
+	tmx := loadFixture(t, "infinite_gzip.tmx")
+	data, err := tmx.LayerByName("ground").cellAt(23, -11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != 2|TILE_FLIP_HORIZONTAL {
+		t.Errorf("infinite_gzip.tmx: cell 23,-11 is %#x, want gid 2 flipped horizontally", data)
+	}
+}
+
+func TestFixtureLoadsWangSetTileset(t *testing.T) {
+	tmx := loadFixture(t, "wang_corners.tmx")
+
+	tsx, err := tmx.Tilesets[0].TSX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tsx.Name() != "terrain" || tsx.TileCount() != 4 {
+		t.Errorf("loaded tileset %s with %d tiles, want terrain with 4", tsx.Name(), tsx.TileCount())
+	}
+
+	data, err := tmx.LayerByName("terrain").cellAt(3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, ok := TileKeyOf(data, tmx.Tilesets); !ok || key.ID != 2 || data&TILE_FLIP_HORIZONTAL == 0 {
+		t.Errorf("cell 3,3 is %#x, want terrain tile 2 flipped horizontally", data)
+	}
+}
@@ -0,0 +1,568 @@
+package tiled
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ======================================================
+// Cell Grid
+// ======================================================
+
+// Cell identifies a cell of a tile layer by its tile coordinates.
+type Cell struct {
+	X, Y int
+}
+
+// cellGrid holds the raw data of a rectangular block of cells: the whole layer for finite maps,
+// or a single chunk for infinite maps.
+type cellGrid struct {
+	x, y          int
+	width, height int
+	data          []uint32
+	used          int64 // Budget tick the block was last used in.
+}
+
+func (g *cellGrid) contains(x, y int) bool {
+	return x >= g.x && x < g.x+g.width && y >= g.y && y < g.y+g.height
+}
+
+func (g *cellGrid) index(x, y int) int {
+	return (y-g.y)*g.width + (x - g.x)
+}
+
+// cellGrids returns the layer's raw cell data, decoding every block that is not decoded yet.
+func (layer *Layer) cellGrids() ([]*cellGrid, error) {
+	if layer.Data == nil {
+		return layer.grids, nil
+	}
+	if layer.grids != nil && !slices.Contains(layer.grids, nil) {
+		return layer.grids, nil
+	}
+
+	defer metricsObserve(MetricDecodeTime, metricsStart())
+
+	for i := range layer.gridCount() {
+		if _, err := layer.grid(i); err != nil {
+			return nil, err
+		}
+	}
+	return layer.grids, nil
+}
+
+// grid returns the i-th block of the layer's raw cell data, decoding only that block on first use.
+// Blocks that are not decoded are nil in the layer's grids.
+func (layer *Layer) grid(i int) (*cellGrid, error) {
+	if layer.grids == nil {
+		layer.grids = make([]*cellGrid, layer.gridCount())
+	}
+	if g := layer.grids[i]; g != nil {
+		g.used = budgetTick.Load()
+		return g, nil
+	}
+
+	g, err := layer.decodeGrid(i)
+	if err != nil {
+		return nil, err
+	}
+	g.used = budgetTick.Load()
+	layer.grids[i] = g
+	metricsCacheChanged([]*cellGrid{g}, 1)
+	layer.trackGrids()
+	return g, nil
+}
+
+// dropGrid drops the decoded i-th block of cells, which is decoded again on next use.
+// What was built from it elsewhere, such as collision segments, goes with it.
+func (layer *Layer) dropGrid(i int) {
+	if i >= len(layer.grids) || layer.grids[i] == nil {
+		return
+	}
+	metricsCacheChanged(layer.grids[i:i+1], -1)
+	layer.grids[i] = nil
+	layer.gridDrops++
+}
+
+// gridCount returns how many blocks of cells the layer's data holds: one per chunk, or one for finite layers.
+func (layer *Layer) gridCount() int {
+	return max(len(layer.Data.Chunks), 1)
+}
+
+// decodeGrid decodes the i-th block of the layer's data: chunk i, or the whole layer for finite layers.
+func (layer *Layer) decodeGrid(i int) (*cellGrid, error) {
+	if len(layer.Data.Chunks) > 0 {
+		chunk := layer.Data.Chunks[i]
+		data, err := layer.Data.decode(chunk.Data)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != chunk.Width()*chunk.Height() {
+			return nil, fmt.Errorf("chunk at %d,%d has %d cells, expected %d", chunk.X(), chunk.Y(), len(data), chunk.Width()*chunk.Height())
+		}
+		return &cellGrid{x: chunk.X(), y: chunk.Y(), width: chunk.Width(), height: chunk.Height(), data: data}, nil
+	}
+
+	data, err := layer.Data.decode(layer.Data.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != layer.Width()*layer.Height() {
+		return nil, fmt.Errorf("layer has %d cells, expected %d", len(data), layer.Width()*layer.Height())
+	}
+	return &cellGrid{width: layer.Width(), height: layer.Height(), data: data}, nil
+}
+
+// gridData returns the raw cells of the i-th block of the layer's data, reusing what was already
+// decoded so drawing does not decode the same data twice.
+func (layer *Layer) gridData(i int) ([]uint32, error) {
+	if i < len(layer.grids) && layer.grids[i] != nil {
+		layer.grids[i].used = budgetTick.Load()
+		return layer.grids[i].data, nil
+	}
+	if len(layer.Data.Chunks) > 0 {
+		return layer.Data.decode(layer.Data.Chunks[i].Data)
+	}
+	return layer.Data.decode(layer.Data.Data)
+}
+
+// ChunkGIDs returns the raw data of the i-th block of the layer's cells, as ordered by GIDChunks, with
+// flip flags included. Cells that are already decoded are reused; others are decoded without being
+// kept, so drawing a chunk does not hold on to its cells. Like GIDs, the data must not be changed.
+func (layer *Layer) ChunkGIDs(i int) ([]uint32, error) {
+	if layer.Data == nil || i < 0 || i >= layer.gridCount() {
+		return nil, fmt.Errorf("layer %s has no block %d", layer.Name(), i)
+	}
+	return layer.gridData(i)
+}
+
+// ReleaseChunk drops the decoded cells of the i-th block of the layer, as ordered by GIDChunks, along
+// with what was built from them, such as collision segments. They are decoded again on next use.
+func (layer *Layer) ReleaseChunk(i int) {
+	layer.dropGrid(i)
+}
+
+// DecodedBytes returns how much memory the layer's decoded cells hold.
+func (layer *Layer) DecodedBytes() int64 {
+	var bytes int64
+	for _, g := range layer.grids {
+		if g != nil {
+			bytes += int64(cap(g.data)) * 4
+		}
+	}
+	return bytes
+}
+
+// gridBounds returns the smallest block of cells, as origin and size, containing every grid.
+// The grids must all be decoded.
+func gridBounds(grids []*cellGrid) (x, y, width, height int) {
+	if len(grids) == 0 {
+		return 0, 0, 0, 0
+	}
+	minX, minY := grids[0].x, grids[0].y
+	maxX, maxY := grids[0].x+grids[0].width, grids[0].y+grids[0].height
+	for _, g := range grids[1:] {
+		minX, minY = min(minX, g.x), min(minY, g.y)
+		maxX, maxY = max(maxX, g.x+g.width), max(maxY, g.y+g.height)
+	}
+	return minX, minY, maxX - minX, maxY - minY
+}
+
+// gridAt returns the decoded block of cells containing the cell, or nil if the cell is outside the layer.
+// Only that block is decoded.
+func (layer *Layer) gridAt(x, y int) (*cellGrid, error) {
+	i := layer.gridIndex(x, y)
+	if i < 0 {
+		return nil, nil
+	}
+	return layer.grid(i)
+}
+
+// gridIndex returns the index of the block of cells containing the cell, found from the layer's
+// chunk bounds without decoding anything, or -1 if the cell is outside the layer.
+func (layer *Layer) gridIndex(x, y int) int {
+	if layer.Data == nil {
+		return -1
+	}
+	if len(layer.Data.Chunks) == 0 {
+		if x >= 0 && x < layer.Width() && y >= 0 && y < layer.Height() {
+			return 0
+		}
+		return -1
+	}
+	for i, chunk := range layer.Data.Chunks {
+		if x >= chunk.X() && x < chunk.X()+chunk.Width() && y >= chunk.Y() && y < chunk.Y()+chunk.Height() {
+			return i
+		}
+	}
+	return -1
+}
+
+// cellAt returns the raw data of a cell, including flip flags.
+// Cells outside the layer, or outside every chunk of an infinite layer, are empty.
+func (layer *Layer) cellAt(x, y int) (uint32, error) {
+	g, err := layer.gridAt(x, y)
+	if err != nil || g == nil {
+		return 0, err
+	}
+	return g.data[g.index(x, y)], nil
+}
+
+// invalidate drops everything decoded from the layer's data so it is rebuilt on next use.
+func (layer *Layer) invalidate() {
+	metricsCacheChanged(layer.grids, -1)
+	layer.untrackGrids()
+
+	layer.grids = nil
+	layer.gridDrops++
+	layer.generation++
+
+	notifyObservers(func(o Observer) { o.LayerChanged(layer, nil) })
+}
+
+// Generation returns a number that changes whenever the layer's cells change, so work started from
+// its cells, such as decoding in the background, can tell it is out of date.
+func (layer *Layer) Generation() int {
+	return layer.generation
+}
+
+// setCells writes raw cell data, including flip flags, and re-encodes the affected blocks in the
+// layer's current format. Cells outside the layer, or outside every chunk of an infinite layer,
+// are ignored. Either every cell is written or, on error, none are.
+func (layer *Layer) setCells(cells map[Cell]uint32) error {
+	// Only the blocks holding the cells are decoded.
+	grids := make(map[int]*cellGrid)
+	updated := make(map[int][]uint32)
+	for cell, data := range cells {
+		i := layer.gridIndex(cell.X, cell.Y)
+		if i < 0 {
+			continue
+		}
+		g, err := layer.grid(i)
+		if err != nil {
+			return err
+		}
+		grids[i] = g
+
+		cellData, exists := updated[i]
+		if !exists {
+			cellData = append([]uint32(nil), g.data...)
+			updated[i] = cellData
+		}
+		cellData[g.index(cell.X, cell.Y)] = data
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+
+	format := layer.Data.Format()
+
+	encoded := make(map[int]string, len(updated))
+	for i, cellData := range updated {
+		raw, err := encodeData(cellData, grids[i].width, format)
+		if err != nil {
+			return err
+		}
+		encoded[i] = raw
+	}
+
+	for i, cellData := range updated {
+		grids[i].data = cellData
+		if len(layer.Data.Chunks) > 0 {
+			layer.Data.Chunks[i].Data = encoded[i]
+		} else {
+			layer.Data.Data = encoded[i]
+		}
+	}
+
+	// The decoded grids stay valid; only what was built from them is dropped.
+	layer.generation++
+
+	changed := make([]Cell, 0, len(cells))
+	for cell := range cells {
+		changed = append(changed, cell)
+	}
+	notifyObservers(func(o Observer) { o.LayerChanged(layer, changed) })
+
+	return nil
+}
+
+// GetTileGID returns the raw data of a cell at the given tile coordinates: its GID with flip flags.
+// Cells outside the layer, or outside every chunk of an infinite layer, are empty.
+func (layer *Layer) GetTileGID(x, y int) (uint32, error) {
+	return layer.cellAt(x, y)
+}
+
+// SetTileGID changes a cell to the GID, which may include flip flags, and drops the tiles decoded from
+// the layer so the change is drawn next frame. Setting a cell outside every chunk of an infinite layer
+// adds an empty chunk around it, sized and aligned like the layer's other chunks; cells outside a finite
+// layer are an error.
+func (layer *Layer) SetTileGID(x, y int, gid uint32) error {
+	if err := layer.ensureCell(x, y); err != nil {
+		return err
+	}
+	return layer.setCells(map[Cell]uint32{{X: x, Y: y}: gid})
+}
+
+// GIDs returns the raw data of every cell of a finite layer, row by row, with flip flags included.
+// The layer is Width by Height cells. The slice is the layer's own decoded data and must not be changed;
+// use SetTileGID instead. Infinite layers have no single grid of cells, so use GIDChunks for them.
+func (layer *Layer) GIDs() ([]uint32, error) {
+	if layer.Data != nil && len(layer.Data.Chunks) > 0 {
+		return nil, fmt.Errorf("layer %s is infinite, its cells are split into chunks", layer.Name())
+	}
+	grids, err := layer.cellGrids()
+	if err != nil || len(grids) == 0 {
+		return nil, err
+	}
+	return grids[0].data, nil
+}
+
+// GIDChunk is a block of raw cell data: a chunk of an infinite layer, or the whole of a finite one.
+type GIDChunk struct {
+	X, Y          int
+	Width, Height int
+
+	// GIDs holds the raw data of the chunk's cells, row by row, with flip flags included.
+	GIDs []uint32
+}
+
+// GIDChunks returns the raw data of the layer's cells block by block: one per chunk of an infinite
+// layer, or a single block for a finite one. Like GIDs, the data must not be changed.
+func (layer *Layer) GIDChunks() ([]GIDChunk, error) {
+	grids, err := layer.cellGrids()
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]GIDChunk, len(grids))
+	for i, g := range grids {
+		chunks[i] = GIDChunk{X: g.x, Y: g.y, Width: g.width, Height: g.height, GIDs: g.data}
+	}
+	return chunks, nil
+}
+
+// FloodFill changes the cell at the given tile coordinates, and every cell connected to it by edges
+// holding the same GID, to the GID, which may include flip flags. Flip flags are ignored when matching.
+// The fill stays within the layer, or within the existing chunks of an infinite layer.
+func (layer *Layer) FloodFill(x, y int, gid uint32) error {
+	cells, err := layer.floodCells(x, y, gid)
+	if err != nil || len(cells) == 0 {
+		return err
+	}
+	return layer.setCells(cells)
+}
+
+// floodCells returns the cells a flood fill from the cell would change, mapped to the GID.
+func (layer *Layer) floodCells(x, y int, gid uint32) (map[Cell]uint32, error) {
+	start, err := layer.gridAt(x, y)
+	if err != nil || start == nil {
+		return nil, err
+	}
+
+	target := start.data[start.index(x, y)] & TILE_ID_MASK
+	if start.data[start.index(x, y)] == gid {
+		return nil, nil
+	}
+
+	cells := make(map[Cell]uint32)
+	stack := []Cell{{X: x, Y: y}}
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if _, seen := cells[cell]; seen {
+			continue
+		}
+		g, err := layer.gridAt(cell.X, cell.Y)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil || g.data[g.index(cell.X, cell.Y)]&TILE_ID_MASK != target {
+			continue
+		}
+
+		cells[cell] = gid
+		stack = append(stack,
+			Cell{X: cell.X + 1, Y: cell.Y},
+			Cell{X: cell.X - 1, Y: cell.Y},
+			Cell{X: cell.X, Y: cell.Y + 1},
+			Cell{X: cell.X, Y: cell.Y - 1},
+		)
+	}
+
+	return cells, nil
+}
+
+// ensureCell makes sure a block of decoded cells contains the cell, adding a chunk to infinite layers.
+func (layer *Layer) ensureCell(x, y int) error {
+	if layer.Data == nil {
+		return fmt.Errorf("layer %s has no data", layer.Name())
+	}
+
+	g, err := layer.gridAt(x, y)
+	if err != nil || g != nil {
+		return err
+	}
+	if len(layer.Data.Chunks) == 0 {
+		return fmt.Errorf("cell %d,%d is outside layer %s", x, y, layer.Name())
+	}
+
+	// New chunks follow the layer's first chunk, so the layer keeps a single chunk size and grid.
+	first := layer.Data.Chunks[0]
+	width, height := first.Width(), first.Height()
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("layer %s has a %dx%d chunk", layer.Name(), width, height)
+	}
+
+	g = &cellGrid{
+		x:      first.X() + floorDiv(x-first.X(), width)*width,
+		y:      first.Y() + floorDiv(y-first.Y(), height)*height,
+		width:  width,
+		height: height,
+		data:   make([]uint32, width*height),
+	}
+
+	raw, err := encodeData(g.data, width, layer.Data.Format())
+	if err != nil {
+		return err
+	}
+
+	layer.Data.Chunks = append(layer.Data.Chunks, &DataChunk{
+		Attrs: TiledXMLAttrTable{
+			XAttr:      AttrInt(g.x),
+			YAttr:      AttrInt(g.y),
+			WidthAttr:  AttrInt(width),
+			HeightAttr: AttrInt(height),
+		},
+		Data: raw,
+	})
+	// Chunk i is decoded into grid i, so the new grid goes last as well.
+	if layer.grids == nil {
+		layer.grids = make([]*cellGrid, len(layer.Data.Chunks)-1)
+	}
+	layer.grids = append(layer.grids, g)
+	metricsCacheChanged([]*cellGrid{g}, 1)
+	layer.trackGrids()
+
+	return nil
+}
+
+// ======================================================
+// Memory Budget
+// ======================================================
+
+// While Config.MemoryBudget is set, the layers holding decoded blocks of cells are tracked so the
+// least recently used blocks, across every layer, can be dropped once the cache is over budget.
+// Blocks remember the budget tick they were last used in; the tick advances after each layer drawn.
+var (
+	budgetMu     sync.Mutex
+	budgetLayers = make(map[*Layer]struct{})
+	budgetTick   atomic.Int64
+)
+
+// trackGrids registers the layer as holding decoded blocks when a memory budget is set.
+func (layer *Layer) trackGrids() {
+	if CurrentConfig().MemoryBudget <= 0 {
+		return
+	}
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+	budgetLayers[layer] = struct{}{}
+}
+
+// untrackGrids forgets the layer once it no longer holds decoded blocks.
+func (layer *Layer) untrackGrids() {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+	delete(budgetLayers, layer)
+}
+
+// AdvanceCacheTick marks the end of a use of decoded cells, such as drawing a layer. Cells dropped
+// for the memory budget are the ones used the most ticks ago.
+func AdvanceCacheTick() {
+	budgetTick.Add(1)
+}
+
+// EnforceMemoryBudget drops the least recently used decoded cells, across every layer, while they hold
+// more than Config.MemoryBudget. It does nothing without a budget.
+func EnforceMemoryBudget() {
+	if budget := CurrentConfig().MemoryBudget; budget > 0 && cacheBytes.Load() > budget {
+		enforceMemoryBudget(budget)
+	}
+}
+
+// enforceMemoryBudget drops the least recently used decoded blocks of cells until the cache is within
+// the budget. Only raw cells are dropped; tiles and static buffers built from them are kept, and
+// blocks are decoded again when next needed.
+func enforceMemoryBudget(budget int64) {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+
+	type gridRef struct {
+		layer *Layer
+		index int
+		used  int64
+	}
+	var refs []gridRef
+	for layer := range budgetLayers {
+		held := false
+		for i, g := range layer.grids {
+			if g != nil {
+				refs = append(refs, gridRef{layer: layer, index: i, used: g.used})
+				held = true
+			}
+		}
+		if !held {
+			delete(budgetLayers, layer)
+		}
+	}
+	slices.SortFunc(refs, func(a, b gridRef) int {
+		return cmp.Compare(a.used, b.used)
+	})
+
+	for _, ref := range refs {
+		if cacheBytes.Load() <= budget {
+			return
+		}
+		ref.layer.dropGrid(ref.index)
+	}
+}
+
+// forEachCell invokes fn with the cell coordinates and raw data of every cell in the layer,
+// including chunks of infinite layers.
+func forEachCell(layer *Layer, fn func(x, y int, data uint32)) error {
+	if layer.Data == nil {
+		return nil
+	}
+
+	if len(layer.Data.Chunks) > 0 {
+		for _, chunk := range layer.Data.Chunks {
+			if chunk.Width() <= 0 {
+				return fmt.Errorf("invalid chunk width: %d", chunk.Width())
+			}
+			parsedData, err := layer.Data.decode(chunk.Data)
+			if err != nil {
+				return err
+			}
+			for i := range parsedData {
+				fn(chunk.X()+i%chunk.Width(), chunk.Y()+i/chunk.Width(), parsedData[i])
+			}
+		}
+		return nil
+	}
+
+	if layer.Width() <= 0 {
+		return fmt.Errorf("invalid layer width: %d", layer.Width())
+	}
+
+	parsedData, err := layer.Data.decode(layer.Data.Data)
+	if err != nil {
+		return err
+	}
+	for i := range parsedData {
+		fn(i%layer.Width(), i/layer.Width(), parsedData[i])
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package tiled
+
+import "testing"
+
+func TestSetTileGIDAddsChunkLikeTheLayers(t *testing.T) {
+	tmx := loadFixture(t, "infinite_csv_chunk32.tmx")
+	layer := tmx.LayerByName("ground")
+
+	for _, cell := range []Cell{{X: 40, Y: 5}, {X: -3, Y: -40}} {
+		if err := layer.SetTileGID(cell.X, cell.Y, 2); err != nil {
+			t.Fatal(err)
+		}
+		if gid, _ := layer.GetTileGID(cell.X, cell.Y); gid != 2 {
+			t.Errorf("cell %v reads back %d, want 2", cell, gid)
+		}
+	}
+
+	want := []Cell{{X: 0, Y: 0}, {X: 32, Y: 0}, {X: -32, Y: -64}}
+	if len(layer.Data.Chunks) != len(want) {
+		t.Fatalf("layer has %d chunks, want %d", len(layer.Data.Chunks), len(want))
+	}
+	for i, chunk := range layer.Data.Chunks {
+		if chunk.X() != want[i].X || chunk.Y() != want[i].Y || chunk.Width() != 32 || chunk.Height() != 32 {
+			t.Errorf("chunk %d is %dx%d at %d,%d, want 32x32 at %d,%d", i, chunk.Width(), chunk.Height(), chunk.X(), chunk.Y(), want[i].X, want[i].Y)
+		}
+	}
+
+	for _, issue := range tmx.Validate().Issues {
+		t.Errorf("edited map does not validate: %s", issue.Message)
+	}
+}
@@ -0,0 +1,99 @@
+package tiled
+
+import "github.com/adm87/finch-core/geom"
+
+// ======================================================
+// Hex Coordinates
+// ======================================================
+
+// Axial is a cell of a hexagonal map in axial coordinates, where every neighbour is a fixed step
+// away and distances reduce to simple arithmetic. Tile layers store cells in offset coordinates;
+// the TMX converts between the two using the map's stagger axis and index.
+type Axial struct {
+	Q, R int
+}
+
+// axialDirections are the steps to the six neighbours of an axial cell.
+var axialDirections = [6]Axial{
+	{Q: 1, R: 0}, {Q: 1, R: -1}, {Q: 0, R: -1},
+	{Q: -1, R: 0}, {Q: -1, R: 1}, {Q: 0, R: 1},
+}
+
+func (a Axial) Add(b Axial) Axial {
+	return Axial{Q: a.Q + b.Q, R: a.R + b.R}
+}
+
+// Distance returns the number of steps between two axial cells.
+func (a Axial) Distance(b Axial) int {
+	dq, dr := a.Q-b.Q, a.R-b.R
+	return (abs(dq) + abs(dq+dr) + abs(dr)) / 2
+}
+
+// Neighbors returns the six cells adjacent to an axial cell.
+func (a Axial) Neighbors() [6]Axial {
+	var out [6]Axial
+	for i, dir := range axialDirections {
+		out[i] = a.Add(dir)
+	}
+	return out
+}
+
+// OffsetToAxial converts a cell of the map's tile layers to axial coordinates.
+func (tmx TMX) OffsetToAxial(cell Cell) Axial {
+	l := tmx.stagger()
+
+	if l.staggerX {
+		return Axial{Q: cell.X, R: cell.Y - l.staggerShift(cell.X)}
+	}
+	return Axial{Q: cell.X - l.staggerShift(cell.Y), R: cell.Y}
+}
+
+// AxialToOffset converts axial coordinates to a cell of the map's tile layers.
+func (tmx TMX) AxialToOffset(a Axial) Cell {
+	l := tmx.stagger()
+
+	if l.staggerX {
+		return Cell{X: a.Q, Y: a.R + l.staggerShift(a.Q)}
+	}
+	return Cell{X: a.Q + l.staggerShift(a.R), Y: a.R}
+}
+
+// staggerShift returns how many cells a row or column has been pushed along the other axis by the
+// rows or columns shifted before it.
+func (l staggerLayout) staggerShift(n int) int {
+	if l.staggerEven {
+		return (n + n&1) / 2
+	}
+	return (n - n&1) / 2
+}
+
+// AxialToWorld returns the top-left corner of the bounding box of an axial cell in world space.
+func (tmx TMX) AxialToWorld(a Axial) geom.Point64 {
+	return tmx.TileToWorld(tmx.AxialToOffset(a))
+}
+
+// WorldToAxial returns the axial cell under a world position.
+func (tmx TMX) WorldToAxial(worldX, worldY float64) Axial {
+	return tmx.OffsetToAxial(tmx.WorldToTile(worldX, worldY))
+}
+
+// HexDistance returns the number of steps between two cells of the map's tile layers.
+func (tmx TMX) HexDistance(a, b Cell) int {
+	return tmx.OffsetToAxial(a).Distance(tmx.OffsetToAxial(b))
+}
+
+// HexNeighbors returns the six cells adjacent to a cell of the map's tile layers.
+func (tmx TMX) HexNeighbors(cell Cell) [6]Cell {
+	var out [6]Cell
+	for i, n := range tmx.OffsetToAxial(cell).Neighbors() {
+		out[i] = tmx.AxialToOffset(n)
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package tiled
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func TestHexOffsetToAxial(t *testing.T) {
+	for _, name := range []string{"hex_odd_y.tmx", "staggered_even_x.tmx"} {
+		tmx := loadFixture(t, name)
+
+		for y := -2; y < 6; y++ {
+			for x := -2; x < 6; x++ {
+				cell := Cell{X: x, Y: y}
+				if got := tmx.AxialToOffset(tmx.OffsetToAxial(cell)); got != cell {
+					t.Errorf("%s: cell %v converts back to %v", name, cell, got)
+				}
+				for _, n := range tmx.HexNeighbors(cell) {
+					if d := tmx.HexDistance(cell, n); d != 1 {
+						t.Errorf("%s: neighbour %v of %v is %d steps away", name, n, cell, d)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHexNeighbors(t *testing.T) {
+	tmx := loadFixture(t, "hex_odd_y.tmx")
+
+	// Odd rows are shifted right, so cell 1,1 touches columns 1 and 2 of the rows above and below.
+	got := tmx.HexNeighbors(Cell{X: 1, Y: 1})
+	want := []Cell{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 2}, {X: 2, Y: 0}, {X: 2, Y: 1}, {X: 2, Y: 2}}
+	slices.SortFunc(got[:], func(a, b Cell) int { return cmp.Or(cmp.Compare(a.X, b.X), cmp.Compare(a.Y, b.Y)) })
+	if !slices.Equal(got[:], want) {
+		t.Errorf("neighbours of 1,1 are %v, want %v", got, want)
+	}
+
+	if d := tmx.HexDistance(Cell{X: 0, Y: 0}, Cell{X: 3, Y: 3}); d != 5 {
+		t.Errorf("distance from 0,0 to 3,3 is %d, want 5", d)
+	}
+}
+
+func TestStaggeredWorldToTile(t *testing.T) {
+	for _, name := range []string{"hex_odd_y.tmx", "staggered_even_x.tmx"} {
+		tmx := loadFixture(t, name)
+		tw, th := float64(tmx.TileWidth()), float64(tmx.TileHeight())
+
+		for y := -1; y < 5; y++ {
+			for x := -1; x < 5; x++ {
+				cell := Cell{X: x, Y: y}
+				p := tmx.TileToWorld(cell)
+				if got := tmx.WorldToTile(p.X+tw/2, p.Y+th/2); got != cell {
+					t.Errorf("%s: centre of cell %v at %v,%v maps to %v", name, cell, p.X+tw/2, p.Y+th/2, got)
+				}
+			}
+		}
+	}
+}
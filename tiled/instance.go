@@ -0,0 +1,178 @@
+package tiled
+
+import (
+	"image"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ======================================================
+// Map Instance
+// ======================================================
+
+// MapInstance is a runtime instance of a loaded map.
+// Loaded maps are shared through the asset cache, so state that should only affect one
+// use of a map, such as tile overrides, lives on the instance instead of the TMX. The instance's
+// TMX has its own copy of the map's tile layers: tile edits made through the instance, such as
+// SetTileGID, DestroyTilesInShape, Rewind or a MutationQueue, change only that copy and are drawn
+// and collided against only for this instance. Tilesets, object groups and image layers are shared.
+type MapInstance struct {
+	TMX *TMX
+
+	overrides  TileOverrides
+	visibility map[string]bool
+
+	timeline      *Timeline
+	eventHandlers map[string]EventHandler
+
+	clock  time.Duration
+	rewind *rewindBuffer
+
+	edits    map[string]map[Cell]uint32
+	recorder *ReplayRecorder
+}
+
+// NewMapInstance creates a runtime instance of the provided map, copying its tile layers.
+// Collision grids and other queries for the instance should be built from the instance's TMX.
+func NewMapInstance(tmx *TMX) *MapInstance {
+	return &MapInstance{
+		TMX: tmx.instanceCopy(),
+	}
+}
+
+// instanceCopy returns a copy of the map with its own tile layers, their data included and nothing
+// decoded yet. Everything else is shared with the map. Observers are told about each copied layer,
+// so package render can carry its draw settings over.
+func (tmx *TMX) instanceCopy() *TMX {
+	cp := *tmx
+	layers := make(map[*Layer]*Layer, len(tmx.Layers))
+	cp.Layers = make([]*Layer, len(tmx.Layers))
+	for i, layer := range tmx.Layers {
+		cp.Layers[i] = layer.instanceCopy()
+		layers[layer] = cp.Layers[i]
+		notifyObservers(func(o Observer) { o.LayerCopied(layer, cp.Layers[i]) })
+	}
+	cp.order = slices.Clone(tmx.order)
+	for i, entry := range cp.order {
+		if layer, ok := entry.(*Layer); ok {
+			cp.order[i] = layers[layer]
+		}
+	}
+	return &cp
+}
+
+// instanceCopy returns a copy of the layer's attributes and data.
+func (layer *Layer) instanceCopy() *Layer {
+	cp := &Layer{
+		Attrs:      maps.Clone(layer.Attrs),
+		Properties: layer.Properties,
+	}
+	if layer.Data != nil {
+		cp.Data = layer.Data.clone()
+	}
+	return cp
+}
+
+// SetLayerVisible shows or hides a layer for this instance only, overriding the layer's authored visibility.
+func (inst *MapInstance) SetLayerVisible(layerName string, visible bool) {
+	if inst.visibility == nil {
+		inst.visibility = make(map[string]bool)
+	}
+	inst.visibility[layerName] = visible
+}
+
+// IsLayerVisible reports whether a layer is drawn for this instance.
+func (inst *MapInstance) IsLayerVisible(layerName string) bool {
+	if layer := inst.TMX.LayerByName(layerName); layer != nil {
+		return inst.layerVisible(layerName, layer.IsVisible())
+	}
+	if layer := inst.TMX.ImageLayerByName(layerName); layer != nil {
+		return inst.layerVisible(layerName, layer.IsVisible())
+	}
+	if og := inst.TMX.ObjectGroupByName(layerName); og != nil {
+		return inst.layerVisible(layerName, og.IsVisible())
+	}
+	return false
+}
+
+// layerVisible resolves a layer's visibility, falling back to the authored visibility
+// when the instance is nil or doesn't override it.
+func (inst *MapInstance) layerVisible(layerName string, authored bool) bool {
+	if inst == nil {
+		return authored
+	}
+	if visible, exists := inst.visibility[layerName]; exists {
+		return visible
+	}
+	return authored
+}
+
+// ======================================================
+// Tile Overrides
+// ======================================================
+
+// TileOverrides maps authored tiles to the tiles drawn in their place.
+// Because tiles are identified by tileset source, the same table can be used with every map of a project.
+type TileOverrides map[TileKey]TileKey
+
+// SetTileOverride draws the to tile wherever the from tile is authored.
+func (inst *MapInstance) SetTileOverride(from, to TileKey) {
+	if inst.overrides == nil {
+		inst.overrides = make(TileOverrides)
+	}
+	inst.overrides[from] = to
+}
+
+// SetTileOverrides replaces the instance's override table, e.g. when switching seasons or biomes.
+func (inst *MapInstance) SetTileOverrides(overrides TileOverrides) {
+	inst.overrides = overrides
+}
+
+// TileOverrides returns the instance's override table.
+func (inst *MapInstance) TileOverrides() TileOverrides {
+	return inst.overrides
+}
+
+// ClearTileOverrides removes every tile override from the instance.
+func (inst *MapInstance) ClearTileOverrides() {
+	inst.overrides = nil
+}
+
+// TileKey returns the key of the tile referenced by a GID of the instance's map.
+func (inst *MapInstance) TileKey(gid uint32) (TileKey, bool) {
+	return TileKeyOf(gid, inst.TMX.Tilesets)
+}
+
+// Apply returns the tile to draw in place of the provided tile.
+// The replacement keeps the original's cell anchoring and flags while adopting the size
+// and offset of the replacement tileset, which resolves against the map's tilesets.
+func (o TileOverrides) Apply(tile *Tile, tilesets []*Tileset) (Tile, error) {
+	to, exists := o[TileKey{Source: tile.TsxSrc, ID: tile.GID}]
+	if !exists {
+		return *tile, nil
+	}
+
+	from, err := tile.TSX()
+	if err != nil {
+		return *tile, err
+	}
+	tsx, files, err := resolveTileKey(to, tilesets)
+	if err != nil {
+		return *tile, err
+	}
+
+	width, height := tsx.TileSize(to.ID)
+
+	replaced := *tile
+	replaced.GID = to.ID
+	replaced.TsxSrc = to.Source
+	replaced.files = files
+	replaced.src = image.Rectangle{}
+	replaced.Width = float64(width)
+	replaced.Height = float64(height)
+	replaced.X += float64(tsx.TileOffsetX() - from.TileOffsetX())
+	replaced.Y += float64(tsx.TileOffsetY()-from.TileOffsetY()) + tile.Height - float64(height)
+
+	return replaced, nil
+}
@@ -0,0 +1,40 @@
+package tiled
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMapInstanceEditsStayOnTheInstance(t *testing.T) {
+	tmx, err := ParseTMX(strings.NewReader(cropTestMap), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := NewMapInstance(tmx), NewMapInstance(tmx)
+
+	if err := a.SetTileGID("ground", 1, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.FloodFill("ground", 6, 6, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if gid, _ := a.GetTileGID("ground", 1, 1); gid != 2 {
+		t.Errorf("instance reads back %d, want 2", gid)
+	}
+	if gid, _ := b.GetTileGID("ground", 1, 1); gid != 0 {
+		t.Errorf("edit leaked into another instance: %d", gid)
+	}
+	if gid, _ := tmx.LayerByName("ground").GetTileGID(6, 6); gid != 0 {
+		t.Errorf("edit leaked into the shared map: %d", gid)
+	}
+	if gid, _ := tmx.LayerByName("ground").GetTileGID(3, 4); gid != 3 {
+		t.Errorf("shared map lost its tile: %d", gid)
+	}
+
+	for _, entry := range a.TMX.OrderedLayers() {
+		if layer, ok := entry.(*Layer); ok && layer != a.TMX.LayerByName(layer.Name()) {
+			t.Errorf("layer %s of the instance draws the shared map's copy", layer.Name())
+		}
+	}
+}
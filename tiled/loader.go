@@ -0,0 +1,617 @@
+package tiled
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+	"sync"
+)
+
+// Asset types of Tiled's file formats, which are also their file extensions.
+const (
+	TMXAssetType = "tmx"
+	TSXAssetType = "tsx"
+	TXAssetType  = "tx"
+)
+
+// ======================================================
+// Resolver
+// ======================================================
+
+// Resolver finds the tilesets, templates and images referenced by files that were not loaded from a
+// file system with LoadTMX, such as the finch assets loaded by package render. Paths are resolved
+// like tileset sources: relative to the root the referencing file was loaded from.
+type Resolver interface {
+	TSX(path string) (*TSX, error)
+	TX(path string) (*TX, error)
+	Image(path string) (image.Image, error)
+}
+
+var (
+	resolver      Resolver
+	resolverMutex sync.RWMutex
+)
+
+// SetResolver routes the lookups of files not loaded with LoadTMX to r. Passing nil leaves them
+// unresolved.
+func SetResolver(r Resolver) {
+	resolverMutex.Lock()
+	defer resolverMutex.Unlock()
+	resolver = r
+}
+
+func currentResolver() Resolver {
+	resolverMutex.RLock()
+	defer resolverMutex.RUnlock()
+	return resolver
+}
+
+// ======================================================
+// Standalone Loading
+// ======================================================
+
+// PathResolver maps a source path, as written in a Tiled file, to the path it is loaded from.
+// Tiled writes sources relative to the file they appear in.
+type PathResolver func(source string) string
+
+// RelativeTo returns a resolver for sources written in the file at the path, resolving them like
+// the finch asset importers of package render do.
+func RelativeTo(path string) PathResolver {
+	return func(source string) string {
+		return resolveSourcePath(path, source)
+	}
+}
+
+func resolveSourcePath(basePath, source string) string {
+	resolvedPath := path.Join(path.Dir(basePath), source)
+	resolvedPath = path.Clean(resolvedPath)
+	return resolvedPath
+}
+
+// resolveFileProperties rewrites the paths held by file properties, which Tiled writes relative
+// to the file they appear in, so they resolve like tileset sources do.
+func resolveFileProperties(resolve PathResolver, props []*Property) {
+	for _, prop := range props {
+		if prop.Type() == FilePropertyType && prop.Value() != "" {
+			prop.Attrs[ValueAttr] = AttrString(resolve.apply(prop.Value()))
+		}
+		resolveFileProperties(resolve, prop.Properties)
+	}
+}
+
+func (resolve PathResolver) apply(source string) string {
+	if resolve == nil {
+		return source
+	}
+	return resolve(source)
+}
+
+// fileSet holds the files loaded from one file system with LoadTMX, LoadTSX, LoadTX and LoadTMXWithImages,
+// by path, so the tilesets, templates and images they reference resolve without going through the
+// Resolver. The tilesets, images and templates of files loaded from a set refer back to it, so they
+// resolve within the set only: files loaded from different file systems never mix, and never shadow
+// resolved files of the same path.
+type fileSet struct {
+	sync.RWMutex
+	fsys  fs.FS
+	files map[string]any
+}
+
+func (set *fileSet) store(path string, asset any) {
+	set.Lock()
+	set.files[path] = asset
+	set.Unlock()
+}
+
+// getFile returns the file loaded at the path into the set, if any.
+func getFile[T any](set *fileSet, path string) (T, bool) {
+	set.RLock()
+	asset, exists := set.files[path]
+	set.RUnlock()
+
+	typed, ok := asset.(T)
+	return typed, exists && ok
+}
+
+// fileSets holds the file set of each file system files were loaded from, so loading several maps
+// from one file system shares their tilesets and images.
+var fileSets = struct {
+	sync.Mutex
+	sets map[any]*fileSet
+}{sets: make(map[any]*fileSet)}
+
+// fileSetKey returns what identifies the file system in fileSets, or nil if it cannot be identified,
+// such as a struct holding a map. Maps, like fstest.MapFS, are identified by reference.
+func fileSetKey(fsys fs.FS) any {
+	v := reflect.ValueOf(fsys)
+	switch {
+	case v.Comparable():
+		return fsys
+	case v.Kind() == reflect.Map:
+		return v.Pointer()
+	default:
+		return nil
+	}
+}
+
+// fileSetOf returns the file set of the file system, creating it on first use.
+func fileSetOf(fsys fs.FS) *fileSet {
+	key := fileSetKey(fsys)
+	if key == nil {
+		return &fileSet{fsys: fsys, files: make(map[string]any)}
+	}
+
+	fileSets.Lock()
+	defer fileSets.Unlock()
+
+	set, exists := fileSets.sets[key]
+	if !exists {
+		set = &fileSet{fsys: fsys, files: make(map[string]any)}
+		fileSets.sets[key] = set
+	}
+	return set
+}
+
+// ReleaseFS drops every file loaded from the file system with LoadTMX, LoadTSX, LoadTX and
+// LoadTMXWithImages, and tells observers about each of them, so package render can free the GPU images
+// and cached tiles it built from them. Maps loaded from it must not be drawn afterwards; load them
+// again instead.
+func ReleaseFS(fsys fs.FS) {
+	key := fileSetKey(fsys)
+	if key == nil {
+		return
+	}
+
+	fileSets.Lock()
+	set, exists := fileSets.sets[key]
+	delete(fileSets.sets, key)
+	fileSets.Unlock()
+	if !exists {
+		return
+	}
+
+	set.Lock()
+	defer set.Unlock()
+
+	for path, asset := range set.files {
+		notifyObservers(func(o Observer) { o.FileReleased(path, asset) })
+	}
+	clear(set.files)
+}
+
+// lookupTSX returns the tileset at the path, from the file set the file referencing it was loaded
+// from, or from the Resolver if it was not loaded from a file system.
+func lookupTSX(set *fileSet, path string) (*TSX, error) {
+	if set == nil {
+		if r := currentResolver(); r != nil {
+			return r.TSX(path)
+		}
+	} else if tsx, ok := getFile[*TSX](set, path); ok {
+		return tsx, nil
+	}
+	return nil, fmt.Errorf("tsx is not loaded: %s", path)
+}
+
+// lookupTX returns the template at the path, like lookupTSX.
+func lookupTX(set *fileSet, path string) (*TX, error) {
+	if set == nil {
+		if r := currentResolver(); r != nil {
+			return r.TX(path)
+		}
+	} else if tx, ok := getFile[*TX](set, path); ok {
+		return tx, nil
+	}
+	return nil, fmt.Errorf("tx is not loaded: %s", path)
+}
+
+// lookupImage returns the image file at the path, like lookupTSX.
+func lookupImage(set *fileSet, path string) (image.Image, error) {
+	if set == nil {
+		if r := currentResolver(); r != nil {
+			return r.Image(path)
+		}
+	} else if img, ok := getFile[image.Image](set, path); ok {
+		return img, nil
+	}
+	return nil, fmt.Errorf("image is not loaded: %s", path)
+}
+
+// resolveTileKey returns the tileset of a tile key and the file set it is loaded from. Keys of the
+// map's tilesets resolve like the tilesets do. Other keys resolve through the Resolver, or else the one file
+// system the tileset was loaded from with LoadTMX or LoadTSX; a tileset loaded from several file
+// systems is ambiguous.
+func resolveTileKey(key TileKey, tilesets []*Tileset) (*TSX, *fileSet, error) {
+	for _, ts := range tilesets {
+		if ts.Source() == key.Source {
+			tsx, err := ts.TSX()
+			return tsx, ts.files, err
+		}
+	}
+	if r := currentResolver(); r != nil {
+		if tsx, err := r.TSX(key.Source); err == nil {
+			return tsx, nil, nil
+		}
+	}
+
+	fileSets.Lock()
+	defer fileSets.Unlock()
+
+	var found *TSX
+	var foundIn *fileSet
+	for _, set := range fileSets.sets {
+		if tsx, ok := getFile[*TSX](set, key.Source); ok {
+			if found != nil {
+				return nil, nil, fmt.Errorf("tsx is loaded from more than one file system: %s", key.Source)
+			}
+			found, foundIn = tsx, set
+		}
+	}
+	if found == nil {
+		return nil, nil, fmt.Errorf("tsx is not loaded: %s", key.Source)
+	}
+	return found, foundIn, nil
+}
+
+// ParseTMXFile parses a TMX map read from the path, resolving the sources it references relative to
+// it. Its tilesets and templates are found through the Resolver.
+func ParseTMXFile(r io.Reader, path string) (*TMX, error) {
+	return parseTMX(r, path, RelativeTo(path), nil, nil)
+}
+
+// ParseTSXFile parses a TSX tileset read from the path, like ParseTMXFile.
+func ParseTSXFile(r io.Reader, path string) (*TSX, error) {
+	return parseTSX(r, path, RelativeTo(path), nil)
+}
+
+// ParseTXFile parses a TX template read from the path, like ParseTMXFile.
+func ParseTXFile(r io.Reader, path string) (*TX, error) {
+	return parseTX(r, RelativeTo(path), nil)
+}
+
+// ParseTMX parses a TMX map, resolving the sources it references with the resolver. A nil resolver
+// leaves them as written. Tilesets and templates are not loaded; use LoadTMX for that.
+func ParseTMX(r io.Reader, resolver PathResolver) (*TMX, error) {
+	return parseTMX(r, "<input>", resolver, nil, nil)
+}
+
+// ParseTSX parses a TSX tileset, resolving the images it references with the resolver.
+func ParseTSX(r io.Reader, resolver PathResolver) (*TSX, error) {
+	return parseTSX(r, "<input>", resolver, nil)
+}
+
+// ParseTX parses a TX template, resolving the tileset it references with the resolver.
+func ParseTX(r io.Reader, resolver PathResolver) (*TX, error) {
+	return parseTX(r, resolver, nil)
+}
+
+// LoadTMX loads a TMX map from the file system along with the tilesets and templates it references,
+// so its tiles decode and its objects resolve without a Resolver. Images are not loaded; use
+// LoadTMXWithImages to draw the map with package render. Files are loaded once per file system and
+// kept until ReleaseFS.
+func LoadTMX(fsys fs.FS, name string) (*TMX, error) {
+	set := fileSetOf(fsys)
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmx, err := parseTMX(bytes.NewReader(data), name, RelativeTo(name), set, func(tmx *TMX) error {
+		for _, tileset := range tmx.Tilesets {
+			if tileset.Source() == "" {
+				continue
+			}
+			if _, err := loadStandaloneTSX(set, tileset.Source()); err != nil {
+				return err
+			}
+		}
+		for _, og := range tmx.ObjectGroups {
+			for _, obj := range og.Objects {
+				if !obj.HasTemplate() {
+					continue
+				}
+				if _, err := loadStandaloneTX(set, obj.Template()); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tmx, nil
+}
+
+// LoadTSX loads a TSX tileset from the file system like LoadTMX loads the tilesets of a map.
+func LoadTSX(fsys fs.FS, name string) (*TSX, error) {
+	return loadStandaloneTSX(fileSetOf(fsys), name)
+}
+
+// LoadTX loads a TX template from the file system along with the tileset it references.
+func LoadTX(fsys fs.FS, name string) (*TX, error) {
+	return loadStandaloneTX(fileSetOf(fsys), name)
+}
+
+func loadStandaloneTSX(set *fileSet, name string) (*TSX, error) {
+	if tsx, ok := getFile[*TSX](set, name); ok {
+		return tsx, nil
+	}
+
+	data, err := fs.ReadFile(set.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	tsx, err := parseTSX(bytes.NewReader(data), name, RelativeTo(name), set)
+	if err != nil {
+		return nil, err
+	}
+
+	set.store(name, tsx)
+	return tsx, nil
+}
+
+func loadStandaloneTX(set *fileSet, name string) (*TX, error) {
+	if tx, ok := getFile[*TX](set, name); ok {
+		return tx, nil
+	}
+
+	data, err := fs.ReadFile(set.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := parseTX(bytes.NewReader(data), RelativeTo(name), set)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Tileset != nil && tx.Tileset.Source() != "" {
+		if _, err := loadStandaloneTSX(set, tx.Tileset.Source()); err != nil {
+			return nil, err
+		}
+	}
+
+	set.store(name, tx)
+	return tx, nil
+}
+
+// parseTMX parses a map named name in errors. set is the file set the map is loaded from, if any.
+// deps, if set, loads what the map references once its sources are resolved and before its layers
+// are predecoded.
+func parseTMX(r io.Reader, name string, resolve PathResolver, set *fileSet, deps func(*TMX) error) (*TMX, error) {
+	var tmx TMX
+
+	if err := xml.NewDecoder(r).Decode(&tmx); err != nil {
+		return nil, err
+	}
+
+	if !CurrentConfig().AllowsOrientation(tmx.Orientation()) {
+		return nil, fmt.Errorf("map orientation %s is not enabled: %s", tmx.Orientation(), name)
+	}
+
+	for i := range tmx.Tilesets {
+		tmx.Tilesets[i].files = set
+		if _, exists := tmx.Tilesets[i].Attrs[SourceAttr]; exists {
+			tmx.Tilesets[i].Attrs[SourceAttr] = AttrString(resolve.apply(tmx.Tilesets[i].Source()))
+		}
+	}
+
+	for i := range tmx.ImageLayers {
+		if img := tmx.ImageLayers[i].Image; img != nil {
+			img.files = set
+			if _, exists := img.Attrs[SourceAttr]; exists {
+				img.Attrs[SourceAttr] = AttrString(resolve.apply(img.Source()))
+			}
+		}
+	}
+
+	for i := range tmx.ObjectGroups {
+		for j := range tmx.ObjectGroups[i].Objects {
+			tmx.ObjectGroups[i].Objects[j].files = set
+			if _, exists := tmx.ObjectGroups[i].Objects[j].Attrs[TemplateAttr]; !exists {
+				continue
+			}
+			tmx.ObjectGroups[i].Objects[j].Attrs[TemplateAttr] = AttrString(resolve.apply(tmx.ObjectGroups[i].Objects[j].Template()))
+		}
+	}
+
+	resolveFileProperties(resolve, tmx.Properties)
+	for _, layer := range tmx.Layers {
+		resolveFileProperties(resolve, layer.Properties)
+	}
+	for _, layer := range tmx.ImageLayers {
+		resolveFileProperties(resolve, layer.Properties)
+	}
+	for _, og := range tmx.ObjectGroups {
+		resolveFileProperties(resolve, og.Properties)
+		for _, obj := range og.Objects {
+			resolveFileProperties(resolve, obj.Properties)
+		}
+	}
+
+	if deps != nil {
+		if err := deps(&tmx); err != nil {
+			return nil, err
+		}
+	}
+
+	if CurrentConfig().PredecodeLayers {
+		if err := tmx.Predecode(); err != nil {
+			return nil, err
+		}
+	}
+
+	metricsCount(MetricMapsLoaded)
+
+	return &tmx, nil
+}
+
+func parseTSX(r io.Reader, name string, resolve PathResolver, set *fileSet) (*TSX, error) {
+	var tsx TSX
+
+	if err := xml.NewDecoder(r).Decode(&tsx); err != nil {
+		return nil, err
+	}
+
+	if tsx.Image != nil {
+		tsx.Image.files = set
+		tsx.Image.Attrs[SourceAttr] = AttrString(resolve.apply(tsx.Image.Source()))
+	}
+
+	if err := validateTSXTiles(name, &tsx); err != nil {
+		return nil, err
+	}
+
+	for i := range tsx.Tiles {
+		if img := tsx.Tiles[i].Image; img != nil {
+			img.files = set
+			img.Attrs[SourceAttr] = AttrString(resolve.apply(img.Source()))
+		}
+		resolveFileProperties(resolve, tsx.Tiles[i].Properties)
+	}
+
+	resolveFileProperties(resolve, tsx.Properties)
+
+	return &tsx, nil
+}
+
+// validateTSXTiles checks that every tile of an image collection tileset has a unique ID and an image source.
+func validateTSXTiles(name string, tsx *TSX) error {
+	seen := make(map[uint32]bool, len(tsx.Tiles))
+	for _, tile := range tsx.Tiles {
+		if _, exists := tile.Attrs[IDAttr]; !exists {
+			return fmt.Errorf("tsx tile is missing an id: %s", name)
+		}
+		if seen[tile.ID()] {
+			return fmt.Errorf("tsx tile %d is defined more than once: %s", tile.ID(), name)
+		}
+		seen[tile.ID()] = true
+
+		if tile.Image != nil && tile.Image.Source() == "" {
+			return fmt.Errorf("tsx tile %d image is missing a source: %s", tile.ID(), name)
+		}
+		if tsx.IsImageCollection() && tile.Image == nil {
+			return fmt.Errorf("tsx tile %d has no image in an image collection tileset: %s", tile.ID(), name)
+		}
+	}
+	return nil
+}
+
+func parseTX(r io.Reader, resolve PathResolver, set *fileSet) (*TX, error) {
+	var tx TX
+
+	if err := xml.NewDecoder(r).Decode(&tx); err != nil {
+		return nil, err
+	}
+
+	if tx.Tileset != nil {
+		tx.Tileset.files = set
+		if _, exists := tx.Tileset.Attrs[SourceAttr]; exists {
+			tx.Tileset.Attrs[SourceAttr] = AttrString(resolve.apply(tx.Tileset.Source()))
+		}
+	}
+
+	if tx.Object != nil {
+		resolveFileProperties(resolve, tx.Object.Properties)
+	}
+
+	return &tx, nil
+}
+
+// ======================================================
+// Standalone Images
+// ======================================================
+
+// LoadTMXWithImages loads a TMX map from the file system like LoadTMX, along with every image the map,
+// its tilesets and its templates reference, so package render draws the map without finch. This lets
+// a game ship its maps inside the binary with an embed.FS; sources resolve within the embedded tree.
+// PNG and JPEG images are supported.
+func LoadTMXWithImages(fsys fs.FS, name string) (*TMX, error) {
+	tmx, err := LoadTMX(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	set := fileSetOf(fsys)
+
+	for _, tileset := range tmx.Tilesets {
+		if tileset.Source() == "" {
+			continue
+		}
+		if err := loadTSXImages(set, tileset.Source()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, og := range tmx.ObjectGroups {
+		for _, obj := range og.Objects {
+			if !obj.HasTemplate() {
+				continue
+			}
+			tx, ok := getFile[*TX](set, obj.Template())
+			if !ok || tx.Tileset == nil || tx.Tileset.Source() == "" {
+				continue
+			}
+			if err := loadTSXImages(set, tx.Tileset.Source()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, layer := range tmx.ImageLayers {
+		if layer.Image == nil || layer.Image.Source() == "" {
+			continue
+		}
+		if err := loadStandaloneImage(set, layer.Image.Source()); err != nil {
+			return nil, err
+		}
+	}
+
+	return tmx, nil
+}
+
+// loadTSXImages loads the image of a tileset loaded with LoadTSX, or each of its tiles' images.
+func loadTSXImages(set *fileSet, name string) error {
+	tsx, ok := getFile[*TSX](set, name)
+	if !ok {
+		return fmt.Errorf("tsx is not loaded: %s", name)
+	}
+
+	if tsx.Image != nil {
+		return loadStandaloneImage(set, tsx.Image.Source())
+	}
+	for _, tile := range tsx.Tiles {
+		if tile.Image == nil {
+			continue
+		}
+		if err := loadStandaloneImage(set, tile.Image.Source()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadStandaloneImage(set *fileSet, name string) error {
+	if _, ok := getFile[image.Image](set, name); ok {
+		return nil
+	}
+
+	f, err := set.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("invalid image %s: %w", name, err)
+	}
+
+	set.store(name, img)
+	return nil
+}
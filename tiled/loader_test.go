@@ -0,0 +1,107 @@
+package tiled
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+// The forest and desert file systems hold the same map next to different tilesets of the same name.
+var (
+	forestFS = os.DirFS("testdata/loader/forest")
+	desertFS = os.DirFS("testdata/loader/desert")
+)
+
+func TestLoadTMXKeepsFileSystemsApart(t *testing.T) {
+	defer ReleaseFS(forestFS)
+	defer ReleaseFS(desertFS)
+
+	for _, c := range []struct {
+		fsys fs.FS
+		want string
+	}{{forestFS, "forest"}, {desertFS, "desert"}} {
+		want := c.want
+		tmx, err := LoadTMX(c.fsys, "maps/level.tmx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tsx, err := tmx.Tilesets[0].TSX()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tsx.Name() != want {
+			t.Errorf("map loaded from the %s file system uses tileset %s", want, tsx.Name())
+		}
+		if tsx.Image.files == nil {
+			t.Errorf("%s tileset image does not resolve within its file system", want)
+		}
+	}
+}
+
+// stubResolver resolves every tileset to the same TSX, standing in for finch's asset system.
+type stubResolver struct {
+	tsx *TSX
+}
+
+func (r stubResolver) TSX(path string) (*TSX, error) {
+	return r.tsx, nil
+}
+
+func (r stubResolver) TX(path string) (*TX, error) {
+	return nil, fmt.Errorf("tx is not loaded: %s", path)
+}
+
+func (r stubResolver) Image(path string) (image.Image, error) {
+	return nil, fmt.Errorf("image is not loaded: %s", path)
+}
+
+func TestLoadTMXDoesNotShadowResolvedFiles(t *testing.T) {
+	defer ReleaseFS(forestFS)
+
+	if _, err := LoadTMX(forestFS, "maps/level.tmx"); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := &TSX{}
+	SetResolver(stubResolver{tsx: resolved})
+	defer SetResolver(nil)
+
+	data, err := fs.ReadFile(desertFS, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmx, err := ParseTMXFile(bytes.NewReader(data), "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tsx, err := tmx.Tilesets[0].TSX(); err != nil || tsx != resolved {
+		t.Errorf("tileset of a parsed map did not resolve through the resolver: %v", err)
+	}
+}
+
+func TestReleaseFS(t *testing.T) {
+	tmx, err := LoadTMX(forestFS, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ReleaseFS(forestFS)
+
+	if _, err := tmx.Tilesets[0].TSX(); err == nil {
+		t.Error("tileset still resolves after its file system was released")
+	}
+	if _, _, err := resolveTileKey(TileKey{Source: "maps/tiles.tsx"}, nil); err == nil {
+		t.Error("tile key still resolves after its file system was released")
+	}
+
+	reloaded, err := LoadTMX(forestFS, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ReleaseFS(forestFS)
+	if _, err := reloaded.Tilesets[0].TSX(); err != nil {
+		t.Errorf("tileset does not resolve after reloading: %v", err)
+	}
+}
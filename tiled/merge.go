@@ -0,0 +1,293 @@
+package tiled
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ======================================================
+// Merging
+// ======================================================
+
+// Merge copies the tile layers and object groups of src into dst, offset by offsetX, offsetY tiles.
+// Tilesets of src that dst lacks are appended to dst after its last GID, and every copied tile and
+// tile object is remapped to dst's GIDs. Layers and groups are matched by name; unmatched ones are
+// added on top of dst. Empty cells of src leave dst untouched, and cells that fall outside a finite
+// dst are dropped. Copied objects get new IDs, and object properties referring to copied objects are
+// updated to match; references to objects that are not copied are cleared. Both maps must use the
+// same tile size, and their tilesets must be loaded. Tiles are pasted into copies of dst's layers and
+// everything is checked before dst is changed, so a failed merge leaves dst as it was.
+func Merge(dst, src *TMX, offsetX, offsetY int) error {
+	if dst.TileWidth() != src.TileWidth() || dst.TileHeight() != src.TileHeight() {
+		return fmt.Errorf("cannot merge maps with different tile sizes: %dx%d and %dx%d",
+			dst.TileWidth(), dst.TileHeight(), src.TileWidth(), src.TileHeight())
+	}
+
+	added, err := mergeTilesets(dst, src)
+	if err != nil {
+		return err
+	}
+	tilesets := append(slices.Clone(dst.Tilesets), added...)
+
+	patches := make([]*TilePatch, len(src.Layers))
+	for i, layer := range src.Layers {
+		if patches[i], err = mergePatch(dst, src, layer, tilesets); err != nil {
+			return fmt.Errorf("failed to merge layer %s: %w", layer.Name(), err)
+		}
+	}
+
+	objects := make([][]*Object, len(src.ObjectGroups))
+	for i, og := range src.ObjectGroups {
+		if objects[i], err = mergeObjects(src, og, tilesets, offsetX*src.TileWidth(), offsetY*src.TileHeight()); err != nil {
+			return fmt.Errorf("failed to merge object group %s: %w", og.Name(), err)
+		}
+	}
+
+	// Cells are pasted into copies of dst's layers, and new layers are filled before they are added,
+	// so a paste that fails leaves dst untouched. What the copies decoded is dropped either way.
+	staged := make(map[string]*Layer)
+	var layers []*Layer
+	committed := false
+	defer func() {
+		for _, layer := range staged {
+			if !committed || !slices.Contains(layers, layer) {
+				layer.invalidate()
+			}
+		}
+	}()
+	for i, layer := range src.Layers {
+		if patches[i] == nil {
+			continue
+		}
+		target, exists := staged[layer.Name()]
+		if !exists {
+			if existing := dst.LayerByName(layer.Name()); existing != nil {
+				target = &Layer{Attrs: existing.Attrs, Data: existing.Data.clone()}
+			} else {
+				target = newMergedLayer(dst, layer)
+				layers = append(layers, target)
+			}
+			staged[layer.Name()] = target
+		}
+		bounds := layer.Bounds()
+		if err := target.PasteRegion(patches[i], int(bounds.X)+offsetX, int(bounds.Y)+offsetY); err != nil {
+			return fmt.Errorf("failed to merge layer %s: %w", layer.Name(), err)
+		}
+	}
+
+	for _, ts := range added {
+		dst.AddTileset(ts)
+	}
+	for name, layer := range staged {
+		if existing := dst.LayerByName(name); existing != nil {
+			existing.Data = layer.Data
+			existing.invalidate()
+		}
+	}
+	for _, layer := range layers {
+		dst.AddLayer(layer)
+	}
+	committed = true
+
+	// Objects are added first and their references rewritten once every new ID is known.
+	ids := make(map[int]int)
+	var groups []*ObjectGroup
+	for i, og := range src.ObjectGroups {
+		target := dst.ObjectGroupByName(og.Name())
+		if target == nil {
+			target = dst.AddObjectGroup(og.Name())
+			for name, attr := range og.Attrs {
+				if name != IDAttr {
+					target.Attrs[name] = attr
+				}
+			}
+			target.Properties = og.Properties
+			groups = append(groups, target)
+		}
+		for j, obj := range objects[i] {
+			ids[og.Objects[j].ID()] = dst.AddObject(target, obj).ID()
+		}
+	}
+	for _, layer := range layers {
+		layer.Properties = remapObjectRefs(layer.Properties, ids)
+	}
+	for _, og := range groups {
+		og.Properties = remapObjectRefs(og.Properties, ids)
+	}
+	for _, copies := range objects {
+		for _, obj := range copies {
+			obj.Properties = remapObjectRefs(obj.Properties, ids)
+		}
+	}
+
+	return nil
+}
+
+// mergeTilesets returns the tilesets of src missing from dst, numbered to start after dst's last GID.
+func mergeTilesets(dst, src *TMX) ([]*Tileset, error) {
+	var missing []int
+	for i, ts := range src.Tilesets {
+		if _, ok := gidOf(TileKey{Source: ts.Source()}, dst.Tilesets); !ok {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	next := uint32(1)
+	for i, ts := range dst.Tilesets {
+		span, err := tilesetSpan(dst.Tilesets, i)
+		if err != nil {
+			return nil, err
+		}
+		next = max(next, ts.FirstGID()+span)
+	}
+
+	var added []*Tileset
+	for _, i := range missing {
+		span, err := tilesetSpan(src.Tilesets, i)
+		if err != nil {
+			return nil, err
+		}
+		ts := NewTileset(src.Tilesets[i].Source(), next)
+		ts.files = src.Tilesets[i].files
+		added = append(added, ts)
+		next += span
+	}
+
+	return added, nil
+}
+
+// tilesetSpan returns how many GIDs a map's tileset reserves: enough for its highest tile ID.
+// Tilesets followed by another span up to it; the last one is read from its loaded TSX.
+func tilesetSpan(tilesets []*Tileset, i int) (uint32, error) {
+	if i+1 < len(tilesets) {
+		return tilesets[i+1].FirstGID() - tilesets[i].FirstGID(), nil
+	}
+
+	tsx, err := tilesets[i].TSX()
+	if err != nil {
+		return 0, err
+	}
+
+	span := uint32(tsx.TileCount())
+	for _, tile := range tsx.Tiles {
+		span = max(span, tile.ID()+1)
+	}
+	return span, nil
+}
+
+// mergePatch returns the cells of a layer of src remapped to the tilesets dst will have, or nil if the
+// layer has none. The layer of dst it is pasted into, if there is one, must be decodable.
+func mergePatch(dst, src *TMX, layer *Layer, tilesets []*Tileset) (*TilePatch, error) {
+	if layer.Data == nil {
+		return nil, nil
+	}
+
+	bounds := layer.Bounds()
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return nil, nil
+	}
+
+	patch, err := layer.CopyRegion(bounds)
+	if err != nil {
+		return nil, err
+	}
+	if patch, err = patch.Remap(src.Tilesets, tilesets); err != nil {
+		return nil, err
+	}
+
+	if target := dst.LayerByName(layer.Name()); target != nil {
+		if target.Data == nil {
+			return nil, fmt.Errorf("layer %s has no data", target.Name())
+		}
+		if _, err := target.cellGrids(); err != nil {
+			return nil, err
+		}
+	}
+
+	return patch, nil
+}
+
+// newMergedLayer returns an empty layer for dst carrying the source layer's attributes and properties.
+func newMergedLayer(dst *TMX, layer *Layer) *Layer {
+	merged := NewLayer(layer.Name(), dst.Width(), dst.Height())
+	for name, attr := range layer.Attrs {
+		if name != IDAttr && name != WidthAttr && name != HeightAttr {
+			merged.Attrs[name] = attr
+		}
+	}
+	merged.Properties = layer.Properties
+
+	if dst.IsInfinite() {
+		merged.Data.Chunks = []*DataChunk{{
+			Attrs: TiledXMLAttrTable{
+				XAttr:      AttrInt(0),
+				YAttr:      AttrInt(0),
+				WidthAttr:  AttrInt(DefaultChunkSize),
+				HeightAttr: AttrInt(DefaultChunkSize),
+			},
+			Data: encodeCsvData(make([]uint32, DefaultChunkSize*DefaultChunkSize), DefaultChunkSize),
+		}}
+		merged.Data.Data = ""
+		merged.invalidate()
+	}
+
+	return merged
+}
+
+// mergeObjects returns copies of the objects of a group of src, moved by dx, dy pixels, with their tiles
+// remapped to the tilesets dst will have.
+func mergeObjects(src *TMX, og *ObjectGroup, tilesets []*Tileset, dx, dy int) ([]*Object, error) {
+	copies := make([]*Object, 0, len(og.Objects))
+	for _, obj := range og.Objects {
+		copied := *obj
+		copied.Attrs = maps.Clone(obj.Attrs)
+		copied.Attrs[XAttr] = numberAttr(obj.X64() + float64(dx))
+		copied.Attrs[YAttr] = numberAttr(obj.Y64() + float64(dy))
+
+		if data := uint32(obj.GID()); data != 0 {
+			key, ok := TileKeyOf(data, src.Tilesets)
+			if !ok {
+				return nil, fmt.Errorf("object %d references an unknown tile: %d", obj.ID(), data&TILE_ID_MASK)
+			}
+			gid, ok := gidOf(key, tilesets)
+			if !ok {
+				return nil, fmt.Errorf("tileset not found in target map: %s", key.Source)
+			}
+			copied.Attrs[GIDAttr] = AttrInt(int(gid | data&^TILE_ID_MASK))
+		}
+
+		copies = append(copies, &copied)
+	}
+
+	return copies, nil
+}
+
+// remapObjectRefs returns a copy of the properties, including the members of class properties, with
+// object properties pointing at the new IDs of the objects they referred to. References to objects
+// missing from ids are cleared.
+func remapObjectRefs(props []*Property, ids map[int]int) []*Property {
+	if props == nil {
+		return nil
+	}
+
+	remapped := make([]*Property, len(props))
+	for i, prop := range props {
+		cp := &Property{Attrs: maps.Clone(prop.Attrs), Properties: remapObjectRefs(prop.Properties, ids)}
+		if prop.Type() == ObjectPropertyType {
+			if id, err := prop.ObjectRef(); err == nil && id != 0 {
+				value := ""
+				if newID, ok := ids[id]; ok {
+					value = strconv.Itoa(newID)
+				}
+				cp.Attrs[ValueAttr] = AttrString(value)
+			}
+		}
+		remapped[i] = cp
+	}
+	return remapped
+}
@@ -0,0 +1,98 @@
+package tiled
+
+import "testing"
+
+func TestMergeRemapsObjectReferences(t *testing.T) {
+	dst, src := loadTestMap(t, "merge/dst.tmx"), loadTestMap(t, "merge/object_refs.tmx")
+	if err := Merge(dst, src, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	objects := dst.ObjectGroupByName("things").Objects
+	if len(objects) != 3 {
+		t.Fatalf("%d objects after merging, want 3", len(objects))
+	}
+	lever, door := objects[1], objects[2]
+	if lever.ID() != 10 || door.ID() != 11 {
+		t.Fatalf("merged objects got IDs %d and %d, want 10 and 11", lever.ID(), door.ID())
+	}
+
+	opens, _ := lever.PropertyByName("opens")
+	if id, err := opens.ObjectRef(); err != nil || id != door.ID() {
+		t.Errorf("lever opens object %d (%v), want the merged door %d", id, err, door.ID())
+	}
+	stray, _ := lever.PropertyByName("stray")
+	if id, _ := stray.ObjectRef(); id != 0 {
+		t.Errorf("reference to an object that was not merged points at %d, want none", id)
+	}
+
+	srcOpens, _ := src.ObjectGroupByName("things").Objects[0].PropertyByName("opens")
+	if id, _ := srcOpens.ObjectRef(); id != 2 {
+		t.Errorf("merging changed the source map's reference to %d", id)
+	}
+}
+
+func TestMergeFailureLeavesTargetUntouched(t *testing.T) {
+	// The layer merges cleanly, but the object references a GID below every tileset.
+	dst, src := loadTestMap(t, "merge/dst.tmx"), loadTestMap(t, "merge/unknown_tile.tmx")
+	if err := Merge(dst, src, 1, 0); err == nil {
+		t.Fatal("merging an object with an unknown tile succeeded")
+	}
+
+	if len(dst.Tilesets) != 1 {
+		t.Errorf("failed merge added %d tilesets", len(dst.Tilesets)-1)
+	}
+	if gid, _ := dst.LayerByName("ground").GetTileGID(1, 0); gid != 0 {
+		t.Errorf("failed merge pasted cells: %d", gid)
+	}
+	if n := len(dst.ObjectGroupByName("things").Objects); n != 1 {
+		t.Errorf("failed merge left %d objects, want 1", n)
+	}
+}
+
+func TestMergePasteFailureLeavesTargetUntouched(t *testing.T) {
+	// The decor layer and the extra tileset are new to dst, but ground can't grow past its broken chunk.
+	dst, src := loadTestMap(t, "merge/dst_bad_chunk.tmx"), loadTestMap(t, "merge/two_layers.tmx")
+	ground := dst.LayerByName("ground").Data.Chunks[0]
+
+	if err := Merge(dst, src, 8, 8); err == nil {
+		t.Fatal("pasting past a broken chunk succeeded")
+	}
+
+	if len(dst.Tilesets) != 1 {
+		t.Errorf("failed merge added %d tilesets", len(dst.Tilesets)-1)
+	}
+	if len(dst.Layers) != 1 || dst.LayerByName("decor") != nil {
+		t.Errorf("failed merge left %d layers, want only ground", len(dst.Layers))
+	}
+	if chunks := dst.LayerByName("ground").Data.Chunks; len(chunks) != 1 || chunks[0] != ground {
+		t.Errorf("failed merge changed the chunks of ground: %d chunks", len(chunks))
+	}
+}
+
+func TestMergeIntoExistingLayer(t *testing.T) {
+	dst, src := loadTestMap(t, "merge/dst.tmx"), loadTestMap(t, "merge/two_layers.tmx")
+	ground := dst.LayerByName("ground")
+	if gid, _ := ground.GetTileGID(0, 0); gid != 1 {
+		t.Fatalf("cell 0,0 of ground is %d before merging", gid)
+	}
+
+	if err := Merge(dst, src, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The extra tileset is appended after the four tiles of tiles.tsx.
+	for _, c := range []struct {
+		x, y int
+		want uint32
+	}{{0, 0, 1}, {1, 1, 6}, {2, 2, 6}, {3, 3, 0}} {
+		if gid, _ := ground.GetTileGID(c.x, c.y); gid != c.want {
+			t.Errorf("ground cell %d,%d is %d, want %d", c.x, c.y, gid, c.want)
+		}
+	}
+	if decor := dst.LayerByName("decor"); decor == nil {
+		t.Error("decor layer was not added")
+	} else if gid, _ := decor.GetTileGID(1, 1); gid != 5 {
+		t.Errorf("decor cell 1,1 is %d, want 5", gid)
+	}
+}
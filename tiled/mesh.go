@@ -0,0 +1,83 @@
+package tiled
+
+import (
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Greedy Meshing
+// ======================================================
+
+// Rects merges the grid's solid cells into a compact set of world-space rectangles,
+// suitable for handing to a physics engine in place of one collider per tile.
+// Rectangles are grown greedily, first along rows and then down columns, and never overlap.
+// Chunks of infinite maps are merged across their seams.
+func (cg *CollisionGrid) Rects() ([]geom.Rect64, error) {
+	grids, err := cg.layer.cellGrids()
+	if err != nil || len(grids) == 0 {
+		return nil, err
+	}
+
+	minX, minY, width, height := gridBounds(grids)
+
+	solid, err := cg.solidCells(grids, minX, minY, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	cw, ch := float64(cg.cellWidth), float64(cg.cellHeight)
+
+	var rects []geom.Rect64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !solid[y*width+x] {
+				continue
+			}
+
+			w := 1
+			for x+w < width && solid[y*width+x+w] {
+				w++
+			}
+
+			h := 1
+			for y+h < height && rowSolid(solid[(y+h)*width+x:(y+h)*width+x+w]) {
+				h++
+			}
+
+			// Cells are consumed as they are merged so later rectangles don't overlap this one.
+			for row := y; row < y+h; row++ {
+				clear(solid[row*width+x : row*width+x+w])
+			}
+
+			rects = append(rects, geom.NewRect64(float64(minX+x)*cw, float64(minY+y)*ch, float64(w)*cw, float64(h)*ch))
+		}
+	}
+
+	return rects, nil
+}
+
+func rowSolid(cells []bool) bool {
+	for _, solid := range cells {
+		if !solid {
+			return false
+		}
+	}
+	return true
+}
+
+// solidCells flattens the solidity of every grid into a single row-major block of cells.
+func (cg *CollisionGrid) solidCells(grids []*cellGrid, minX, minY, width, height int) ([]bool, error) {
+	solid := make([]bool, width*height)
+	for _, g := range grids {
+		segment, _, err := cg.segment(g.x, g.y)
+		if err != nil {
+			return nil, err
+		}
+		for row := 0; row < g.height; row++ {
+			for col := 0; col < g.width; col++ {
+				solid[(g.y-minY+row)*width+(g.x-minX+col)] = segment[row*g.width+col]
+			}
+		}
+	}
+	return solid, nil
+}
@@ -0,0 +1,57 @@
+package tiled
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+func TestCollisionGridRects(t *testing.T) {
+	tmx := loadFixture(t, "ortho_zlib.tmx")
+	cg, err := NewCollisionGrid(tmx, "walls", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rects, err := cg.Rects()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows are grown first, so the outer walls become the top row, both sides and two bottom runs.
+	want := []geom.Rect64{
+		geom.NewRect64(0, 0, 128, 16),
+		geom.NewRect64(0, 16, 16, 80),
+		geom.NewRect64(64, 16, 16, 32),
+		geom.NewRect64(112, 16, 16, 80),
+		geom.NewRect64(32, 32, 16, 32),
+		geom.NewRect64(96, 48, 16, 16),
+		geom.NewRect64(64, 64, 16, 32),
+		geom.NewRect64(16, 80, 48, 16),
+		geom.NewRect64(80, 80, 32, 16),
+	}
+	if !slices.Equal(rects, want) {
+		t.Fatalf("rects are %v, want %v", rects, want)
+	}
+
+	// Every solid cell is covered exactly once.
+	for y := range 6 {
+		for x := range 8 {
+			solid, err := cg.IsSolid(x, y)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cell := geom.NewRect64(float64(x)*16+4, float64(y)*16+4, 8, 8)
+			covered := 0
+			for _, r := range rects {
+				if r.Intersects(cell) {
+					covered++
+				}
+			}
+			if solid && covered != 1 || !solid && covered != 0 {
+				t.Errorf("cell %d,%d (solid=%v) is covered by %d rects", x, y, solid, covered)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package tiled
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ======================================================
+// Metrics
+// ======================================================
+
+// Metric names reported to Metrics.
+const (
+	MetricMapsLoaded   = "tiled_maps_loaded_total"
+	MetricCachedChunks = "tiled_cached_chunks"
+	MetricCacheBytes   = "tiled_cache_bytes"
+	MetricDecodeTime   = "tiled_decode_seconds"
+	MetricDrawTime     = "tiled_draw_seconds"
+)
+
+// Metrics receives measurements from the package's subsystems.
+// Implementations must be safe for concurrent use; adapt it to expvar, Prometheus or any other backend.
+type Metrics interface {
+	// AddCounter increases a monotonic counter.
+	AddCounter(name string, delta float64)
+
+	// SetGauge records the current value of a gauge.
+	SetGauge(name string, value float64)
+
+	// ObserveDuration records how long an operation took.
+	ObserveDuration(name string, d time.Duration)
+}
+
+var (
+	metrics      Metrics
+	metricsMutex sync.RWMutex
+
+	cachedChunks atomic.Int64
+	cacheBytes   atomic.Int64
+)
+
+// SetMetrics routes the package's measurements to m. Passing nil disables metrics.
+func SetMetrics(m Metrics) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	metrics = m
+	if m != nil {
+		m.SetGauge(MetricCachedChunks, float64(cachedChunks.Load()))
+		m.SetGauge(MetricCacheBytes, float64(cacheBytes.Load()))
+	}
+}
+
+// CurrentMetrics returns the Metrics set with SetMetrics, or nil if metrics are disabled.
+func CurrentMetrics() Metrics {
+	metricsMutex.RLock()
+	defer metricsMutex.RUnlock()
+	return metrics
+}
+
+// metricsStart returns the time an operation started, or the zero time when metrics are disabled.
+func metricsStart() time.Time {
+	if CurrentMetrics() == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// metricsObserve records the duration of an operation started with metricsStart.
+func metricsObserve(name string, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	if m := CurrentMetrics(); m != nil {
+		m.ObserveDuration(name, time.Since(start))
+	}
+}
+
+func metricsCount(name string) {
+	if m := CurrentMetrics(); m != nil {
+		m.AddCounter(name, 1)
+	}
+}
+
+// metricsCacheChanged tracks decoded blocks of cells being added to, or dropped from, layer caches.
+func metricsCacheChanged(grids []*cellGrid, sign int64) {
+	var bytes, count int64
+	for _, g := range grids {
+		if g != nil {
+			bytes += int64(len(g.data)) * 4
+			count++
+		}
+	}
+	chunks := cachedChunks.Add(sign * count)
+	total := cacheBytes.Add(sign * bytes)
+
+	if m := CurrentMetrics(); m != nil {
+		m.SetGauge(MetricCachedChunks, float64(chunks))
+		m.SetGauge(MetricCacheBytes, float64(total))
+	}
+}
+
+// ======================================================
+// Expvar Metrics
+// ======================================================
+
+// ExpvarMetrics publishes measurements as an expvar map, served by the standard /debug/vars handler.
+// Durations are published as a total in seconds and a count, under the metric name with
+// "_sum" and "_count" suffixes.
+type ExpvarMetrics struct {
+	vars *expvar.Map
+}
+
+// NewExpvarMetrics publishes a new expvar map with the given name.
+// Like expvar.Publish, it panics if the name is already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{vars: expvar.NewMap(name)}
+}
+
+func (e *ExpvarMetrics) AddCounter(name string, delta float64) {
+	e.vars.AddFloat(name, delta)
+}
+
+func (e *ExpvarMetrics) SetGauge(name string, value float64) {
+	v := new(expvar.Float)
+	v.Set(value)
+	e.vars.Set(name, v)
+}
+
+func (e *ExpvarMetrics) ObserveDuration(name string, d time.Duration) {
+	e.vars.AddFloat(name+"_sum", d.Seconds())
+	e.vars.Add(name+"_count", 1)
+}
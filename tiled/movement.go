@@ -0,0 +1,260 @@
+package tiled
+
+import (
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Move And Collide
+// ======================================================
+
+// collisionEpsilon keeps resolved bodies from registering as overlapping the cell they were pushed out of.
+const collisionEpsilon = 1e-6
+
+// Contact describes a surface a moving body touched.
+type Contact struct {
+	Cell   Cell
+	Normal geom.Point64
+}
+
+// MoveResult is the outcome of moving a body against a collision grid.
+type MoveResult struct {
+	// Rect is the body's resolved bounds.
+	Rect geom.Rect64
+
+	// Contacts lists the surfaces touched while moving, in the order they were hit.
+	Contacts []Contact
+
+	// OnSlope reports whether the body ended resting on a slope.
+	OnSlope bool
+}
+
+// HasContact reports whether any contact normal points roughly along the provided direction,
+// e.g. geom.NewPoint64(0, -1) for "standing on something".
+func (r MoveResult) HasContact(direction geom.Point64) bool {
+	for _, c := range r.Contacts {
+		if c.Normal.X*direction.X+c.Normal.Y*direction.Y > 0.5 {
+			return true
+		}
+	}
+	return false
+}
+
+// MoveOptions controls how MoveAndCollideWithOptions resolves movement.
+type MoveOptions struct {
+	// IgnoreOneWay lets the body fall through one-way platforms, e.g. while dropping down.
+	IgnoreOneWay bool
+
+	// NoSlopeSnap stops bodies from being settled onto slopes below them while moving down.
+	NoSlopeSnap bool
+}
+
+// MoveAndCollide moves an axis-aligned body by delta, stopping it against solid cells.
+// Movement is resolved horizontally and then vertically so bodies slide along walls and floors.
+// Bodies whose bottom centre ends inside a slope cell are lifted onto the slope's surface.
+func (cg *CollisionGrid) MoveAndCollide(rect geom.Rect64, delta geom.Point64) (MoveResult, error) {
+	return cg.MoveAndCollideWithOptions(rect, delta, MoveOptions{})
+}
+
+// MoveAndCollideWithOptions is like MoveAndCollide with configurable one-way and slope handling.
+func (cg *CollisionGrid) MoveAndCollideWithOptions(rect geom.Rect64, delta geom.Point64, opts MoveOptions) (MoveResult, error) {
+	result := MoveResult{Rect: rect}
+
+	snap := delta.Y >= 0 && !opts.NoSlopeSnap
+
+	if err := cg.sweepX(&result, delta.X); err != nil {
+		return result, err
+	}
+	if err := cg.resolveSlope(&result, snap); err != nil {
+		return result, err
+	}
+	if err := cg.sweepY(&result, delta.Y, !opts.IgnoreOneWay); err != nil {
+		return result, err
+	}
+	if err := cg.resolveSlope(&result, snap); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Overlaps reports whether the rect overlaps any solid cell.
+func (cg *CollisionGrid) Overlaps(rect geom.Rect64) (bool, error) {
+	minCol, minRow := cg.cellOf(rect.X, rect.Y)
+	maxCol, maxRow := cg.cellOf(rect.X+rect.Width-collisionEpsilon, rect.Y+rect.Height-collisionEpsilon)
+
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			solid, err := cg.IsSolid(col, row)
+			if err != nil {
+				return false, err
+			}
+			if solid {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (cg *CollisionGrid) sweepX(result *MoveResult, dx float64) error {
+	if dx == 0 {
+		return nil
+	}
+
+	cw := float64(cg.cellWidth)
+	rect := &result.Rect
+
+	_, minRow := cg.cellOf(rect.X, rect.Y)
+	_, maxRow := cg.cellOf(rect.X, rect.Y+rect.Height-collisionEpsilon)
+
+	if dx > 0 {
+		edge := rect.X + rect.Width
+		first := int(math.Floor((edge - collisionEpsilon) / cw))
+		last := int(math.Floor((edge + dx - collisionEpsilon) / cw))
+		for col := first + 1; col <= last; col++ {
+			if row, hit, err := cg.solidInColumn(col, minRow, maxRow); err != nil {
+				return err
+			} else if hit {
+				rect.X = float64(col)*cw - rect.Width
+				result.Contacts = append(result.Contacts, Contact{Cell: Cell{X: col, Y: row}, Normal: geom.NewPoint64(-1, 0)})
+				return nil
+			}
+		}
+	} else {
+		edge := rect.X
+		first := int(math.Floor((edge + collisionEpsilon) / cw))
+		last := int(math.Floor((edge + dx) / cw))
+		for col := first - 1; col >= last; col-- {
+			if row, hit, err := cg.solidInColumn(col, minRow, maxRow); err != nil {
+				return err
+			} else if hit {
+				rect.X = float64(col+1) * cw
+				result.Contacts = append(result.Contacts, Contact{Cell: Cell{X: col, Y: row}, Normal: geom.NewPoint64(1, 0)})
+				return nil
+			}
+		}
+	}
+
+	rect.X += dx
+	return nil
+}
+
+func (cg *CollisionGrid) sweepY(result *MoveResult, dy float64, landOnOneWay bool) error {
+	if dy == 0 {
+		return nil
+	}
+
+	ch := float64(cg.cellHeight)
+	rect := &result.Rect
+
+	minCol, _ := cg.cellOf(rect.X, rect.Y)
+	maxCol, _ := cg.cellOf(rect.X+rect.Width-collisionEpsilon, rect.Y)
+
+	if dy > 0 {
+		edge := rect.Y + rect.Height
+		first := int(math.Floor((edge - collisionEpsilon) / ch))
+		last := int(math.Floor((edge + dy - collisionEpsilon) / ch))
+		for row := first + 1; row <= last; row++ {
+			// One-way platforms only catch bodies whose bottom started at or above the platform's top.
+			col, hit, err := cg.solidInRow(row, minCol, maxCol, landOnOneWay)
+			if err != nil {
+				return err
+			}
+			if hit {
+				rect.Y = float64(row)*ch - rect.Height
+				result.Contacts = append(result.Contacts, Contact{Cell: Cell{X: col, Y: row}, Normal: geom.NewPoint64(0, -1)})
+				return nil
+			}
+		}
+	} else {
+		edge := rect.Y
+		first := int(math.Floor((edge + collisionEpsilon) / ch))
+		last := int(math.Floor((edge + dy) / ch))
+		for row := first - 1; row >= last; row-- {
+			col, hit, err := cg.solidInRow(row, minCol, maxCol, false)
+			if err != nil {
+				return err
+			}
+			if hit {
+				rect.Y = float64(row+1) * ch
+				result.Contacts = append(result.Contacts, Contact{Cell: Cell{X: col, Y: row}, Normal: geom.NewPoint64(0, 1)})
+				return nil
+			}
+		}
+	}
+
+	rect.Y += dy
+	return nil
+}
+
+// resolveSlope lifts the body onto the surface of the slope under its bottom centre.
+// The cells above and below the foot are considered too, so bodies follow slopes that span rows.
+// When snap is set, bodies hovering less than a cell above a slope surface are settled onto it,
+// which keeps them attached while walking down slopes.
+func (cg *CollisionGrid) resolveSlope(result *MoveResult, snap bool) error {
+	if cg.slope == nil {
+		return nil
+	}
+
+	rect := &result.Rect
+	cw, ch := float64(cg.cellWidth), float64(cg.cellHeight)
+
+	footX := rect.X + rect.Width/2
+	footY := rect.Y + rect.Height
+
+	col, footRow := cg.cellOf(footX, footY-collisionEpsilon)
+
+	for row := footRow - 1; row <= footRow+1; row++ {
+		slope, ok, err := cg.SlopeAt(col, row)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		surface := float64(row+1)*ch - slope.HeightAt((footX-float64(col)*cw)/cw)*ch
+
+		if footY > surface || (snap && row >= footRow && surface-footY < ch) {
+			rect.Y = surface - rect.Height
+			result.OnSlope = true
+
+			contact := Contact{Cell: Cell{X: col, Y: row}, Normal: slope.Normal(cw, ch)}
+			if n := len(result.Contacts); n == 0 || result.Contacts[n-1] != contact {
+				result.Contacts = append(result.Contacts, contact)
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (cg *CollisionGrid) solidInColumn(col, minRow, maxRow int) (int, bool, error) {
+	for row := minRow; row <= maxRow; row++ {
+		solid, err := cg.IsSolid(col, row)
+		if err != nil || solid {
+			return row, solid, err
+		}
+	}
+	return 0, false, nil
+}
+
+func (cg *CollisionGrid) solidInRow(row, minCol, maxCol int, includeOneWay bool) (int, bool, error) {
+	for col := minCol; col <= maxCol; col++ {
+		solid, err := cg.IsSolid(col, row)
+		if err != nil || solid {
+			return col, solid, err
+		}
+		if includeOneWay {
+			oneWay, err := cg.IsOneWay(col, row)
+			if err != nil || oneWay {
+				return col, oneWay, err
+			}
+		}
+	}
+	return 0, false, nil
+}
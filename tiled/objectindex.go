@@ -0,0 +1,155 @@
+package tiled
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/adm87/finch-core/geom"
+	"github.com/adm87/finch-core/partition/quadtree"
+)
+
+// ======================================================
+// Object Index
+// ======================================================
+
+const (
+	objectIndexLeafSize = 8
+	objectIndexDepth    = 8
+)
+
+// ObjectIndex answers area and point queries against the objects of every object group of a map
+// using a quadtree, so object-heavy maps are not scanned object by object.
+// Objects are indexed by their bounds when added; call Update after moving or resizing one.
+type ObjectIndex struct {
+	tmx     *TMX
+	tree    *quadtree.QuadTree[*indexedObject]
+	entries map[*Object]*indexedObject
+	next    int
+}
+
+type indexedObject struct {
+	obj    *Object
+	bounds geom.Rect64
+	seq    int
+}
+
+// Bounds returns the area the object is indexed under. Points and lines have no area, so they are
+// given a sliver of one for the quadtree to place them.
+func (e *indexedObject) Bounds() geom.Rect64 {
+	b := e.bounds
+	b.Width, b.Height = max(b.Width, objectIndexSliver), max(b.Height, objectIndexSliver)
+	return b
+}
+
+const objectIndexSliver = 1e-6
+
+// NewObjectIndex indexes every object of the map.
+func NewObjectIndex(tmx *TMX) *ObjectIndex {
+	idx := &ObjectIndex{tmx: tmx, entries: make(map[*Object]*indexedObject)}
+
+	var entries []*indexedObject
+	area := tmx.Bounds()
+	for _, og := range tmx.ObjectGroups {
+		for _, obj := range og.Objects {
+			entry := idx.entry(obj)
+			entries = append(entries, entry)
+			area = area.Union(entry.Bounds())
+		}
+	}
+
+	idx.tree = quadtree.New[*indexedObject](area, objectIndexLeafSize, objectIndexDepth)
+	for _, entry := range entries {
+		idx.tree.Insert(entry)
+	}
+	return idx
+}
+
+// ObjectIndex returns the map's object index, building it on first use. Objects added with AddObject
+// are indexed automatically, and Crop and Resize rebuild the index after moving and removing objects.
+func (tmx *TMX) ObjectIndex() *ObjectIndex {
+	if tmx.objects == nil {
+		tmx.objects = NewObjectIndex(tmx)
+	}
+	return tmx.objects
+}
+
+func (idx *ObjectIndex) entry(obj *Object) *indexedObject {
+	entry := &indexedObject{obj: obj, bounds: idx.tmx.ObjectBounds(obj), seq: idx.next}
+	idx.entries[obj] = entry
+	idx.next++
+	return entry
+}
+
+// Add indexes an object, growing the indexed area when the object lies outside it.
+func (idx *ObjectIndex) Add(obj *Object) {
+	if _, exists := idx.entries[obj]; exists {
+		idx.Update(obj)
+		return
+	}
+	idx.insert(idx.entry(obj))
+}
+
+// Remove drops an object from the index.
+func (idx *ObjectIndex) Remove(obj *Object) {
+	if entry, exists := idx.entries[obj]; exists {
+		idx.tree.Remove(entry)
+		delete(idx.entries, obj)
+	}
+}
+
+// Update re-indexes an object after it moved or changed size.
+func (idx *ObjectIndex) Update(obj *Object) {
+	entry, exists := idx.entries[obj]
+	if !exists {
+		return
+	}
+	idx.tree.Remove(entry)
+	entry.bounds = idx.tmx.ObjectBounds(obj)
+	idx.insert(entry)
+}
+
+func (idx *ObjectIndex) insert(entry *indexedObject) {
+	if !idx.tree.Insert(entry) {
+		minX, minY := idx.tree.Min()
+		maxX, maxY := idx.tree.Max()
+		idx.tree.Resize(geom.NewRect64(minX, minY, maxX-minX, maxY-minY).Union(entry.Bounds()))
+		idx.tree.Insert(entry)
+	}
+}
+
+// ObjectsInRect returns the objects whose bounds overlap the area, in the order they were indexed.
+// Points and lines count as overlapping when they touch the area.
+func (idx *ObjectIndex) ObjectsInRect(area geom.Rect64) []*Object {
+	return idx.query(area, func(b geom.Rect64) bool {
+		return b.X <= area.X+area.Width && b.X+b.Width >= area.X && b.Y <= area.Y+area.Height && b.Y+b.Height >= area.Y
+	})
+}
+
+// ObjectsAt returns the objects whose bounds contain the world position, in the order they were indexed.
+func (idx *ObjectIndex) ObjectsAt(x, y float64) []*Object {
+	return idx.query(geom.NewRect64(x, y, objectIndexSliver, objectIndexSliver), func(b geom.Rect64) bool {
+		return x >= b.X && x <= b.X+b.Width && y >= b.Y && y <= b.Y+b.Height
+	})
+}
+
+// Overlapping returns the other indexed objects whose bounds overlap the object's, such as the triggers an actor stands in.
+func (idx *ObjectIndex) Overlapping(obj *Object) []*Object {
+	objects := idx.ObjectsInRect(idx.tmx.ObjectBounds(obj))
+	return slices.DeleteFunc(objects, func(o *Object) bool { return o == obj })
+}
+
+func (idx *ObjectIndex) query(area geom.Rect64, hit func(geom.Rect64) bool) []*Object {
+	var matches []*indexedObject
+	for entry := range idx.tree.Query(area.Expend(objectIndexSliver)) {
+		if hit(entry.bounds) {
+			matches = append(matches, entry)
+		}
+	}
+	slices.SortFunc(matches, func(a, b *indexedObject) int { return cmp.Compare(a.seq, b.seq) })
+
+	objects := make([]*Object, len(matches))
+	for i, entry := range matches {
+		objects[i] = entry.obj
+	}
+	return objects
+}
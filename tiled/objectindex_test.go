@@ -0,0 +1,53 @@
+package tiled
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+func TestObjectIndexFollowsCrop(t *testing.T) {
+	tmx, err := ParseTMX(strings.NewReader(cropTestMap), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spawn, crate := tmx.ObjectGroups[0].Objects[0], tmx.ObjectGroups[0].Objects[1]
+	if objects := tmx.ObjectIndex().ObjectsAt(80, 80); len(objects) != 1 || objects[0] != spawn {
+		t.Fatalf("objects at the spawn are %v before cropping", objects)
+	}
+
+	// Cropping to the spawn's tile drops the crate and moves the spawn to 0,0.
+	if err := Crop(tmx, geom.NewRect64(5, 5, 1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	idx := tmx.ObjectIndex()
+	if objects := idx.ObjectsAt(80, 80); len(objects) != 0 {
+		t.Errorf("objects at the spawn's old position: %v", objects)
+	}
+	if objects := idx.ObjectsAt(0, 0); len(objects) != 1 || objects[0] != spawn {
+		t.Errorf("objects at the spawn's new position are %v", objects)
+	}
+	for _, obj := range idx.ObjectsInRect(geom.NewRect64(-1000, -1000, 2000, 2000)) {
+		if obj == crate {
+			t.Error("cropped crate is still indexed")
+		}
+	}
+}
+
+func TestObjectIndexFollowsResize(t *testing.T) {
+	tmx, err := ParseTMX(strings.NewReader(cropTestMap), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spawn := tmx.ObjectGroups[0].Objects[0]
+	tmx.ObjectIndex()
+
+	// Growing the map by two tiles on the left and top moves everything by 32 pixels.
+	if err := tmx.Resize(10, 10, AnchorBottomRight); err != nil {
+		t.Fatal(err)
+	}
+	if objects := tmx.ObjectIndex().ObjectsAt(112, 112); len(objects) != 1 || objects[0] != spawn {
+		t.Errorf("objects at the spawn's new position are %v", objects)
+	}
+}
@@ -0,0 +1,48 @@
+package tiled
+
+import "sync"
+
+// ======================================================
+// Observers
+// ======================================================
+
+// Observer is told about changes to loaded maps and files, so state built from them elsewhere, such as
+// the tiles and GPU buffers of package render, follows along. Observers are called synchronously from
+// the goroutine making the change.
+type Observer interface {
+	// LayerCopied reports that dst was copied from src, such as for a map instance.
+	LayerCopied(src, dst *Layer)
+
+	// LayerChanged reports that cells of the layer changed. A nil slice means all of its cells were
+	// replaced or released.
+	LayerChanged(layer *Layer, cells []Cell)
+
+	// MapReleased reports that the map was released with Release.
+	MapReleased(tmx *TMX)
+
+	// FileReleased reports that a file loaded from a file system was dropped with ReleaseFS. The file
+	// is a *TSX, a *TX or an image.Image.
+	FileReleased(path string, file any)
+}
+
+var (
+	observers      []Observer
+	observersMutex sync.RWMutex
+)
+
+// RegisterObserver adds o to the observers told about changes to maps and files.
+func RegisterObserver(o Observer) {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+	observers = append(observers, o)
+}
+
+func notifyObservers(fn func(Observer)) {
+	observersMutex.RLock()
+	registered := observers
+	observersMutex.RUnlock()
+
+	for _, o := range registered {
+		fn(o)
+	}
+}
@@ -0,0 +1,109 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Tile Patches
+// ======================================================
+
+// TilePatch is a rectangular block of cells copied out of a tile layer, stored row by row as raw
+// cell data: GIDs with flip flags. Empty cells hold zero.
+type TilePatch struct {
+	Width, Height int
+	Data          []uint32
+}
+
+func (patch *TilePatch) At(x, y int) uint32 {
+	return patch.Data[y*patch.Width+x]
+}
+
+// Remap returns a copy of the patch with its GIDs rewritten from the tilesets of the map it was
+// copied from to the tilesets of the map it will be pasted into, keeping flip flags.
+// Tilesets are matched by source; a tile whose tileset the target map lacks is an error.
+func (patch *TilePatch) Remap(from, to []*Tileset) (*TilePatch, error) {
+	out := &TilePatch{Width: patch.Width, Height: patch.Height, Data: make([]uint32, len(patch.Data))}
+
+	for i, data := range patch.Data {
+		key, ok := TileKeyOf(data, from)
+		if !ok {
+			continue
+		}
+		gid, ok := gidOf(key, to)
+		if !ok {
+			return nil, fmt.Errorf("tileset not found in target map: %s", key.Source)
+		}
+		out.Data[i] = gid | data&^TILE_ID_MASK
+	}
+
+	return out, nil
+}
+
+// CopyRegion copies the cells of the layer inside the region, expressed in tiles, into a patch.
+// Cells outside the layer, or outside every chunk of an infinite layer, are copied as empty.
+func (layer *Layer) CopyRegion(region geom.Rect64) (*TilePatch, error) {
+	x0 := int(math.Floor(region.X))
+	y0 := int(math.Floor(region.Y))
+	w := int(math.Ceil(region.X+region.Width)) - x0
+	h := int(math.Ceil(region.Y+region.Height)) - y0
+
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid copy region: %v", region)
+	}
+
+	patch := &TilePatch{Width: w, Height: h, Data: make([]uint32, w*h)}
+	for y := range h {
+		for x := range w {
+			data, err := layer.cellAt(x0+x, y0+y)
+			if err != nil {
+				return nil, err
+			}
+			patch.Data[y*w+x] = data
+		}
+	}
+
+	return patch, nil
+}
+
+// PasteRegion stamps the patch into the layer with its top-left cell at the given tile coordinates.
+// Empty cells of the patch leave the layer untouched. Cells that fall outside a finite layer are
+// dropped; infinite layers gain chunks to hold them, like SetTileGID.
+func (layer *Layer) PasteRegion(patch *TilePatch, x, y int) error {
+	cells, err := layer.pasteCells(patch, x, y)
+	if err != nil || len(cells) == 0 {
+		return err
+	}
+	return layer.setCells(cells)
+}
+
+// pasteCells returns the cells a paste would change, adding chunks to infinite layers as needed.
+func (layer *Layer) pasteCells(patch *TilePatch, x, y int) (map[Cell]uint32, error) {
+	if layer.Data == nil {
+		return nil, fmt.Errorf("layer %s has no data", layer.Name())
+	}
+
+	infinite := len(layer.Data.Chunks) > 0
+
+	cells := make(map[Cell]uint32)
+	for py := range patch.Height {
+		for px := range patch.Width {
+			data := patch.At(px, py)
+			if data == 0 {
+				continue
+			}
+			cell := Cell{X: x + px, Y: y + py}
+			if infinite {
+				if err := layer.ensureCell(cell.X, cell.Y); err != nil {
+					return nil, err
+				}
+			}
+			cells[cell] = data
+		}
+	}
+
+	return cells, nil
+}
@@ -0,0 +1,159 @@
+package tiled
+
+import (
+	"container/heap"
+	"math"
+)
+
+// ======================================================
+// Pathfinding
+// ======================================================
+
+// PathOptions controls how FindPath searches a walkability grid.
+type PathOptions struct {
+	// Diagonal allows 8-connected movement. By default paths are 4-connected.
+	Diagonal bool
+
+	// DiagonalCost scales the cost of diagonal steps. Zero uses the Euclidean cost of √2.
+	DiagonalCost float64
+
+	// CutCorners allows diagonal steps between two blocked orthogonal neighbours.
+	CutCorners bool
+
+	// MaxNodes stops the search after expanding this many cells. Zero searches the whole grid.
+	MaxNodes int
+}
+
+// FindPath returns the cheapest path between two cells of a walkability grid using A*.
+// The path includes both the start and goal cells. It returns false if the goal can't be reached.
+func FindPath(grid *WalkabilityGrid, from, to Cell, opts PathOptions) ([]Cell, bool) {
+	if !grid.IsWalkable(from.X, from.Y) || !grid.IsWalkable(to.X, to.Y) {
+		return nil, false
+	}
+	if from == to {
+		return []Cell{from}, true
+	}
+
+	diagonalCost := opts.DiagonalCost
+	if diagonalCost <= 0 {
+		diagonalCost = math.Sqrt2
+	}
+
+	heuristic := func(c Cell) float64 {
+		dx, dy := math.Abs(float64(c.X-to.X)), math.Abs(float64(c.Y-to.Y))
+		if !opts.Diagonal {
+			return dx + dy
+		}
+		// Octile distance, which stays admissible for any diagonal cost.
+		return math.Max(dx, dy) + (math.Min(diagonalCost, 2)-1)*math.Min(dx, dy)
+	}
+
+	index := func(c Cell) int {
+		return (c.Y-grid.Y)*grid.Width + (c.X - grid.X)
+	}
+
+	costs := make(map[int]float64)
+	parents := make(map[int]Cell)
+	closed := make(map[int]bool)
+
+	open := &pathQueue{}
+	heap.Push(open, pathNode{cell: from, priority: heuristic(from)})
+	costs[index(from)] = 0
+
+	expanded := 0
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathNode).cell
+		ci := index(current)
+
+		if current == to {
+			return buildPath(parents, from, to, index), true
+		}
+		if closed[ci] {
+			continue
+		}
+		closed[ci] = true
+
+		if expanded++; opts.MaxNodes > 0 && expanded > opts.MaxNodes {
+			return nil, false
+		}
+
+		for _, step := range pathSteps {
+			diagonal := step.X != 0 && step.Y != 0
+			if diagonal && !opts.Diagonal {
+				continue
+			}
+
+			next := Cell{X: current.X + step.X, Y: current.Y + step.Y}
+			if !grid.IsWalkable(next.X, next.Y) {
+				continue
+			}
+
+			stepCost := 1.0
+			if diagonal {
+				sideX := grid.IsWalkable(current.X+step.X, current.Y)
+				sideY := grid.IsWalkable(current.X, current.Y+step.Y)
+				if !opts.CutCorners && (!sideX || !sideY) {
+					continue
+				}
+				stepCost = diagonalCost
+			}
+
+			ni := index(next)
+			if closed[ni] {
+				continue
+			}
+
+			cost := costs[ci] + stepCost*grid.Cost(next.X, next.Y)
+			if known, exists := costs[ni]; exists && known <= cost {
+				continue
+			}
+
+			costs[ni] = cost
+			parents[ni] = current
+			heap.Push(open, pathNode{cell: next, priority: cost + heuristic(next)})
+		}
+	}
+
+	return nil, false
+}
+
+var pathSteps = []Cell{
+	{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1},
+	{X: 1, Y: 1}, {X: -1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: -1},
+}
+
+func buildPath(parents map[int]Cell, from, to Cell, index func(Cell) int) []Cell {
+	path := []Cell{to}
+	for current := to; current != from; {
+		current = parents[index(current)]
+		path = append(path, current)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type pathNode struct {
+	cell     Cell
+	priority float64
+}
+
+// pathQueue is a min-heap of nodes ordered by priority.
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x any) {
+	*q = append(*q, x.(pathNode))
+}
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
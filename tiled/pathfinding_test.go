@@ -0,0 +1,50 @@
+package tiled
+
+import "testing"
+
+func TestFindPath(t *testing.T) {
+	tmx := loadFixture(t, "ortho_csv.tmx")
+	grid, err := BuildWalkabilityGrid(tmx, "walls", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The wall at 4,1-4,2 forces the path down through the gap in row 3.
+	from, to := Cell{X: 1, Y: 1}, Cell{X: 6, Y: 1}
+	path, ok := FindPath(grid, from, to, PathOptions{})
+	if !ok {
+		t.Fatal("no path found")
+	}
+	if len(path) != 10 {
+		t.Fatalf("path has %d cells, want 10: %v", len(path), path)
+	}
+	if path[0] != from || path[len(path)-1] != to {
+		t.Fatalf("path runs from %v to %v, want %v to %v", path[0], path[len(path)-1], from, to)
+	}
+	for i, cell := range path {
+		if !grid.IsWalkable(cell.X, cell.Y) {
+			t.Errorf("path crosses blocked cell %v", cell)
+		}
+		if i > 0 && abs(cell.X-path[i-1].X)+abs(cell.Y-path[i-1].Y) != 1 {
+			t.Errorf("step from %v to %v is not to a 4-connected neighbour", path[i-1], cell)
+		}
+	}
+}
+
+func TestFindPathUnreachable(t *testing.T) {
+	tmx := loadFixture(t, "ortho_csv.tmx")
+	grid, err := BuildWalkabilityGrid(tmx, "walls", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := FindPath(grid, Cell{X: 1, Y: 1}, Cell{X: 4, Y: 1}, PathOptions{}); ok {
+		t.Error("found a path into a wall")
+	}
+	if _, ok := FindPath(grid, Cell{X: 1, Y: 1}, Cell{X: 6, Y: 1}, PathOptions{MaxNodes: 3}); ok {
+		t.Error("found a path longer than the node limit allows")
+	}
+	if path, ok := FindPath(grid, Cell{X: 1, Y: 1}, Cell{X: 1, Y: 1}, PathOptions{}); !ok || len(path) != 1 {
+		t.Errorf("path to the start cell is %v, want only the start cell", path)
+	}
+}
@@ -0,0 +1,102 @@
+package tiled
+
+import (
+	"math"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Platformer Controller
+// ======================================================
+
+// PlatformerInput is the player intent fed to a PlatformerController each update.
+type PlatformerInput struct {
+	// Move is the horizontal intent, from -1 (left) to 1 (right).
+	Move float64
+
+	// Jump starts a jump when the body is on the ground.
+	Jump bool
+
+	// Drop falls through one-way platforms the body is standing on.
+	Drop bool
+}
+
+// PlatformerController is a reference kinematic controller for side-on games, built on CollisionGrid.
+// It applies gravity, jumping, one-way platforms and slope walking, and can be used as-is or as a
+// starting point for a game specific controller. Units are world units and seconds.
+type PlatformerController struct {
+	Grid *CollisionGrid
+
+	// Rect is the body's bounds in world space.
+	Rect geom.Rect64
+
+	// Velocity is the body's current velocity in world units per second.
+	Velocity geom.Point64
+
+	Gravity      float64
+	MaxFallSpeed float64
+	MoveSpeed    float64
+	JumpSpeed    float64
+
+	// OnGround reports whether the body ended its last update standing on something.
+	OnGround bool
+
+	// OnSlope reports whether the body ended its last update standing on a slope.
+	OnSlope bool
+}
+
+// NewPlatformerController creates a controller with defaults tuned for the grid's cell size:
+// roughly three cell jumps and eight cells per second of running.
+func NewPlatformerController(grid *CollisionGrid, rect geom.Rect64) *PlatformerController {
+	_, cellHeight := grid.CellSize()
+	ch := float64(cellHeight)
+
+	return &PlatformerController{
+		Grid:         grid,
+		Rect:         rect,
+		Gravity:      ch * 60,
+		MaxFallSpeed: ch * 30,
+		MoveSpeed:    ch * 8,
+		JumpSpeed:    ch * 19,
+	}
+}
+
+// Update advances the controller by dt and returns the collision result of the move.
+func (pc *PlatformerController) Update(input PlatformerInput, dt time.Duration) (MoveResult, error) {
+	seconds := dt.Seconds()
+
+	pc.Velocity.X = math.Max(-1, math.Min(1, input.Move)) * pc.MoveSpeed
+
+	jumping := false
+	if pc.OnGround && input.Jump {
+		pc.Velocity.Y = -pc.JumpSpeed
+		jumping = true
+	}
+
+	pc.Velocity.Y = math.Min(pc.Velocity.Y+pc.Gravity*seconds, pc.MaxFallSpeed)
+
+	delta := geom.NewPoint64(pc.Velocity.X*seconds, pc.Velocity.Y*seconds)
+
+	result, err := pc.Grid.MoveAndCollideWithOptions(pc.Rect, delta, MoveOptions{
+		IgnoreOneWay: input.Drop,
+		NoSlopeSnap:  jumping || !pc.OnGround,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	pc.Rect = result.Rect
+	pc.OnSlope = result.OnSlope
+	pc.OnGround = result.HasContact(geom.NewPoint64(0, -1))
+
+	if pc.OnGround && pc.Velocity.Y > 0 {
+		pc.Velocity.Y = 0
+	}
+	if result.HasContact(geom.NewPoint64(0, 1)) && pc.Velocity.Y < 0 {
+		pc.Velocity.Y = 0
+	}
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package tiled
+
+import (
+	"slices"
+	"strings"
+)
+
+// ======================================================
+// Property Inheritance
+// ======================================================
+
+// ClassDefaultsFunc returns the default members of a custom class, or nil if the class is unknown.
+type ClassDefaultsFunc func(class string) []*Property
+
+var classDefaults ClassDefaultsFunc
+
+// SetClassDefaults configures where class defaults are looked up when resolving inherited properties,
+// typically a loaded Tiled project. Passing nil disables class defaults.
+func SetClassDefaults(fn ClassDefaultsFunc) {
+	classDefaults = fn
+}
+
+// ResolveProperty returns the effective value of an object's property as Tiled displays it:
+// the object's own value, then its template's, then its tile's for tile objects, then its
+// tileset's, and finally the default of the object's class.
+func (tmx TMX) ResolveProperty(obj *Object, name string) (*Property, bool) {
+	for _, props := range tmx.propertyChain(obj) {
+		if prop := findProperty(props, name); prop != nil {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+// ResolvedProperties returns every effective property of an object, sorted by name,
+// with each taken from the first source in the inheritance chain that sets it.
+func (tmx TMX) ResolvedProperties(obj *Object) []*Property {
+	var resolved []*Property
+	seen := make(map[string]bool)
+	for _, props := range tmx.propertyChain(obj) {
+		for _, prop := range props {
+			if !seen[prop.Name()] {
+				seen[prop.Name()] = true
+				resolved = append(resolved, prop)
+			}
+		}
+	}
+	slices.SortFunc(resolved, func(a, b *Property) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return resolved
+}
+
+// propertyChain returns the property lists an object inherits from, most specific first.
+func (tmx TMX) propertyChain(obj *Object) [][]*Property {
+	chain := [][]*Property{obj.Properties}
+	class := obj.Class()
+
+	gid, tilesets := obj.GID(), tmx.Tilesets
+	if obj.HasTemplate() {
+		if tx, err := obj.TX(); err == nil && tx.Object != nil {
+			chain = append(chain, tx.Object.Properties)
+			if class == "" {
+				class = tx.Object.Class()
+			}
+			// A template's tile references the template's own tileset.
+			if gid == 0 && tx.Tileset != nil {
+				gid, tilesets = tx.Object.GID(), []*Tileset{tx.Tileset}
+			}
+		}
+	}
+
+	if key, ok := TileKeyOf(uint32(gid), tilesets); ok {
+		if tsx, _, err := resolveTileKey(key, tilesets); err == nil {
+			if tile := tsx.Tile(key.ID); tile != nil {
+				chain = append(chain, tile.Properties)
+				if class == "" {
+					class = tile.Class()
+				}
+			}
+			chain = append(chain, tsx.Properties)
+		}
+	}
+
+	if class != "" && classDefaults != nil {
+		chain = append(chain, classDefaults(class))
+	}
+
+	return chain
+}
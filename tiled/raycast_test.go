@@ -0,0 +1,45 @@
+package tiled
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+func TestRaycast(t *testing.T) {
+	tmx := loadFixture(t, "ortho_gzip.tmx")
+
+	for _, c := range []struct {
+		name     string
+		from, to geom.Point64
+		hit      bool
+		cell     Cell
+		point    geom.Point64
+		normal   geom.Point64
+		distance float64
+	}{
+		{"east", geom.NewPoint64(24, 24), geom.NewPoint64(104, 24), true, Cell{X: 4, Y: 1}, geom.NewPoint64(64, 24), geom.NewPoint64(-1, 0), 40},
+		{"south onto a flipped tile", geom.NewPoint64(24, 24), geom.NewPoint64(24, 88), true, Cell{X: 1, Y: 5}, geom.NewPoint64(24, 80), geom.NewPoint64(0, -1), 56},
+		{"short of the wall", geom.NewPoint64(24, 24), geom.NewPoint64(56, 24), false, Cell{}, geom.Point64{}, geom.Point64{}, 0},
+		// The ray passes exactly through the corner at 32,32 and steps down before it steps right.
+		{"through a corner", geom.NewPoint64(20, 20), geom.NewPoint64(60, 60), true, Cell{X: 2, Y: 2}, geom.NewPoint64(32, 32), geom.NewPoint64(-1, 0), math.Hypot(12, 12)},
+		{"from inside a wall", geom.NewPoint64(8, 8), geom.NewPoint64(40, 40), true, Cell{X: 0, Y: 0}, geom.NewPoint64(8, 8), geom.Point64{}, 0},
+	} {
+		hit, ok, err := Raycast(tmx, "walls", c.from, c.to)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if ok != c.hit {
+			t.Errorf("%s: hit=%v, want %v", c.name, ok, c.hit)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if hit.Cell != c.cell || hit.Point != c.point || hit.Normal != c.normal || math.Abs(hit.Distance-c.distance) > 1e-9 {
+			t.Errorf("%s: hit %v at %v normal %v distance %v, want %v at %v normal %v distance %v",
+				c.name, hit.Cell, hit.Point, hit.Normal, hit.Distance, c.cell, c.point, c.normal, c.distance)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package tiled
+
+// ======================================================
+// Releasing Resources
+// ======================================================
+
+// Release frees what was decoded from the map: the cells of every layer, and whatever observers built
+// from them, such as the tiles and static buffers of package render. The map stays usable; layers are
+// decoded again when next needed.
+func (tmx *TMX) Release() {
+	for _, layer := range tmx.Layers {
+		layer.invalidate()
+	}
+	notifyObservers(func(o Observer) { o.MapReleased(tmx) })
+}
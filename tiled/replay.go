@@ -0,0 +1,213 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Replays
+// ======================================================
+
+// ReplayVersion is the version of the replay format written by this package.
+const ReplayVersion = 1
+
+// Replay is a recording of how a map instance was viewed and changed over time,
+// captured to reproduce rendering and culling issues.
+type Replay struct {
+	Version int `json:"version"`
+
+	// Initial is the instance's state when recording started.
+	Initial *MapSave `json:"initial"`
+
+	Frames []ReplayFrame `json:"frames"`
+}
+
+// ReplayFrame is a single recorded frame.
+type ReplayFrame struct {
+	// At is the time since recording started.
+	At time.Duration `json:"at"`
+
+	// Viewport and View are the camera the frame was drawn with.
+	Viewport geom.Rect64 `json:"viewport"`
+	View     [6]float64  `json:"view"`
+
+	// Chunks lists the origins of the decoded blocks of cells the viewport touched, per layer.
+	Chunks map[string][]Cell `json:"chunks,omitempty"`
+
+	// Changes lists the cells changed through the instance since the previous frame.
+	Changes []ReplayChange `json:"changes,omitempty"`
+}
+
+// ReplayChange is a cell change captured while recording.
+type ReplayChange struct {
+	Layer string `json:"layer"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Data  uint32 `json:"data"`
+}
+
+// WriteReplay encodes a replay as JSON.
+func WriteReplay(w io.Writer, replay *Replay) error {
+	return json.NewEncoder(w).Encode(replay)
+}
+
+// ReadReplay decodes a replay written by WriteReplay.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	var replay Replay
+	if err := json.NewDecoder(r).Decode(&replay); err != nil {
+		return nil, err
+	}
+	if replay.Version < 1 || replay.Initial == nil {
+		return nil, fmt.Errorf("invalid replay")
+	}
+	return &replay, nil
+}
+
+// ======================================================
+// Replay Recorder
+// ======================================================
+
+// ReplayRecorder captures frames of a map instance into a Replay.
+type ReplayRecorder struct {
+	inst    *MapInstance
+	replay  *Replay
+	pending []ReplayChange
+	elapsed time.Duration
+}
+
+// RecordReplay starts recording the instance. Only one recorder can be attached to an instance;
+// starting a new one replaces the previous one.
+func RecordReplay(inst *MapInstance, mapPath string) *ReplayRecorder {
+	rec := &ReplayRecorder{
+		inst: inst,
+		replay: &Replay{
+			Version: ReplayVersion,
+			Initial: inst.Save(mapPath),
+		},
+	}
+	inst.recorder = rec
+	return rec
+}
+
+// Frame records a frame drawn with the provided camera, dt after the previous frame. The view holds
+// the first two rows of the view matrix, row by row; package render's RecordFrame fills it from a GeoM.
+func (rec *ReplayRecorder) Frame(dt time.Duration, viewport geom.Rect64, view [6]float64) error {
+	rec.elapsed += dt
+
+	frame := ReplayFrame{
+		At:       rec.elapsed,
+		Viewport: viewport,
+		View:     view,
+		Changes:  rec.pending,
+	}
+
+	cw, ch := float64(rec.inst.TMX.TileWidth()), float64(rec.inst.TMX.TileHeight())
+	for _, layer := range rec.inst.TMX.Layers {
+		grids, err := layer.cellGrids()
+		if err != nil {
+			return fmt.Errorf("failed to record layer %s: %w", layer.Name(), err)
+		}
+		for _, g := range grids {
+			bounds := geom.NewRect64(float64(g.x)*cw, float64(g.y)*ch, float64(g.width)*cw, float64(g.height)*ch)
+			if !bounds.Intersects(viewport) {
+				continue
+			}
+			if frame.Chunks == nil {
+				frame.Chunks = make(map[string][]Cell)
+			}
+			frame.Chunks[layer.Name()] = append(frame.Chunks[layer.Name()], Cell{X: g.x, Y: g.y})
+		}
+	}
+
+	rec.replay.Frames = append(rec.replay.Frames, frame)
+	rec.pending = nil
+	return nil
+}
+
+// Stop detaches the recorder from the instance and returns the recorded replay.
+func (rec *ReplayRecorder) Stop() *Replay {
+	if rec.inst.recorder == rec {
+		rec.inst.recorder = nil
+	}
+	return rec.replay
+}
+
+// record captures cells changed through the instance for the next frame.
+func (rec *ReplayRecorder) record(layerName string, cells map[Cell]uint32) {
+	changes := make([]ReplayChange, 0, len(cells))
+	for cell, data := range cells {
+		changes = append(changes, ReplayChange{Layer: layerName, X: cell.X, Y: cell.Y, Data: data})
+	}
+	slices.SortFunc(changes, func(a, b ReplayChange) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	rec.pending = append(rec.pending, changes...)
+}
+
+// ======================================================
+// Replay Player
+// ======================================================
+
+// ReplayPlayer re-renders a replay onto a map instance.
+type ReplayPlayer struct {
+	inst   *MapInstance
+	replay *Replay
+	next   int
+}
+
+// NewReplayPlayer restores the replay's initial state onto the instance and prepares it for playback.
+// The instance should be created from a freshly loaded copy of the recorded map.
+func NewReplayPlayer(inst *MapInstance, replay *Replay) (*ReplayPlayer, error) {
+	if err := inst.Restore(replay.Initial); err != nil {
+		return nil, err
+	}
+	return &ReplayPlayer{inst: inst, replay: replay}, nil
+}
+
+// Done reports whether every frame has been played.
+func (p *ReplayPlayer) Done() bool {
+	return p.next >= len(p.replay.Frames)
+}
+
+// Step applies the changes of the next frame and returns it.
+func (p *ReplayPlayer) Step() (ReplayFrame, error) {
+	if p.Done() {
+		return ReplayFrame{}, io.EOF
+	}
+
+	frame := p.replay.Frames[p.next]
+	p.next++
+
+	changes := make(map[string]map[Cell]uint32)
+	for _, c := range frame.Changes {
+		if changes[c.Layer] == nil {
+			changes[c.Layer] = make(map[Cell]uint32)
+		}
+		changes[c.Layer][Cell{X: c.X, Y: c.Y}] = c.Data
+	}
+	for layerName, cells := range changes {
+		layer := p.inst.TMX.LayerByName(layerName)
+		if layer == nil {
+			return frame, fmt.Errorf("layer not found: %s", layerName)
+		}
+		if err := p.inst.setLayerCells(layer, cells); err != nil {
+			return frame, err
+		}
+	}
+
+	return frame, nil
+}
+
+// Instance returns the instance the replay plays onto.
+func (p *ReplayPlayer) Instance() *MapInstance {
+	return p.inst
+}
@@ -0,0 +1,198 @@
+package tiled
+
+import (
+	"fmt"
+
+	"github.com/adm87/finch-core/enum"
+)
+
+// ======================================================
+// Anchor
+// ======================================================
+
+type Anchor int
+
+const (
+	AnchorTopLeft Anchor = iota
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorCenter
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+)
+
+func (a Anchor) String() string {
+	switch a {
+	case AnchorTopLeft:
+		return "topleft"
+	case AnchorTop:
+		return "top"
+	case AnchorTopRight:
+		return "topright"
+	case AnchorLeft:
+		return "left"
+	case AnchorCenter:
+		return "center"
+	case AnchorRight:
+		return "right"
+	case AnchorBottomLeft:
+		return "bottomleft"
+	case AnchorBottom:
+		return "bottom"
+	case AnchorBottomRight:
+		return "bottomright"
+	default:
+		return "unknown"
+	}
+}
+
+func (a Anchor) IsValid() bool {
+	return a >= AnchorTopLeft && a <= AnchorBottomRight
+}
+
+func (a Anchor) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(a)
+}
+
+func (a *Anchor) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[Anchor](data)
+	if err != nil {
+		return err
+	}
+	*a = val
+	return nil
+}
+
+// offset returns how far existing content moves when a w by h area is resized to newW by newH.
+func (a Anchor) offset(w, h, newW, newH int) (int, int) {
+	dx, dy := 0, 0
+
+	switch a {
+	case AnchorTop, AnchorCenter, AnchorBottom:
+		dx = (newW - w) / 2
+	case AnchorTopRight, AnchorRight, AnchorBottomRight:
+		dx = newW - w
+	}
+
+	switch a {
+	case AnchorLeft, AnchorCenter, AnchorRight:
+		dy = (newH - h) / 2
+	case AnchorBottomLeft, AnchorBottom, AnchorBottomRight:
+		dy = newH - h
+	}
+
+	return dx, dy
+}
+
+// ======================================================
+// Resizing
+// ======================================================
+
+// Resize grows or shrinks every tile layer of a finite map to newW by newH tiles, keeping the
+// existing content pinned to the anchor. Cells that did not exist before are filled with fill,
+// and objects are shifted by the same amount as the tiles.
+func Resize(tmx *TMX, newW, newH int, anchor Anchor, fill uint32) error {
+	if tmx.IsInfinite() {
+		return fmt.Errorf("cannot resize an infinite map")
+	}
+	if newW <= 0 || newH <= 0 {
+		return fmt.Errorf("invalid map size: %dx%d", newW, newH)
+	}
+	if !anchor.IsValid() {
+		return fmt.Errorf("invalid anchor: %d", anchor)
+	}
+
+	dx, dy := anchor.offset(tmx.Width(), tmx.Height(), newW, newH)
+
+	// Every layer is resized before any is changed, so a layer that fails leaves the map as it was.
+	resized := make([]layerCells, 0, len(tmx.Layers))
+	for _, layer := range tmx.Layers {
+		cells, err := layer.resized(newW, newH, dx, dy, fill)
+		if err != nil {
+			return fmt.Errorf("failed to resize layer %s: %w", layer.Name(), err)
+		}
+		resized = append(resized, cells)
+	}
+	for _, cells := range resized {
+		cells.apply()
+	}
+
+	shiftObjects(tmx, dx*tmx.TileWidth(), dy*tmx.TileHeight())
+
+	tmx.Attrs[WidthAttr] = AttrInt(newW)
+	tmx.Attrs[HeightAttr] = AttrInt(newH)
+
+	return nil
+}
+
+// Resize resizes the map like the package-level Resize, filling new cells with empty tiles.
+func (tmx *TMX) Resize(newW, newH int, anchor Anchor) error {
+	return Resize(tmx, newW, newH, anchor, 0)
+}
+
+// Resize grows or shrinks a single tile layer of a finite map to newW by newH tiles, keeping its
+// content pinned to the anchor and filling new cells with fill. The layer keeps its data format.
+// Objects and the map's size are left alone; resize the map to move everything together.
+func (layer *Layer) Resize(newW, newH int, anchor Anchor, fill uint32) error {
+	if newW <= 0 || newH <= 0 {
+		return fmt.Errorf("invalid layer size: %dx%d", newW, newH)
+	}
+	if !anchor.IsValid() {
+		return fmt.Errorf("invalid anchor: %d", anchor)
+	}
+	if layer.Data != nil && len(layer.Data.Chunks) > 0 {
+		return fmt.Errorf("cannot resize an infinite layer")
+	}
+
+	dx, dy := anchor.offset(layer.Width(), layer.Height(), newW, newH)
+	cells, err := layer.resized(newW, newH, dx, dy, fill)
+	if err != nil {
+		return err
+	}
+	cells.apply()
+	return nil
+}
+
+// resized returns the layer's cells rebuilt as newW by newH tiles with its existing cells moved by
+// dx, dy, encoded in the layer's format but not yet applied.
+func (layer *Layer) resized(newW, newH, dx, dy int, fill uint32) (layerCells, error) {
+	w, h := layer.Width(), layer.Height()
+
+	format := DataFormatCSV
+	if layer.Data != nil {
+		format = layer.Data.Format()
+	}
+
+	resized := make([]uint32, newW*newH)
+	for y := 0; y < newH; y++ {
+		for x := 0; x < newW; x++ {
+			if ox, oy := x-dx, y-dy; ox < 0 || ox >= w || oy < 0 || oy >= h {
+				resized[y*newW+x] = fill
+			}
+		}
+	}
+
+	err := forEachCell(layer, func(x, y int, data uint32) {
+		if nx, ny := x+dx, y+dy; nx >= 0 && nx < newW && ny >= 0 && ny < newH {
+			resized[ny*newW+nx] = data
+		}
+	})
+	if err != nil {
+		return layerCells{}, err
+	}
+
+	return encodeLayerCells(layer, resized, newW, newH, format)
+}
+
+func shiftObjects(tmx *TMX, dx, dy int) {
+	for _, og := range tmx.ObjectGroups {
+		for _, obj := range og.Objects {
+			obj.Attrs[XAttr] = numberAttr(obj.X64() + float64(dx))
+			obj.Attrs[YAttr] = numberAttr(obj.Y64() + float64(dy))
+		}
+	}
+	tmx.objects = nil
+}
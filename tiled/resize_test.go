@@ -0,0 +1,26 @@
+package tiled
+
+import "testing"
+
+func TestResizeFailureLeavesMapUntouched(t *testing.T) {
+	// The ground layer resizes cleanly, but the broken layer's data can't be decoded.
+	tmx := loadTestMap(t, "resize/broken_layer.tmx")
+	ground := tmx.LayerByName("ground")
+
+	if err := Resize(tmx, 4, 4, AnchorBottomRight, 0); err == nil {
+		t.Fatal("resizing a map with an undecodable layer succeeded")
+	}
+
+	if tmx.Width() != 2 || tmx.Height() != 2 {
+		t.Errorf("failed resize changed the map to %dx%d", tmx.Width(), tmx.Height())
+	}
+	if ground.Width() != 2 || ground.Height() != 2 {
+		t.Errorf("failed resize changed ground to %dx%d", ground.Width(), ground.Height())
+	}
+	if gid, _ := ground.GetTileGID(1, 1); gid != 4 {
+		t.Errorf("ground cell 1,1 is %d after a failed resize, want 4", gid)
+	}
+	if spawn := tmx.ObjectGroups[0].Objects[0]; spawn.X64() != 8 || spawn.Y64() != 8 {
+		t.Errorf("failed resize moved the spawn to %v,%v", spawn.X64(), spawn.Y64())
+	}
+}
@@ -0,0 +1,209 @@
+package tiled
+
+import (
+	"fmt"
+	"time"
+)
+
+// ======================================================
+// Tile Rewind
+// ======================================================
+
+// TileDelta records a single cell change made through a MapInstance.
+type TileDelta struct {
+	Layer  string
+	Cell   Cell
+	Before uint32
+	After  uint32
+
+	// At is the instance clock when the change was made.
+	At time.Duration
+}
+
+// rewindBuffer is a fixed-capacity ring of the most recent tile deltas.
+type rewindBuffer struct {
+	deltas []TileDelta
+	start  int
+	count  int
+
+	// horizon is the time of the newest delta dropped from the buffer; rewinding past it isn't possible.
+	horizon time.Duration
+	dropped bool
+}
+
+func (rb *rewindBuffer) push(delta TileDelta) {
+	if len(rb.deltas) == 0 {
+		return
+	}
+	if rb.count == len(rb.deltas) {
+		rb.horizon = rb.deltas[rb.start].At
+		rb.dropped = true
+		rb.deltas[rb.start] = delta
+		rb.start = (rb.start + 1) % len(rb.deltas)
+		return
+	}
+	rb.deltas[(rb.start+rb.count)%len(rb.deltas)] = delta
+	rb.count++
+}
+
+// newest returns the i-th most recent delta.
+func (rb *rewindBuffer) newest(i int) TileDelta {
+	return rb.deltas[(rb.start+rb.count-1-i)%len(rb.deltas)]
+}
+
+// EnableRewind keeps the last capacity tile changes made through the instance so they can be undone
+// with Rewind. Passing zero disables rewinding and discards the recorded changes.
+func (inst *MapInstance) EnableRewind(capacity int) {
+	if capacity <= 0 {
+		inst.rewind = nil
+		return
+	}
+	inst.rewind = &rewindBuffer{deltas: make([]TileDelta, capacity)}
+}
+
+// Clock returns how much time the instance has been updated for.
+func (inst *MapInstance) Clock() time.Duration {
+	return inst.clock
+}
+
+// Rewind undoes every recorded tile change made after t and moves the instance clock back to t.
+// Only tiles are rewound; timelines and decals carry on from where they were.
+// Rewinding further back than the oldest retained change fails without changing anything.
+func (inst *MapInstance) Rewind(t time.Duration) error {
+	rb := inst.rewind
+	if rb == nil {
+		return fmt.Errorf("rewind is not enabled")
+	}
+	if rb.dropped && t < rb.horizon {
+		return fmt.Errorf("cannot rewind to %v, history only reaches back to %v", t, rb.horizon)
+	}
+
+	// Walking from newest to oldest leaves each cell with the value it had before the earliest undone change.
+	undo := make(map[string]map[Cell]uint32)
+	n := 0
+	for ; n < rb.count; n++ {
+		delta := rb.newest(n)
+		if delta.At <= t {
+			break
+		}
+		cells, exists := undo[delta.Layer]
+		if !exists {
+			cells = make(map[Cell]uint32)
+			undo[delta.Layer] = cells
+		}
+		cells[delta.Cell] = delta.Before
+	}
+
+	for layerName, cells := range undo {
+		layer := inst.TMX.LayerByName(layerName)
+		if layer == nil {
+			return fmt.Errorf("layer not found: %s", layerName)
+		}
+		if err := inst.setLayerCells(layer, cells); err != nil {
+			return fmt.Errorf("failed to rewind layer %s: %w", layerName, err)
+		}
+	}
+
+	rb.count -= n
+	if t < inst.clock {
+		inst.clock = t
+	}
+	return nil
+}
+
+// writeCells changes cells of a layer of the instance's map, recording the change when rewind is enabled.
+func (inst *MapInstance) writeCells(layer *Layer, cells map[Cell]uint32) error {
+	var deltas []TileDelta
+	if inst.rewind != nil {
+		deltas = make([]TileDelta, 0, len(cells))
+		for cell, after := range cells {
+			before, err := layer.cellAt(cell.X, cell.Y)
+			if err != nil {
+				return err
+			}
+			deltas = append(deltas, TileDelta{Layer: layer.Name(), Cell: cell, Before: before, After: after, At: inst.clock})
+		}
+	}
+
+	if err := inst.setLayerCells(layer, cells); err != nil {
+		return err
+	}
+
+	for _, delta := range deltas {
+		inst.rewind.push(delta)
+	}
+	return nil
+}
+
+// setLayerCells changes cells of a layer of the instance's map and remembers their new values,
+// so the instance's state can be saved and recorded.
+func (inst *MapInstance) setLayerCells(layer *Layer, cells map[Cell]uint32) error {
+	if err := layer.setCells(cells); err != nil {
+		return err
+	}
+	if inst.edits == nil {
+		inst.edits = make(map[string]map[Cell]uint32)
+	}
+	edits, exists := inst.edits[layer.Name()]
+	if !exists {
+		edits = make(map[Cell]uint32)
+		inst.edits[layer.Name()] = edits
+	}
+	for cell, data := range cells {
+		edits[cell] = data
+	}
+	if inst.recorder != nil {
+		inst.recorder.record(layer.Name(), cells)
+	}
+	return nil
+}
+
+// SetTileGID changes a cell of a layer of the instance's map like Layer.SetTileGID, recording the change
+// for rewinding, saving and replays.
+func (inst *MapInstance) SetTileGID(layerName string, x, y int, gid uint32) error {
+	layer := inst.TMX.LayerByName(layerName)
+	if layer == nil {
+		return fmt.Errorf("layer not found: %s", layerName)
+	}
+	if err := layer.ensureCell(x, y); err != nil {
+		return err
+	}
+	return inst.writeCells(layer, map[Cell]uint32{{X: x, Y: y}: gid})
+}
+
+// FloodFill fills a region of a layer of the instance's map like Layer.FloodFill, recording the change
+// for rewinding, saving and replays.
+func (inst *MapInstance) FloodFill(layerName string, x, y int, gid uint32) error {
+	layer := inst.TMX.LayerByName(layerName)
+	if layer == nil {
+		return fmt.Errorf("layer not found: %s", layerName)
+	}
+	cells, err := layer.floodCells(x, y, gid)
+	if err != nil || len(cells) == 0 {
+		return err
+	}
+	return inst.writeCells(layer, cells)
+}
+
+// PasteRegion stamps a patch into a layer of the instance's map like Layer.PasteRegion, recording the
+// change for rewinding, saving and replays.
+func (inst *MapInstance) PasteRegion(layerName string, patch *TilePatch, x, y int) error {
+	layer := inst.TMX.LayerByName(layerName)
+	if layer == nil {
+		return fmt.Errorf("layer not found: %s", layerName)
+	}
+	cells, err := layer.pasteCells(patch, x, y)
+	if err != nil || len(cells) == 0 {
+		return err
+	}
+	return inst.writeCells(layer, cells)
+}
+
+// GetTileGID returns the raw data of a cell of a layer of the instance's map, like Layer.GetTileGID.
+func (inst *MapInstance) GetTileGID(layerName string, x, y int) (uint32, error) {
+	layer := inst.TMX.LayerByName(layerName)
+	if layer == nil {
+		return 0, fmt.Errorf("layer not found: %s", layerName)
+	}
+	return layer.GetTileGID(x, y)
+}
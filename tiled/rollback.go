@@ -0,0 +1,186 @@
+package tiled
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// ======================================================
+// Rollback Mutation Queue
+// ======================================================
+
+// TileMutation is a tick-stamped change to a single cell.
+type TileMutation struct {
+	Tick  uint64
+	Layer string
+	Cell  Cell
+
+	// Data is the cell's new raw data: a GID, optionally combined with flip flags. Zero clears the cell.
+	Data uint32
+}
+
+// compareMutations orders mutations by tick and then by content, so every peer applies
+// the same mutations in the same order regardless of the order they arrived in.
+func compareMutations(a, b TileMutation) int {
+	return cmp.Or(
+		cmp.Compare(a.Tick, b.Tick),
+		cmp.Compare(a.Layer, b.Layer),
+		cmp.Compare(a.Cell.Y, b.Cell.Y),
+		cmp.Compare(a.Cell.X, b.Cell.X),
+		cmp.Compare(a.Data, b.Data),
+	)
+}
+
+// MutationQueue applies tile mutations to an instance in a deterministic order and can roll them
+// back and reapply them, for games that re-simulate ticks when late inputs arrive.
+// Mutations applied through the queue aren't recorded by the instance's rewind buffer.
+type MutationQueue struct {
+	inst *MapInstance
+
+	mutations []TileMutation
+	before    []uint32
+	applied   int
+
+	tick    uint64
+	head    uint64
+	started bool
+}
+
+// NewMutationQueue creates an empty mutation queue for the instance.
+func NewMutationQueue(inst *MapInstance) *MutationQueue {
+	return &MutationQueue{inst: inst}
+}
+
+// Tick returns the tick the queue has been applied up to.
+func (q *MutationQueue) Tick() uint64 {
+	return q.tick
+}
+
+// Pending returns how many submitted mutations haven't been applied yet.
+func (q *MutationQueue) Pending() int {
+	return len(q.mutations) - q.applied
+}
+
+// Submit queues a mutation. Mutations for ticks that have already been applied are rejected;
+// roll back to before their tick first, then Reapply.
+func (q *MutationQueue) Submit(m TileMutation) error {
+	if q.started && m.Tick <= q.tick {
+		return fmt.Errorf("mutation for tick %d is not after applied tick %d", m.Tick, q.tick)
+	}
+	i, _ := slices.BinarySearchFunc(q.mutations, m, compareMutations)
+	q.mutations = slices.Insert(q.mutations, i, m)
+	return nil
+}
+
+// Apply applies every queued mutation up to and including tick.
+func (q *MutationQueue) Apply(tick uint64) error {
+	end := q.applied
+	for end < len(q.mutations) && q.mutations[end].Tick <= tick {
+		end++
+	}
+
+	// Cells are written through an overlay so a cell changed twice records the right previous value.
+	overlay := make(map[string]map[Cell]uint32)
+	before := make([]uint32, 0, end-q.applied)
+
+	for _, m := range q.mutations[q.applied:end] {
+		layer := q.inst.TMX.LayerByName(m.Layer)
+		if layer == nil {
+			return fmt.Errorf("layer not found: %s", m.Layer)
+		}
+
+		cells, exists := overlay[m.Layer]
+		if !exists {
+			cells = make(map[Cell]uint32)
+			overlay[m.Layer] = cells
+		}
+
+		prev, exists := cells[m.Cell]
+		if !exists {
+			data, err := layer.cellAt(m.Cell.X, m.Cell.Y)
+			if err != nil {
+				return err
+			}
+			prev = data
+		}
+
+		before = append(before, prev)
+		cells[m.Cell] = m.Data
+	}
+
+	if err := q.write(overlay); err != nil {
+		return err
+	}
+
+	q.before = append(q.before, before...)
+	q.applied = end
+	q.tick = max(q.tick, tick)
+	q.head = max(q.head, q.tick)
+	q.started = true
+	return nil
+}
+
+// Rollback undoes every applied mutation after tick. The mutations stay queued, so a later
+// Apply or Reapply applies them again together with any mutations submitted in the meantime.
+func (q *MutationQueue) Rollback(tick uint64) error {
+	start := q.applied
+	for start > 0 && q.mutations[start-1].Tick > tick {
+		start--
+	}
+
+	// Undoing newest first leaves each cell with the value it had before its earliest undone mutation.
+	overlay := make(map[string]map[Cell]uint32)
+	for i := q.applied - 1; i >= start; i-- {
+		m := q.mutations[i]
+		cells, exists := overlay[m.Layer]
+		if !exists {
+			cells = make(map[Cell]uint32)
+			overlay[m.Layer] = cells
+		}
+		cells[m.Cell] = q.before[i]
+	}
+
+	if err := q.write(overlay); err != nil {
+		return err
+	}
+
+	q.before = q.before[:start]
+	q.applied = start
+	q.tick = min(q.tick, tick)
+	return nil
+}
+
+// Reapply applies every queued mutation up to the newest tick applied before the last rollback,
+// re-simulating the rolled back ticks together with any mutations submitted in the meantime.
+func (q *MutationQueue) Reapply() error {
+	return q.Apply(q.head)
+}
+
+// Confirm drops the history of every mutation up to and including tick, once no peer can roll
+// back that far anymore. Confirmed mutations can no longer be rolled back.
+func (q *MutationQueue) Confirm(tick uint64) {
+	n := 0
+	for n < q.applied && q.mutations[n].Tick <= tick {
+		n++
+	}
+	q.mutations = slices.Delete(q.mutations, 0, n)
+	q.before = slices.Delete(q.before, 0, n)
+	q.applied -= n
+}
+
+func (q *MutationQueue) write(overlay map[string]map[Cell]uint32) error {
+	// Layers are written in name order so failures are reproducible across peers.
+	names := make([]string, 0, len(overlay))
+	for name := range overlay {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if err := q.inst.setLayerCells(q.inst.TMX.LayerByName(name), overlay[name]); err != nil {
+			return fmt.Errorf("failed to write layer %s: %w", name, err)
+		}
+	}
+	return nil
+}
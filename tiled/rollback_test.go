@@ -0,0 +1,91 @@
+package tiled
+
+import "testing"
+
+func TestMutationQueueOrdering(t *testing.T) {
+	tmx := loadFixture(t, "ortho_base64.tmx")
+
+	// Two peers receive the same mutations in different orders and must end up with the same cell.
+	mutations := []TileMutation{
+		{Tick: 2, Layer: "walls", Cell: Cell{X: 1, Y: 1}, Data: 3},
+		{Tick: 1, Layer: "walls", Cell: Cell{X: 1, Y: 1}, Data: 4},
+		{Tick: 2, Layer: "walls", Cell: Cell{X: 1, Y: 1}, Data: 1},
+	}
+	for _, order := range [][]int{{0, 1, 2}, {2, 1, 0}, {1, 2, 0}} {
+		inst := NewMapInstance(tmx)
+		q := NewMutationQueue(inst)
+		for _, i := range order {
+			if err := q.Submit(mutations[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := q.Apply(1); err != nil {
+			t.Fatal(err)
+		}
+		if gid, _ := inst.GetTileGID("walls", 1, 1); gid != 4 {
+			t.Errorf("order %v: cell is %d after tick 1, want 4", order, gid)
+		}
+
+		// Mutations of the same tick are applied in data order, so 3 lands after 1.
+		if err := q.Apply(2); err != nil {
+			t.Fatal(err)
+		}
+		if gid, _ := inst.GetTileGID("walls", 1, 1); gid != 3 {
+			t.Errorf("order %v: cell is %d after tick 2, want 3", order, gid)
+		}
+		if q.Pending() != 0 {
+			t.Errorf("order %v: %d mutations still pending", order, q.Pending())
+		}
+	}
+}
+
+func TestMutationQueueRollback(t *testing.T) {
+	tmx := loadFixture(t, "ortho_base64.tmx")
+	inst := NewMapInstance(tmx)
+	q := NewMutationQueue(inst)
+
+	cell := Cell{X: 2, Y: 1}
+	for _, m := range []TileMutation{
+		{Tick: 1, Layer: "walls", Cell: cell, Data: 1},
+		{Tick: 2, Layer: "walls", Cell: cell, Data: 3},
+	} {
+		if err := q.Submit(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Apply(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Submit(TileMutation{Tick: 2, Layer: "walls", Cell: cell, Data: 4}); err == nil {
+		t.Fatal("a mutation for an applied tick was accepted")
+	}
+
+	if err := q.Rollback(0); err != nil {
+		t.Fatal(err)
+	}
+	if gid, _ := inst.GetTileGID("walls", cell.X, cell.Y); gid != 0 {
+		t.Errorf("cell is %d after rolling back, want the original 0", gid)
+	}
+	if q.Tick() != 0 || q.Pending() != 2 {
+		t.Errorf("rolled back to tick %d with %d pending, want tick 0 with 2 pending", q.Tick(), q.Pending())
+	}
+
+	// A late mutation for a rolled back tick is re-simulated together with the original ones.
+	if err := q.Submit(TileMutation{Tick: 1, Layer: "walls", Cell: Cell{X: 3, Y: 1}, Data: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Reapply(); err != nil {
+		t.Fatal(err)
+	}
+	if gid, _ := inst.GetTileGID("walls", cell.X, cell.Y); gid != 3 {
+		t.Errorf("cell is %d after reapplying, want 3", gid)
+	}
+	if gid, _ := inst.GetTileGID("walls", 3, 1); gid != 2 {
+		t.Errorf("late mutation left %d, want 2", gid)
+	}
+	if gid, _ := tmx.LayerByName("walls").GetTileGID(cell.X, cell.Y); gid != 0 {
+		t.Errorf("mutations leaked into the shared map: %d", gid)
+	}
+}
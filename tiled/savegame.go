@@ -0,0 +1,174 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ======================================================
+// Map Saves
+// ======================================================
+
+// MapSaveVersion is the version of the save format written by this package.
+// Readers accept saves of any version: fields added by later versions are ignored,
+// and fields missing from earlier versions keep their zero values.
+const MapSaveVersion = 1
+
+// MapSaveAssetType is the asset type of saves written with WriteMapSave.
+const MapSaveAssetType = "tiled-save"
+
+// MapSave is a persistable snapshot of the runtime state of a map instance: every cell changed
+// through the instance, plus any per-cell gameplay data grids the game attaches.
+type MapSave struct {
+	Version int `json:"version"`
+
+	// Map is the asset path of the map the save was taken from.
+	Map string `json:"map,omitempty"`
+
+	// Layers holds the changed cells of each tile layer.
+	Layers map[string][]SavedCell `json:"layers,omitempty"`
+
+	// Grids holds named gameplay data, such as CellData grids, encoded as JSON.
+	Grids map[string]json.RawMessage `json:"grids,omitempty"`
+}
+
+// SavedCell is the raw data, including flip flags, of a changed cell.
+type SavedCell struct {
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	Data uint32 `json:"data"`
+}
+
+// Save snapshots every cell changed through the instance. The map path is recorded so the
+// save can be matched with its map when restoring.
+func (inst *MapInstance) Save(mapPath string) *MapSave {
+	save := &MapSave{
+		Version: MapSaveVersion,
+		Map:     mapPath,
+	}
+
+	for layerName, cells := range inst.edits {
+		saved := make([]SavedCell, 0, len(cells))
+		for cell, data := range cells {
+			saved = append(saved, SavedCell{X: cell.X, Y: cell.Y, Data: data})
+		}
+		// Cells are sorted so the same state always produces the same save.
+		slices.SortFunc(saved, func(a, b SavedCell) int {
+			if a.Y != b.Y {
+				return a.Y - b.Y
+			}
+			return a.X - b.X
+		})
+		if save.Layers == nil {
+			save.Layers = make(map[string][]SavedCell)
+		}
+		save.Layers[layerName] = saved
+	}
+
+	return save
+}
+
+// Restore applies the changed cells of a save to the instance. Layers that no longer exist in
+// the map are skipped, so saves survive maps being edited between releases.
+func (inst *MapInstance) Restore(save *MapSave) error {
+	for layerName, saved := range save.Layers {
+		layer := inst.TMX.LayerByName(layerName)
+		if layer == nil {
+			continue
+		}
+		cells := make(map[Cell]uint32, len(saved))
+		for _, c := range saved {
+			cells[Cell{X: c.X, Y: c.Y}] = c.Data
+		}
+		if err := inst.setLayerCells(layer, cells); err != nil {
+			return fmt.Errorf("failed to restore layer %s: %w", layerName, err)
+		}
+	}
+	return nil
+}
+
+// SetGrid stores named gameplay data in the save.
+func (save *MapSave) SetGrid(name string, grid any) error {
+	data, err := json.Marshal(grid)
+	if err != nil {
+		return fmt.Errorf("failed to encode grid %s: %w", name, err)
+	}
+	if save.Grids == nil {
+		save.Grids = make(map[string]json.RawMessage)
+	}
+	save.Grids[name] = data
+	return nil
+}
+
+// Grid decodes named gameplay data from the save into grid.
+// It returns false if the save has no data with that name.
+func (save *MapSave) Grid(name string, grid any) (bool, error) {
+	data, exists := save.Grids[name]
+	if !exists {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, grid); err != nil {
+		return false, fmt.Errorf("failed to decode grid %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// WriteMapSave encodes a save as JSON.
+func WriteMapSave(w io.Writer, save *MapSave) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(save)
+}
+
+// ReadMapSave decodes a save written by WriteMapSave.
+func ReadMapSave(r io.Reader) (*MapSave, error) {
+	var save MapSave
+	if err := json.NewDecoder(r).Decode(&save); err != nil {
+		return nil, err
+	}
+	if save.Version < 1 {
+		return nil, fmt.Errorf("invalid map save version: %d", save.Version)
+	}
+	return &save, nil
+}
+
+// ======================================================
+// Cell Data
+// ======================================================
+
+// CellData is a rectangular grid of per-cell gameplay values, such as tile health or fog of war,
+// that can be stored in a MapSave.
+type CellData[T any] struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Values []T `json:"values"`
+}
+
+// NewCellData creates a grid covering the given block of cells, with every value zeroed.
+func NewCellData[T any](x, y, width, height int) *CellData[T] {
+	return &CellData[T]{X: x, Y: y, Width: width, Height: height, Values: make([]T, width*height)}
+}
+
+// At returns the value of a cell, or false if the cell is outside the grid.
+func (g *CellData[T]) At(x, y int) (T, bool) {
+	if !g.contains(x, y) {
+		var zero T
+		return zero, false
+	}
+	return g.Values[(y-g.Y)*g.Width+(x-g.X)], true
+}
+
+// Set changes the value of a cell. Cells outside the grid are ignored.
+func (g *CellData[T]) Set(x, y int, value T) {
+	if g.contains(x, y) {
+		g.Values[(y-g.Y)*g.Width+(x-g.X)] = value
+	}
+}
+
+func (g *CellData[T]) contains(x, y int) bool {
+	return x >= g.X && x < g.X+g.Width && y >= g.Y && y < g.Y+g.Height && len(g.Values) == g.Width*g.Height
+}
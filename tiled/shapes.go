@@ -0,0 +1,157 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Collision Shapes
+// ======================================================
+
+// CollisionShape is a collision shape authored on a tile, placed in world space.
+type CollisionShape struct {
+	// Cell is the layer cell the tile occupies.
+	Cell Cell
+
+	// Object is the authored shape in the tileset's collision editor.
+	Object *Object
+
+	// Bounds is the world-space bounding rectangle of the shape.
+	Bounds geom.Rect64
+
+	// Polygon holds the world-space points of polygon and polyline shapes; it is nil for rectangles.
+	Polygon []geom.Point64
+}
+
+// IsRect reports whether the shape is an axis-aligned rectangle fully described by its bounds.
+func (s CollisionShape) IsRect() bool {
+	return s.Polygon == nil
+}
+
+// CollisionShapes walks a tile layer and returns every collision shape authored on its tiles,
+// transformed into world space with the tile's flips applied.
+func CollisionShapes(tmx *TMX, layerName string) ([]CollisionShape, error) {
+	layer := tmx.LayerByName(layerName)
+	if layer == nil {
+		return nil, fmt.Errorf("layer not found: %s", layerName)
+	}
+
+	cellWidth, cellHeight := tmx.TileWidth(), tmx.TileHeight()
+
+	var shapes []CollisionShape
+	var walkErr error
+
+	err := forEachCell(layer, func(x, y int, data uint32) {
+		if walkErr != nil || data&TILE_ID_MASK == 0 {
+			return
+		}
+
+		tile, err := TileOf(data, tmx.Tilesets, cellHeight)
+		if err != nil {
+			walkErr = err
+			return
+		}
+
+		tsx, err := tile.TSX()
+		if err != nil {
+			walkErr = err
+			return
+		}
+
+		og := tsx.TileCollision(tile.GID)
+		if og == nil {
+			return
+		}
+
+		tile.X += float64(x * cellWidth)
+		tile.Y += float64(y * cellHeight)
+
+		for _, obj := range og.Objects {
+			shapes = append(shapes, tileShape(tile, Cell{X: x, Y: y}, obj))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	return shapes, nil
+}
+
+// tileShape transforms a tile's collision object from tile-local space into world space.
+func tileShape(tile *Tile, cell Cell, obj *Object) CollisionShape {
+	ox, oy := obj.X64(), obj.Y64()
+
+	var local []geom.Point64
+	switch {
+	case obj.Polygon != nil:
+		local = offsetPoints(obj.Polygon.Points(), ox, oy)
+	case obj.Polyline != nil:
+		local = offsetPoints(obj.Polyline.Points(), ox, oy)
+	default:
+		w, h := obj.Width64(), obj.Height64()
+		local = []geom.Point64{
+			geom.NewPoint64(ox, oy),
+			geom.NewPoint64(ox+w, oy),
+			geom.NewPoint64(ox+w, oy+h),
+			geom.NewPoint64(ox, oy+h),
+		}
+	}
+
+	world := make([]geom.Point64, len(local))
+	for i := range local {
+		x, y := flipPoint(local[i].X, local[i].Y, tile.Width, tile.Height, tile.Flags)
+		world[i] = geom.NewPoint64(tile.X+x, tile.Y+y)
+	}
+
+	shape := CollisionShape{
+		Cell:   cell,
+		Object: obj,
+		Bounds: pointBounds(world),
+	}
+	if obj.Polygon != nil || obj.Polyline != nil {
+		shape.Polygon = world
+	}
+	return shape
+}
+
+// flipPoint applies a tile's flip flags to a point in tile-local space,
+// in the same order the renderer applies them.
+// See: https://doc.mapeditor.org/en/stable/reference/global-tile-ids/#tile-flipping
+func flipPoint(x, y, width, height float64, flags FlipFlags) (float64, float64) {
+	if flags&FLIP_DIAGONAL != 0 {
+		x, y = y+height-width, x
+	}
+	if flags&FLIP_HORIZONTAL != 0 {
+		x = width - x
+	}
+	if flags&FLIP_VERTICAL != 0 {
+		y = height - y
+	}
+	return x, y
+}
+
+func offsetPoints(points []geom.Point64, dx, dy float64) []geom.Point64 {
+	for i := range points {
+		points[i] = geom.NewPoint64(points[i].X+dx, points[i].Y+dy)
+	}
+	return points
+}
+
+func pointBounds(points []geom.Point64) geom.Rect64 {
+	if len(points) == 0 {
+		return geom.Rect64{}
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	return geom.NewRect64(minX, minY, maxX-minX, maxY-minY)
+}
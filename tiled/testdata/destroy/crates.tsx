@@ -0,0 +1,20 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.11.0" name="crates" tilewidth="16" tileheight="16" tilecount="4" columns="4">
+ <image source="../tiles.png" width="64" height="16"/>
+ <tile id="0">
+  <properties>
+   <property name="destroyed" type="int" value="1"/>
+   <property name="destructible" type="bool" value="true"/>
+  </properties>
+ </tile>
+ <tile id="2">
+  <properties>
+   <property name="destructible" type="bool" value="false"/>
+  </properties>
+ </tile>
+ <tile id="3">
+  <properties>
+   <property name="destructible" type="bool" value="true"/>
+  </properties>
+ </tile>
+</tileset>
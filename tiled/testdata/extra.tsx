@@ -0,0 +1,4 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.11.0" name="extra" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+ <image source="extra.png" width="32" height="16"/>
+</tileset>
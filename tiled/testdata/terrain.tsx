@@ -0,0 +1,14 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.11.0" name="terrain" tilewidth="16" tileheight="16" tilecount="4" columns="4">
+ <image source="tiles.png" width="64" height="16"/>
+ <wangsets>
+  <wangset name="ground" type="corner" tile="-1">
+   <wangcolor name="grass" color="#ff0000" tile="0" probability="1"/>
+   <wangcolor name="stone" color="#00ff00" tile="1" probability="1"/>
+   <wangtile tileid="0" wangid="0,1,0,1,0,1,0,1"/>
+   <wangtile tileid="1" wangid="0,2,0,2,0,2,0,2"/>
+   <wangtile tileid="2" wangid="0,1,0,2,0,1,0,1"/>
+   <wangtile tileid="3" wangid="0,1,0,1,0,1,0,2"/>
+  </wangset>
+ </wangsets>
+</tileset>
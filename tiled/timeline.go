@@ -0,0 +1,182 @@
+package tiled
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ======================================================
+// Map Event Timeline
+// ======================================================
+
+const (
+	// EventsLayerName is the name of the object group whose objects are compiled into a map's timeline.
+	EventsLayerName = "events"
+
+	// EventAtProperty is the object property holding when an event fires, e.g. "12.5s" or "12.5".
+	EventAtProperty = "at"
+
+	// EventActionProperty is the object property holding what an event does, e.g. "show-layer:Lights".
+	EventActionProperty = "action"
+)
+
+// TimelineEvent is a single timed action compiled from an object of the events layer.
+type TimelineEvent struct {
+	At     time.Duration
+	Action string
+	Arg    string
+	Object *Object
+}
+
+// Timeline is the ordered list of events compiled from a map's events layer.
+type Timeline struct {
+	Events []TimelineEvent
+
+	elapsed time.Duration
+	next    int
+}
+
+// EventHandler executes a timeline action on a map instance.
+type EventHandler func(inst *MapInstance, event TimelineEvent) error
+
+// CompileTimeline compiles the objects of the map's events layer into a timeline.
+// Each object needs an "at" property with the time the event fires and an "action" property
+// in the form "verb:argument". Maps without an events layer produce an empty timeline.
+func CompileTimeline(tmx *TMX) (*Timeline, error) {
+	timeline := &Timeline{}
+
+	og := tmx.ObjectGroupByName(EventsLayerName)
+	if og == nil {
+		return timeline, nil
+	}
+
+	for _, obj := range og.Objects {
+		atProp := findProperty(obj.Properties, EventAtProperty)
+		actionProp := findProperty(obj.Properties, EventActionProperty)
+
+		if atProp == nil || actionProp == nil {
+			return nil, fmt.Errorf("event object %d requires %q and %q properties", obj.ID(), EventAtProperty, EventActionProperty)
+		}
+
+		at, err := parseEventTime(atProp.Value())
+		if err != nil {
+			return nil, fmt.Errorf("event object %d: %w", obj.ID(), err)
+		}
+
+		action, arg, _ := strings.Cut(actionProp.Value(), ":")
+		if action == "" {
+			return nil, fmt.Errorf("event object %d has an empty action", obj.ID())
+		}
+
+		timeline.Events = append(timeline.Events, TimelineEvent{
+			At:     at,
+			Action: action,
+			Arg:    arg,
+			Object: obj,
+		})
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].At < timeline.Events[j].At
+	})
+
+	return timeline, nil
+}
+
+// Elapsed returns how far the timeline has advanced.
+func (tl *Timeline) Elapsed() time.Duration {
+	return tl.elapsed
+}
+
+// Reset rewinds the timeline so its events fire again.
+func (tl *Timeline) Reset() {
+	tl.elapsed = 0
+	tl.next = 0
+}
+
+func parseEventTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid event time: %s", s)
+	}
+	return d, nil
+}
+
+// ======================================================
+// Instance Timeline
+// ======================================================
+
+var defaultEventHandlers = map[string]EventHandler{
+	"show-layer": func(inst *MapInstance, event TimelineEvent) error {
+		inst.SetLayerVisible(event.Arg, true)
+		return nil
+	},
+	"hide-layer": func(inst *MapInstance, event TimelineEvent) error {
+		inst.SetLayerVisible(event.Arg, false)
+		return nil
+	},
+	"toggle-layer": func(inst *MapInstance, event TimelineEvent) error {
+		inst.SetLayerVisible(event.Arg, !inst.IsLayerVisible(event.Arg))
+		return nil
+	},
+}
+
+// HandleEvent registers the handler for a timeline action, replacing any built-in handler
+// for the same action. Built-in actions are show-layer, hide-layer and toggle-layer.
+func (inst *MapInstance) HandleEvent(action string, handler EventHandler) {
+	if inst.eventHandlers == nil {
+		inst.eventHandlers = make(map[string]EventHandler)
+	}
+	inst.eventHandlers[action] = handler
+}
+
+// Timeline returns the instance's timeline, compiling it from the map on first use.
+func (inst *MapInstance) Timeline() (*Timeline, error) {
+	if inst.timeline == nil {
+		timeline, err := CompileTimeline(inst.TMX)
+		if err != nil {
+			return nil, err
+		}
+		inst.timeline = timeline
+	}
+	return inst.timeline, nil
+}
+
+// Update advances the instance's clock and timeline by dt and executes every timeline event that became due.
+// Decals stamped with package render age with the clock.
+func (inst *MapInstance) Update(dt time.Duration) error {
+	inst.clock += dt
+
+	timeline, err := inst.Timeline()
+	if err != nil {
+		return err
+	}
+
+	timeline.elapsed += dt
+
+	for timeline.next < len(timeline.Events) && timeline.Events[timeline.next].At <= timeline.elapsed {
+		event := timeline.Events[timeline.next]
+		timeline.next++
+
+		handler, exists := inst.eventHandlers[event.Action]
+		if !exists {
+			handler, exists = defaultEventHandlers[event.Action]
+		}
+		if !exists {
+			return fmt.Errorf("unknown timeline action: %s", event.Action)
+		}
+
+		if err := handler(inst, event); err != nil {
+			return fmt.Errorf("timeline action %s failed: %w", event.Action, err)
+		}
+	}
+
+	return nil
+}
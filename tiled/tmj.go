@@ -0,0 +1,276 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ======================================================
+// TMJ Writer
+// ======================================================
+
+// tmjObject is a JSON object of a map exported to Tiled JSON.
+type tmjObject map[string]any
+
+// WriteTMJ writes the map as Tiled JSON (.tmj), keeping each layer's current data format.
+func WriteTMJ(w io.Writer, tmx *TMX) error {
+	return WriteTMJWithOptions(w, tmx, WriteOptions{})
+}
+
+// WriteTMJWithOptions writes the map as Tiled JSON (.tmj) like WriteTMXWithOptions writes it as XML.
+// CSV layer data is written as arrays of GIDs; base64 layer data keeps its encoding and compression.
+func WriteTMJWithOptions(w io.Writer, tmx *TMX, opts WriteOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	tw := &tmjWriter{opts: opts}
+
+	doc, err := tw.mapObject(tmx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(doc)
+}
+
+type tmjWriter struct {
+	opts WriteOptions
+}
+
+func (tw *tmjWriter) mapObject(tmx *TMX) (tmjObject, error) {
+	doc := tw.attrs(tmx.Attrs)
+	doc["type"] = "map"
+	doc["infinite"] = tmx.IsInfinite()
+	doc["orientation"] = tmx.Orientation().String()
+	doc["renderorder"] = tmx.RenderOrder().String()
+	tw.properties(doc, tmx.Properties)
+
+	tilesets := make([]tmjObject, 0, len(tmx.Tilesets))
+	for _, tileset := range tmx.Tilesets {
+		tilesets = append(tilesets, tw.attrs(tw.opts.relativeSource(tileset.Attrs, SourceAttr)))
+	}
+	doc["tilesets"] = tilesets
+
+	layers := []tmjObject{}
+	for _, l := range tmx.OrderedLayers() {
+		var obj tmjObject
+		var err error
+		switch layer := l.(type) {
+		case *Layer:
+			obj, err = tw.layer(layer)
+		case *ImageLayer:
+			obj = tw.imageLayer(layer)
+		case *ObjectGroup:
+			obj = tw.objectGroup(layer)
+		}
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, obj)
+	}
+	doc["layers"] = layers
+
+	return doc, nil
+}
+
+func (tw *tmjWriter) layer(layer *Layer) (tmjObject, error) {
+	obj := tw.attrs(layer.Attrs)
+	obj["type"] = "tilelayer"
+	obj["opacity"] = layer.Opacity()
+	obj["visible"] = layer.IsVisible()
+	obj["x"], obj["y"] = 0, 0
+	tw.properties(obj, layer.Properties)
+
+	if layer.Data == nil {
+		return obj, nil
+	}
+
+	format := tw.opts.layerFormat(layer)
+	obj["encoding"] = format.Encoding.String()
+	if format.Encoding == TMXEncodingBase64 && format.Compression != TMXCompressionNone {
+		obj["compression"] = format.Compression.String()
+	}
+
+	if len(layer.Data.Chunks) == 0 {
+		data, err := tw.data(layer.Data, layer.Data.Data, layer.Width(), format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode layer %s: %w", layer.Name(), err)
+		}
+		obj["data"] = data
+		return obj, nil
+	}
+
+	chunks := make([]tmjObject, 0, len(layer.Data.Chunks))
+	for _, chunk := range layer.Data.Chunks {
+		data, err := tw.data(layer.Data, chunk.Data, chunk.Width(), format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode layer %s: %w", layer.Name(), err)
+		}
+		c := tw.attrs(chunk.Attrs)
+		c["data"] = data
+		chunks = append(chunks, c)
+	}
+	obj["chunks"] = chunks
+
+	return obj, nil
+}
+
+// data returns layer or chunk data as Tiled JSON stores it: an array of GIDs for CSV, or an encoded string.
+func (tw *tmjWriter) data(data *LayerData, raw string, width int, format DataFormat) (any, error) {
+	decoded, err := data.decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	if format.Encoding == TMXEncodingCSV {
+		return decoded, nil
+	}
+	encoded, err := encodeData(decoded, width, format)
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimSpace(encoded), nil
+}
+
+func (tw *tmjWriter) imageLayer(layer *ImageLayer) tmjObject {
+	obj := tw.attrs(layer.Attrs)
+	obj["type"] = "imagelayer"
+	obj["opacity"] = layer.Opacity()
+	obj["visible"] = layer.IsVisible()
+	tw.properties(obj, layer.Properties)
+
+	if layer.Image != nil {
+		image := tw.attrs(tw.opts.relativeSource(layer.Image.Attrs, SourceAttr))
+		obj["image"] = image[SourceAttr]
+		if width, exists := image[WidthAttr]; exists {
+			obj["imagewidth"] = width
+		}
+		if height, exists := image[HeightAttr]; exists {
+			obj["imageheight"] = height
+		}
+	}
+
+	return obj
+}
+
+func (tw *tmjWriter) objectGroup(og *ObjectGroup) tmjObject {
+	obj := tw.attrs(og.Attrs)
+	obj["type"] = "objectgroup"
+	obj["draworder"] = og.DrawOrder().String()
+	obj["opacity"] = og.Opacity()
+	obj["visible"] = og.IsVisible()
+	tw.properties(obj, og.Properties)
+
+	objects := make([]tmjObject, 0, len(og.Objects))
+	for _, o := range og.Objects {
+		objects = append(objects, tw.object(o))
+	}
+	obj["objects"] = objects
+
+	return obj
+}
+
+func (tw *tmjWriter) object(o *Object) tmjObject {
+	obj := tw.attrs(tw.opts.relativeSource(o.Attrs, TemplateAttr))
+	tw.properties(obj, o.Properties)
+
+	switch o.Shape() {
+	case ObjectShapeEllipse:
+		obj["ellipse"] = true
+	case ObjectShapePoint:
+		obj["point"] = true
+	case ObjectShapePolygon:
+		obj["polygon"] = tmjPoints(o.Polygon)
+	case ObjectShapePolyline:
+		obj["polyline"] = tmjPoints(o.Polyline)
+	case ObjectShapeText:
+		text := tw.attrs(o.Text.Attrs)
+		text["text"] = o.Text.Text
+		obj["text"] = text
+	}
+
+	return obj
+}
+
+func tmjPoints(pl *Polyline) []tmjObject {
+	points := pl.Points()
+	out := make([]tmjObject, 0, len(points))
+	for _, p := range points {
+		out = append(out, tmjObject{"x": p.X, "y": p.Y})
+	}
+	return out
+}
+
+// properties adds the properties, if any, to a JSON object with their values typed as declared.
+func (tw *tmjWriter) properties(obj tmjObject, props []*Property) {
+	if len(props) == 0 {
+		return
+	}
+
+	out := make([]tmjObject, 0, len(props))
+	for _, prop := range props {
+		p := tmjObject{
+			"name":  prop.Name(),
+			"type":  prop.Type(),
+			"value": tw.propertyValue(prop),
+		}
+		if ptype, exists := prop.Attrs[PropertyTypeAttr]; exists {
+			p["propertytype"] = ptype.String()
+		}
+		out = append(out, p)
+	}
+	obj["properties"] = out
+}
+
+func (tw *tmjWriter) propertyValue(prop *Property) any {
+	switch prop.Type() {
+	case IntPropertyType, ObjectPropertyType:
+		if v, err := strconv.Atoi(prop.Value()); err == nil {
+			return v
+		}
+		return 0
+	case FloatPropertyType:
+		if v, err := strconv.ParseFloat(prop.Value(), 64); err == nil {
+			return v
+		}
+		return 0
+	case BoolPropertyType:
+		return prop.Value() == "true"
+	case FilePropertyType:
+		if prop.Value() == "" {
+			return ""
+		}
+		return tw.opts.relativeSource(prop.Attrs, ValueAttr)[ValueAttr].String()
+	case ClassPropertyType:
+		members := tmjObject{}
+		for _, member := range prop.Properties {
+			members[member.Name()] = tw.propertyValue(member)
+		}
+		return members
+	default:
+		return prop.Value()
+	}
+}
+
+// attrs converts an attribute table to JSON values of the matching types.
+func (tw *tmjWriter) attrs(table TiledXMLAttrTable) tmjObject {
+	obj := make(tmjObject, len(table))
+	for key, value := range table {
+		switch v := value.(type) {
+		case AttrInt:
+			obj[key] = v.Int()
+		case AttrFloat:
+			obj[key] = json.Number(tw.opts.formatFloat(v.Float()))
+		case AttrBool:
+			obj[key] = v.Bool()
+		default:
+			obj[key] = value.String()
+		}
+	}
+	return obj
+}
@@ -1,6 +1,10 @@
 package tiled
 
 import (
+	"cmp"
+	"encoding/xml"
+	"slices"
+
 	"github.com/adm87/finch-core/enum"
 	"github.com/adm87/finch-core/geom"
 )
@@ -12,9 +16,138 @@ import (
 // TMX represents a deserialized Tiled tmx file.
 type TMX struct {
 	Attrs        TiledXMLAttrTable `xml:",any,attr"`
+	Properties   []*Property       `xml:"properties>property"`
 	ObjectGroups []*ObjectGroup    `xml:"objectgroup"`
 	Tilesets     []*Tileset        `xml:"tileset"`
 	Layers       []*Layer          `xml:"layer"`
+	ImageLayers  []*ImageLayer     `xml:"imagelayer"`
+
+	// Layers, image layers and object groups in document order.
+	order []any
+
+	objects *ObjectIndex
+}
+
+// UnmarshalXML decodes the map while recording the document order of its layers,
+// which the separate layer slices cannot represent on their own.
+func (tmx *TMX) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if err := tmx.Attrs.UnmarshalXMLAttr(attr); err != nil {
+			return err
+		}
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "properties":
+				var props struct {
+					Properties []*Property `xml:"property"`
+				}
+				if err := d.DecodeElement(&props, &t); err != nil {
+					return err
+				}
+				tmx.Properties = append(tmx.Properties, props.Properties...)
+			case "tileset":
+				tileset := &Tileset{}
+				if err := d.DecodeElement(tileset, &t); err != nil {
+					return err
+				}
+				tmx.Tilesets = append(tmx.Tilesets, tileset)
+			case "layer":
+				layer := &Layer{}
+				if err := d.DecodeElement(layer, &t); err != nil {
+					return err
+				}
+				tmx.Layers = append(tmx.Layers, layer)
+				tmx.order = append(tmx.order, layer)
+			case "imagelayer":
+				imageLayer := &ImageLayer{}
+				if err := d.DecodeElement(imageLayer, &t); err != nil {
+					return err
+				}
+				tmx.ImageLayers = append(tmx.ImageLayers, imageLayer)
+				tmx.order = append(tmx.order, imageLayer)
+			case "objectgroup":
+				og := &ObjectGroup{}
+				if err := d.DecodeElement(og, &t); err != nil {
+					return err
+				}
+				tmx.ObjectGroups = append(tmx.ObjectGroups, og)
+				tmx.order = append(tmx.order, og)
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// OrderedLayers returns the map's layers in drawing order, each a *Layer, *ImageLayer or *ObjectGroup:
+// the document order, without layers that were removed from the map's slices, followed by layers
+// appended to the slices directly, image layers first, then tile layers, then object groups.
+func (tmx TMX) OrderedLayers() []any {
+	n := len(tmx.Layers) + len(tmx.ImageLayers) + len(tmx.ObjectGroups)
+	if len(tmx.order) == n && tmx.orderInSync() {
+		return tmx.order
+	}
+
+	order := make([]any, 0, n)
+	for _, entry := range tmx.order {
+		if tmx.hasLayer(entry) && !slices.Contains(order, entry) {
+			order = append(order, entry)
+		}
+	}
+	for _, il := range tmx.ImageLayers {
+		if !slices.Contains(order, any(il)) {
+			order = append(order, il)
+		}
+	}
+	for _, layer := range tmx.Layers {
+		if !slices.Contains(order, any(layer)) {
+			order = append(order, layer)
+		}
+	}
+	for _, og := range tmx.ObjectGroups {
+		if !slices.Contains(order, any(og)) {
+			order = append(order, og)
+		}
+	}
+	return order
+}
+
+// orderInSync reports whether the document order holds every layer of the map's slices exactly once,
+// assuming it holds as many entries as the slices.
+func (tmx TMX) orderInSync() bool {
+	for i, entry := range tmx.order {
+		if !tmx.hasLayer(entry) || slices.Contains(tmx.order[:i], entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLayer reports whether the entry is one of the map's tile layers, image layers or object groups.
+func (tmx TMX) hasLayer(entry any) bool {
+	switch layer := entry.(type) {
+	case *Layer:
+		return slices.Contains(tmx.Layers, layer)
+	case *ImageLayer:
+		return slices.Contains(tmx.ImageLayers, layer)
+	case *ObjectGroup:
+		return slices.Contains(tmx.ObjectGroups, layer)
+	default:
+		return false
+	}
 }
 
 func (tmx TMX) Orientation() Orientation {
@@ -97,6 +230,41 @@ func (tmx TMX) TileHeight() int {
 	return 0
 }
 
+func (tmx TMX) StaggerAxis() StaggerAxis {
+	if staggerAxis, exists := tmx.Attrs[StaggerAxisAttr]; exists {
+		if attr, ok := staggerAxis.(AttrString); ok {
+			e, err := enum.Value[StaggerAxis](attr.String())
+			if err != nil {
+				panic(err)
+			}
+			return e
+		}
+	}
+	return StaggerAxisY
+}
+
+func (tmx TMX) StaggerIndex() StaggerIndex {
+	if staggerIndex, exists := tmx.Attrs[StaggerIndexAttr]; exists {
+		if attr, ok := staggerIndex.(AttrString); ok {
+			e, err := enum.Value[StaggerIndex](attr.String())
+			if err != nil {
+				panic(err)
+			}
+			return e
+		}
+	}
+	return StaggerIndexOdd
+}
+
+func (tmx TMX) HexSideLength() int {
+	if hexSideLength, exists := tmx.Attrs[HexSideLengthAttr]; exists {
+		if attr, ok := hexSideLength.(AttrInt); ok {
+			return attr.Int()
+		}
+	}
+	return 0
+}
+
 func (tmx TMX) IsInfinite() bool {
 	if infinite, exists := tmx.Attrs[InfiniteAttr]; exists {
 		if attr, ok := infinite.(AttrBool); ok {
@@ -106,6 +274,27 @@ func (tmx TMX) IsInfinite() bool {
 	return false
 }
 
+func (tmx TMX) PropertyOfType(ptype string) (*Property, bool) {
+	for _, prop := range tmx.Properties {
+		if prop.PropertyType() == ptype {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+func (tmx TMX) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(tmx.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (tmx TMX) HasProperty(name string) bool {
+	_, exists := tmx.PropertyByName(name)
+	return exists
+}
+
 func (tmx TMX) LayerByName(name string) *Layer {
 	for _, layer := range tmx.Layers {
 		if layer.Name() == name {
@@ -126,6 +315,15 @@ func (tmx TMX) LayerByProperty(ptype string, pvalue any) *Layer {
 	return nil
 }
 
+func (tmx TMX) ImageLayerByName(name string) *ImageLayer {
+	for _, il := range tmx.ImageLayers {
+		if il.Name() == name {
+			return il
+		}
+	}
+	return nil
+}
+
 func (tmx TMX) ObjectGroupByName(name string) *ObjectGroup {
 	for _, og := range tmx.ObjectGroups {
 		if og.Name() == name {
@@ -168,3 +366,41 @@ func (tmx TMX) Bounds() geom.Rect64 {
 
 	return bounds
 }
+
+// ObjectBounds returns the area covered by an object in world space.
+// Tile objects are placed by their tileset's object alignment, polygons and polylines cover their
+// points, and other objects extend right and down from their position. Points have no size.
+func (tmx TMX) ObjectBounds(obj *Object) geom.Rect64 {
+	x, y := obj.X64(), obj.Y64()
+	w, h := obj.Width64(), obj.Height64()
+
+	if pl := cmp.Or(obj.Polygon, obj.Polyline); pl != nil {
+		if points := pl.Points(); len(points) > 0 {
+			bounds := geom.NewRect64(x+points[0].X, y+points[0].Y, 0, 0)
+			for _, p := range points[1:] {
+				bounds = bounds.Union(geom.NewRect64(x+p.X, y+p.Y, 0, 0))
+			}
+			return bounds
+		}
+	}
+
+	if obj.GID() != 0 {
+		anchor := tmx.ObjectAnchor(uint32(obj.GID()), tmx.Tilesets)
+		x -= anchor.X * w
+		y -= anchor.Y * h
+	}
+
+	return geom.NewRect64(x, y, w, h)
+}
+
+// ObjectAnchor returns the anchor of a tile object referencing the GID in the tilesets, defaulting
+// to bottom-left when the tileset can't be resolved.
+func (tmx TMX) ObjectAnchor(gid uint32, tilesets []*Tileset) geom.Point64 {
+	alignment := ObjectAlignmentUnspecified
+	if tileset := TilesetOf(gid&TILE_ID_MASK, tilesets); tileset != nil {
+		if tsx, err := tileset.TSX(); err == nil {
+			alignment = tsx.ObjectAlignment()
+		}
+	}
+	return alignment.Anchor(tmx.Orientation())
+}
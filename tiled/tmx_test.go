@@ -0,0 +1,52 @@
+package tiled
+
+import "testing"
+
+func layerNames(order []any) []string {
+	names := make([]string, 0, len(order))
+	for _, entry := range order {
+		switch layer := entry.(type) {
+		case *Layer:
+			names = append(names, layer.Name())
+		case *ImageLayer:
+			names = append(names, layer.Name())
+		case *ObjectGroup:
+			names = append(names, layer.Name())
+		}
+	}
+	return names
+}
+
+func checkLayerOrder(t *testing.T, tmx *TMX, want ...string) {
+	t.Helper()
+	got := layerNames(tmx.OrderedLayers())
+	if len(got) != len(want) {
+		t.Fatalf("layer order is %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("layer order is %v, want %v", got, want)
+		}
+	}
+}
+
+func TestOrderedLayersAppendsUnknownLayers(t *testing.T) {
+	tmx := loadFixture(t, "ortho_csv.tmx")
+	checkLayerOrder(t, tmx, "ground", "walls", "things")
+
+	tmx.Layers = append(tmx.Layers, NewLayer("roof", tmx.Width(), tmx.Height()))
+	checkLayerOrder(t, tmx, "ground", "walls", "things", "roof")
+
+	tmx.AddObjectGroup("triggers")
+	checkLayerOrder(t, tmx, "ground", "walls", "things", "roof", "triggers")
+}
+
+func TestOrderedLayersDropsReplacedLayers(t *testing.T) {
+	tmx := loadFixture(t, "ortho_csv.tmx")
+
+	tmx.Layers[0] = NewLayer("floor", tmx.Width(), tmx.Height())
+	checkLayerOrder(t, tmx, "walls", "things", "floor")
+
+	tmx.ObjectGroups = nil
+	checkLayerOrder(t, tmx, "walls", "floor")
+}
@@ -0,0 +1,197 @@
+package tiled
+
+import (
+	"math"
+	"time"
+
+	"github.com/adm87/finch-core/geom"
+)
+
+// ======================================================
+// Top-Down Controller
+// ======================================================
+
+// TopDownInput is the player intent fed to a TopDownController each update.
+type TopDownInput struct {
+	// Move is the movement intent. Each axis ranges from -1 to 1; diagonals are normalized
+	// so moving in any of the eight directions covers the same distance.
+	Move geom.Point64
+}
+
+// ThresholdFunc reports whether a tile is a threshold, such as a doorway or stairs,
+// that should be reported when a body steps onto it.
+type ThresholdFunc func(key TileKey) bool
+
+// TopDownResult is the outcome of a TopDownController update.
+type TopDownResult struct {
+	MoveResult
+
+	// Entered lists the threshold cells the body's centre stepped onto during the update.
+	Entered []Cell
+}
+
+// TopDownController is a reference kinematic controller for top-down games, built on CollisionGrid.
+// It moves in eight directions, slides along walls, and eases bodies around corners they clip by a
+// small amount. Slopes and one-way platforms are ignored. Units are world units and seconds.
+type TopDownController struct {
+	Grid *CollisionGrid
+
+	// Rect is the body's bounds in world space.
+	Rect geom.Rect64
+
+	// Speed is the body's movement speed in world units per second.
+	Speed float64
+
+	// CornerTolerance is how far a body may overlap a blocking corner and still be eased around it.
+	// Zero disables corner sliding.
+	CornerTolerance float64
+
+	// Thresholds configures which tiles are reported in TopDownResult.Entered. Nil disables thresholds.
+	Thresholds ThresholdFunc
+
+	// Facing is the normalized direction of the last non-zero movement intent.
+	Facing geom.Point64
+}
+
+// NewTopDownController creates a controller with defaults tuned for the grid's cell size:
+// six cells per second, and corner sliding for bodies clipping up to a third of a cell.
+func NewTopDownController(grid *CollisionGrid, rect geom.Rect64) *TopDownController {
+	cellWidth, cellHeight := grid.CellSize()
+	cs := math.Min(float64(cellWidth), float64(cellHeight))
+
+	return &TopDownController{
+		Grid:            grid,
+		Rect:            rect,
+		Speed:           cs * 6,
+		CornerTolerance: cs / 3,
+		Facing:          geom.NewPoint64(0, 1),
+	}
+}
+
+// Update advances the controller by dt and returns the collision result of the move.
+func (tc *TopDownController) Update(input TopDownInput, dt time.Duration) (TopDownResult, error) {
+	move := geom.NewPoint64(math.Max(-1, math.Min(1, input.Move.X)), math.Max(-1, math.Min(1, input.Move.Y)))
+	if length := math.Hypot(move.X, move.Y); length > 1 {
+		move = geom.NewPoint64(move.X/length, move.Y/length)
+	}
+	if move.X != 0 || move.Y != 0 {
+		length := math.Hypot(move.X, move.Y)
+		tc.Facing = geom.NewPoint64(move.X/length, move.Y/length)
+	}
+
+	step := tc.Speed * dt.Seconds()
+	delta := geom.NewPoint64(move.X*step, move.Y*step)
+
+	before, err := tc.thresholdCell(tc.Rect)
+	if err != nil {
+		return TopDownResult{}, err
+	}
+
+	moved, err := tc.Grid.MoveAndCollideWithOptions(tc.Rect, delta, MoveOptions{IgnoreOneWay: true, NoSlopeSnap: true})
+	if err != nil {
+		return TopDownResult{MoveResult: moved}, err
+	}
+
+	// Corner sliding only applies to movement along a single axis; diagonal movement already slides.
+	if move.Y == 0 && move.X != 0 && moved.HasContact(geom.NewPoint64(-math.Copysign(1, move.X), 0)) {
+		if err := tc.cornerSlide(&moved, true, math.Copysign(1, move.X), step); err != nil {
+			return TopDownResult{MoveResult: moved}, err
+		}
+	} else if move.X == 0 && move.Y != 0 && moved.HasContact(geom.NewPoint64(0, -math.Copysign(1, move.Y))) {
+		if err := tc.cornerSlide(&moved, false, math.Copysign(1, move.Y), step); err != nil {
+			return TopDownResult{MoveResult: moved}, err
+		}
+	}
+
+	tc.Rect = moved.Rect
+	result := TopDownResult{MoveResult: moved}
+
+	after, err := tc.thresholdCell(tc.Rect)
+	if err != nil {
+		return result, err
+	}
+	if after != nil && (before == nil || *before != *after) {
+		result.Entered = append(result.Entered, *after)
+	}
+
+	return result, nil
+}
+
+// cornerSlide eases a body blocked along one axis around the corner it clipped,
+// moving it perpendicular to its intent by up to step.
+func (tc *TopDownController) cornerSlide(result *MoveResult, horizontal bool, dir, step float64) error {
+	if tc.CornerTolerance <= 0 {
+		return nil
+	}
+
+	cw, ch := tc.Grid.CellSize()
+	rect := result.Rect
+
+	// Size of the cell perpendicular to the movement, and the body's extent along that axis.
+	size, start, extent := float64(ch), rect.Y, rect.Height
+	if !horizontal {
+		size, start, extent = float64(cw), rect.X, rect.Width
+	}
+
+	// Distance the body would need to move to clear the blocking cells on either side.
+	clearNeg := start + extent - math.Floor((start+extent-collisionEpsilon)/size)*size
+	clearPos := math.Ceil((start+collisionEpsilon)/size)*size - start
+
+	for _, nudge := range []float64{-clearNeg, clearPos} {
+		if math.Abs(nudge) > tc.CornerTolerance {
+			continue
+		}
+
+		probe := rect
+		if horizontal {
+			probe.Y += nudge
+			probe.X += dir * float64(cw) / 2
+		} else {
+			probe.X += nudge
+			probe.Y += dir * float64(ch) / 2
+		}
+
+		blocked, err := tc.Grid.Overlaps(probe)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			continue
+		}
+
+		slide := math.Copysign(math.Min(math.Abs(nudge), step), nudge)
+		delta := geom.NewPoint64(0, slide)
+		if !horizontal {
+			delta = geom.NewPoint64(slide, 0)
+		}
+
+		moved, err := tc.Grid.MoveAndCollideWithOptions(rect, delta, MoveOptions{IgnoreOneWay: true, NoSlopeSnap: true})
+		if err != nil {
+			return err
+		}
+		result.Rect = moved.Rect
+		result.Contacts = append(result.Contacts, moved.Contacts...)
+		return nil
+	}
+
+	return nil
+}
+
+// thresholdCell returns the cell under the body's centre if it is a threshold.
+func (tc *TopDownController) thresholdCell(rect geom.Rect64) (*Cell, error) {
+	if tc.Thresholds == nil {
+		return nil, nil
+	}
+
+	x, y := tc.Grid.cellOf(rect.X+rect.Width/2, rect.Y+rect.Height/2)
+
+	data, err := tc.Grid.layer.cellAt(x, y)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := TileKeyOf(data, tc.Grid.tilesets)
+	if !ok || !tc.Thresholds(key) {
+		return nil, nil
+	}
+	return &Cell{X: x, Y: y}, nil
+}
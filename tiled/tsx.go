@@ -1,6 +1,6 @@
 package tiled
 
-import "github.com/adm87/finch-core/geom"
+import "github.com/adm87/finch-core/enum"
 
 // ======================================================
 // TSX File
@@ -8,8 +8,10 @@ import "github.com/adm87/finch-core/geom"
 
 type TSX struct {
 	Attrs      TiledXMLAttrTable `xml:",any,attr"`
+	Properties []*Property       `xml:"properties>property"`
 	TileOffset *Offset           `xml:"tileoffset"`
 	Image      *Image            `xml:"image"`
+	Tiles      []*TilesetTile    `xml:"tile"`
 }
 
 func (tsx TSX) Version() string {
@@ -98,6 +100,76 @@ func (tsx TSX) TileOffsetY() int {
 	return 0
 }
 
-func (tsx TSX) ObjectAlignment() geom.Point64 {
-	return geom.NewPoint64(0, 0)
+func (tsx TSX) ObjectAlignment() ObjectAlignment {
+	if alignment, exists := tsx.Attrs[ObjectAlignmentAttr]; exists {
+		if attr, ok := alignment.(AttrString); ok {
+			e, err := enum.Value[ObjectAlignment](attr.String())
+			if err != nil {
+				panic(err)
+			}
+			return e
+		}
+	}
+	return ObjectAlignmentUnspecified
+}
+
+func (tsx TSX) PropertyOfType(ptype string) (*Property, bool) {
+	for _, prop := range tsx.Properties {
+		if prop.PropertyType() == ptype {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+func (tsx TSX) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(tsx.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (tsx TSX) HasProperty(name string) bool {
+	_, exists := tsx.PropertyByName(name)
+	return exists
+}
+
+func (tsx TSX) Tile(id uint32) *TilesetTile {
+	for _, tile := range tsx.Tiles {
+		if tile.ID() == id {
+			return tile
+		}
+	}
+	return nil
+}
+
+// IsImageCollection reports whether the tileset stores one image per tile instead of a single atlas image.
+func (tsx TSX) IsImageCollection() bool {
+	return tsx.Image == nil
+}
+
+// TileImage returns the image of a tile in an image collection tileset.
+func (tsx TSX) TileImage(id uint32) *Image {
+	if tile := tsx.Tile(id); tile != nil {
+		return tile.Image
+	}
+	return nil
+}
+
+// TileSize returns the size of a tile, which varies per tile in image collection tilesets.
+func (tsx TSX) TileSize(id uint32) (int, int) {
+	if tsx.IsImageCollection() {
+		if img := tsx.TileImage(id); img != nil {
+			return img.Width(), img.Height()
+		}
+	}
+	return tsx.TileWidth(), tsx.TileHeight()
+}
+
+// TileCollision returns the collision shapes authored for a tile in Tiled's collision editor, or nil if there are none.
+func (tsx TSX) TileCollision(id uint32) *ObjectGroup {
+	if tile := tsx.Tile(id); tile != nil {
+		return tile.ObjectGroup
+	}
+	return nil
 }
@@ -1,8 +1,16 @@
 package tiled
 
 import (
+	"cmp"
 	"encoding/xml"
 	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/adm87/finch-core/enum"
 	"github.com/adm87/finch-core/geom"
@@ -59,9 +67,88 @@ type Tile struct {
 	X, Y          float64
 	Width, Height float64
 	Flags         FlipFlags
+	Cell          Cell
+
+	// Source rectangle in the tileset image, when known ahead of drawing.
+	src image.Rectangle
+
+	// File set the tileset is loaded from, if it was loaded with LoadTMX.
+	files *fileSet
+}
+
+// TSX returns the tileset the tile is from.
+func (tile Tile) TSX() (*TSX, error) {
+	return lookupTSX(tile.files, tile.TsxSrc)
+}
+
+// SourceRect returns the region of the tileset image the tile is drawn from when it is known ahead of
+// drawing, as it is for cooked maps. It is empty otherwise.
+func (tile Tile) SourceRect() image.Rectangle {
+	return tile.src
+}
+
+// TileOf returns the tile a cell's raw data references, positioned within a cell cellHeight pixels
+// tall and carrying the data's flip flags, or nil if the cell is empty.
+func TileOf(data uint32, tilesets []*Tileset, cellHeight int) (*Tile, error) {
+	gid := data & TILE_ID_MASK
+	if gid == 0 {
+		return nil, nil // Empty tile
+	}
+
+	tileset := TilesetOf(gid, tilesets)
+	if tileset == nil {
+		return nil, fmt.Errorf("no tileset found for GID %d", gid)
+	}
+
+	tsx, err := tileset.TSX()
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := 0.0, 0.0
+
+	if tsx.TileOffset != nil {
+		x += float64(tsx.TileOffset.X())
+		y += float64(tsx.TileOffset.Y())
+	}
+
+	// Tiled anchors tiles at the bottom-left of their cell.
+	// Adjust the Y position to offset the tile by the difference between the cell and tile's heights.
+	// See: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/
+	tileWidth, tileHeight := tsx.TileSize(gid - tileset.FirstGID())
+
+	y += float64(cellHeight) - float64(tileHeight)
+
+	tile := tileset.Tile(gid - tileset.FirstGID())
+	tile.Flags = FlipFlagsOf(data)
+	tile.X, tile.Y = x, y
+	tile.Width, tile.Height = float64(tileWidth), float64(tileHeight)
+
+	return &tile, nil
 }
 
-type LayerPartitions map[geom.Rect64][]*Tile
+// FlipFlagsOf returns the flip flags of a cell's raw data.
+func FlipFlagsOf(data uint32) FlipFlags {
+	var flags FlipFlags
+	if (data & TILE_FLIP_HORIZONTAL) != 0 {
+		flags |= FLIP_HORIZONTAL
+	}
+	if (data & TILE_FLIP_VERTICAL) != 0 {
+		flags |= FLIP_VERTICAL
+	}
+	if (data & TILE_FLIP_DIAGONAL) != 0 {
+		flags |= FLIP_DIAGONAL
+		// According to Tiled docs, diagonal flip swaps horizontal and vertical flips
+		// See: https://doc.mapeditor.org/en/stable/reference/global-tile-ids/#tile-flipping
+		if flags&(FLIP_HORIZONTAL|FLIP_VERTICAL) != 0 {
+			flags ^= FLIP_HORIZONTAL | FLIP_VERTICAL
+		}
+	}
+	if (data & TILE_FLIP_ROTATED_HEX) != 0 {
+		flags |= FLIP_ROTATED_HEX
+	}
+	return flags
+}
 
 // ======================================================
 // String Attribute
@@ -100,6 +187,96 @@ func (i AttrInt) String() string {
 	return fmt.Sprintf("%d", i)
 }
 
+// ======================================================
+// Float Attribute
+// ======================================================
+
+type AttrFloat float64
+
+func UnmarshalAttrFloat(s string) (AttrFloat, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float attribute: %s", s)
+	}
+	return AttrFloat(v), nil
+}
+
+func (f AttrFloat) Float() float64 {
+	return float64(f)
+}
+
+func (f AttrFloat) String() string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 64)
+}
+
+// ======================================================
+// Number Attribute
+// ======================================================
+
+// UnmarshalAttrNumber parses attributes Tiled writes as integers or floats depending on the element,
+// such as x and y: whole numbers become an AttrInt, anything else an AttrFloat.
+func UnmarshalAttrNumber(s string) (TiledXMLAttr, error) {
+	if v, err := strconv.Atoi(s); err == nil {
+		return AttrInt(v), nil
+	}
+	return UnmarshalAttrFloat(s)
+}
+
+// numberAttr stores a numeric attribute the way Tiled writes it: whole numbers as integers.
+func numberAttr(v float64) TiledXMLAttr {
+	if v == math.Trunc(v) && math.Abs(v) < math.MaxInt32 {
+		return AttrInt(int(v))
+	}
+	return AttrFloat(v)
+}
+
+// attrNumber returns a numeric attribute as a float, whether it was parsed as an integer or a float.
+func attrNumber(attrs TiledXMLAttrTable, name string) (float64, bool) {
+	switch attr := attrs[name].(type) {
+	case AttrInt:
+		return float64(attr.Int()), true
+	case AttrFloat:
+		return attr.Float(), true
+	}
+	return 0, false
+}
+
+// ======================================================
+// Color Attribute
+// ======================================================
+
+// AttrColor is a color written by Tiled as #RRGGBB or #AARRGGBB.
+type AttrColor color.NRGBA
+
+func UnmarshalAttrColor(s string) (AttrColor, error) {
+	hex := strings.TrimPrefix(s, "#")
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return AttrColor{}, fmt.Errorf("invalid color attribute: %s", s)
+	}
+
+	switch len(hex) {
+	case 6:
+		return AttrColor{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, nil
+	case 8:
+		return AttrColor{A: uint8(v >> 24), R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+	default:
+		return AttrColor{}, fmt.Errorf("invalid color attribute: %s", s)
+	}
+}
+
+func (c AttrColor) Color() color.NRGBA {
+	return color.NRGBA(c)
+}
+
+func (c AttrColor) String() string {
+	if c.A == 0xFF {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.A, c.R, c.G, c.B)
+}
+
 // ======================================================
 // Boolean Attribute
 // ======================================================
@@ -139,32 +316,63 @@ type TiledXMLAttr interface {
 type TiledXMLAttrTable map[string]TiledXMLAttr
 
 const (
+	BoldAttr            = "bold"
+	ClassAttr           = "class"
+	ColorAttr           = "color"
 	ColumnsAttr         = "columns"
+	CompressionAttr     = "compression"
+	DrawOrderAttr       = "draworder"
+	DurationAttr        = "duration"
 	EncodingAttr        = "encoding"
 	FirstGIDAttr        = "firstgid"
+	FontFamilyAttr      = "fontfamily"
 	GIDAttr             = "gid"
+	HAlignAttr          = "halign"
 	HeightAttr          = "height"
+	HexSideLengthAttr   = "hexsidelength"
 	IDAttr              = "id"
 	InfiniteAttr        = "infinite"
+	ItalicAttr          = "italic"
+	KerningAttr         = "kerning"
 	LockedAttr          = "locked"
 	NameAttr            = "name"
 	NextLayerIDAttr     = "nextlayerid"
 	NextObjectIDAttr    = "nextobjectid"
 	ObjectAlignmentAttr = "objectalignment"
+	OffsetXAttr         = "offsetx"
+	OffsetYAttr         = "offsety"
+	OpacityAttr         = "opacity"
 	OrientationAttr     = "orientation"
+	ParallaxXAttr       = "parallaxx"
+	ParallaxYAttr       = "parallaxy"
+	PixelSizeAttr       = "pixelsize"
+	PointsAttr          = "points"
 	PropertyTypeAttr    = "propertytype"
 	RenderOrderAttr     = "renderorder"
+	RepeatXAttr         = "repeatx"
+	RepeatYAttr         = "repeaty"
+	RotationAttr        = "rotation"
 	SourceAttr          = "source"
 	SpacingAttr         = "spacing"
+	StaggerAxisAttr     = "staggeraxis"
+	StaggerIndexAttr    = "staggerindex"
+	StrikeoutAttr       = "strikeout"
 	TemplateAttr        = "template"
 	TileCountAttr       = "tilecount"
 	TileHeightAttr      = "tileheight"
+	TileIDAttr          = "tileid"
 	TileWidthAttr       = "tilewidth"
+	TintColorAttr       = "tintcolor"
 	TiledVersionAttr    = "tiledversion"
+	TransAttr           = "trans"
+	TypeAttr            = "type"
+	UnderlineAttr       = "underline"
+	VAlignAttr          = "valign"
 	ValueAttr           = "value"
 	VersionAttr         = "version"
 	VisibleAttr         = "visible"
 	WidthAttr           = "width"
+	WrapAttr            = "wrap"
 	XAttr               = "x"
 	YAttr               = "y"
 )
@@ -177,33 +385,67 @@ var attr_unmarshallers = map[string]func(s string) (TiledXMLAttr, error){
 	NameAttr:            func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
 	SourceAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
 	EncodingAttr:        func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	CompressionAttr:     func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
 	PropertyTypeAttr:    func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
 	ValueAttr:           func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
 	TemplateAttr:        func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
 	ObjectAlignmentAttr: func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	PointsAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	ClassAttr:           func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	TypeAttr:            func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	DrawOrderAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	StaggerAxisAttr:     func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	StaggerIndexAttr:    func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	HexSideLengthAttr:   func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	InfiniteAttr:        func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
 	VisibleAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
 	LockedAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	TintColorAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrColor(s) },
+	ColorAttr:           func(s string) (TiledXMLAttr, error) { return UnmarshalAttrColor(s) },
+	TransAttr:           func(s string) (TiledXMLAttr, error) { return UnmarshalAttrColor(s) },
+	FontFamilyAttr:      func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	HAlignAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	VAlignAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrString(s) },
+	PixelSizeAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
+	WrapAttr:            func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	BoldAttr:            func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	ItalicAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	UnderlineAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	StrikeoutAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	KerningAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	RepeatXAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
+	RepeatYAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrBool(s) },
 	GIDAttr:             func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
-	WidthAttr:           func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
-	HeightAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
+	WidthAttr:           func(s string) (TiledXMLAttr, error) { return UnmarshalAttrNumber(s) },
+	HeightAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrNumber(s) },
 	TileWidthAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	TileHeightAttr:      func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	SpacingAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	TileCountAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
+	TileIDAttr:          func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
+	DurationAttr:        func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	ColumnsAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	FirstGIDAttr:        func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	IDAttr:              func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
-	XAttr:               func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
-	YAttr:               func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
+	XAttr:               func(s string) (TiledXMLAttr, error) { return UnmarshalAttrNumber(s) },
+	YAttr:               func(s string) (TiledXMLAttr, error) { return UnmarshalAttrNumber(s) },
 	NextLayerIDAttr:     func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
 	NextObjectIDAttr:    func(s string) (TiledXMLAttr, error) { return UnmarshalAttrInt(s) },
+	OffsetXAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrFloat(s) },
+	OffsetYAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrFloat(s) },
+	ParallaxXAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrFloat(s) },
+	ParallaxYAttr:       func(s string) (TiledXMLAttr, error) { return UnmarshalAttrFloat(s) },
+	RotationAttr:        func(s string) (TiledXMLAttr, error) { return UnmarshalAttrFloat(s) },
+	OpacityAttr:         func(s string) (TiledXMLAttr, error) { return UnmarshalAttrFloat(s) },
 }
 
 func (m *TiledXMLAttrTable) UnmarshalXMLAttr(attr xml.Attr) error {
 	unmarshal, ok := attr_unmarshallers[attr.Name.Local]
 
 	if !ok {
+		if CurrentConfig().StrictParsing {
+			return fmt.Errorf("unknown attribute: %s", attr.Name.Local)
+		}
 		println("TiledXMLAttrTable:UnmarshalXMLAttr - unknown attribute:", attr.Name.Local)
 		return nil
 	}
@@ -231,21 +473,21 @@ type Offset struct {
 }
 
 func (offset Offset) X() int {
-	if x, exists := offset.Attrs[XAttr]; exists {
-		if attr, ok := x.(AttrInt); ok {
-			return attr.Int()
-		}
-	}
-	return 0
+	return int(offset.X64())
 }
 
 func (offset Offset) Y() int {
-	if y, exists := offset.Attrs[YAttr]; exists {
-		if attr, ok := y.(AttrInt); ok {
-			return attr.Int()
-		}
-	}
-	return 0
+	return int(offset.Y64())
+}
+
+func (offset Offset) X64() float64 {
+	x, _ := attrNumber(offset.Attrs, XAttr)
+	return x
+}
+
+func (offset Offset) Y64() float64 {
+	y, _ := attrNumber(offset.Attrs, YAttr)
+	return y
 }
 
 // ======================================================
@@ -337,6 +579,35 @@ func (oa ObjectAlignment) IsValid() bool {
 	return oa >= ObjectAlignmentUnspecified && oa <= ObjectAlignmentBottomRight
 }
 
+// Anchor returns the point of a tile object that sits at the object's position, as a fraction of
+// the object's size from its top-left corner. Unspecified alignment anchors at the bottom-left,
+// or the bottom center for isometric maps, like Tiled does.
+func (oa ObjectAlignment) Anchor(orientation Orientation) geom.Point64 {
+	switch oa {
+	case ObjectAlignmentTopLeft:
+		return geom.NewPoint64(0, 0)
+	case ObjectAlignmentTop:
+		return geom.NewPoint64(0.5, 0)
+	case ObjectAlignmentTopRight:
+		return geom.NewPoint64(1, 0)
+	case ObjectAlignmentLeft:
+		return geom.NewPoint64(0, 0.5)
+	case ObjectAlignmentCenter:
+		return geom.NewPoint64(0.5, 0.5)
+	case ObjectAlignmentRight:
+		return geom.NewPoint64(1, 0.5)
+	case ObjectAlignmentBottom:
+		return geom.NewPoint64(0.5, 1)
+	case ObjectAlignmentBottomRight:
+		return geom.NewPoint64(1, 1)
+	case ObjectAlignmentUnspecified:
+		if orientation == Isometric {
+			return geom.NewPoint64(0.5, 1)
+		}
+	}
+	return geom.NewPoint64(0, 1)
+}
+
 func (oa ObjectAlignment) MarshalJSON() ([]byte, error) {
 	return enum.MarshalEnum(oa)
 }
@@ -350,6 +621,45 @@ func (oa *ObjectAlignment) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ======================================================
+// Draw Order
+// ======================================================
+
+type DrawOrder int
+
+const (
+	DrawOrderTopDown DrawOrder = iota
+	DrawOrderIndex
+)
+
+func (do DrawOrder) String() string {
+	switch do {
+	case DrawOrderTopDown:
+		return "topdown"
+	case DrawOrderIndex:
+		return "index"
+	default:
+		return "unknown"
+	}
+}
+
+func (do DrawOrder) IsValid() bool {
+	return do >= DrawOrderTopDown && do <= DrawOrderIndex
+}
+
+func (do DrawOrder) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(do)
+}
+
+func (do *DrawOrder) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[DrawOrder](data)
+	if err != nil {
+		return err
+	}
+	*do = val
+	return nil
+}
+
 // ======================================================
 // Orientation
 // ======================================================
@@ -395,6 +705,86 @@ func (o *Orientation) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ======================================================
+// Stagger Axis
+// ======================================================
+
+// StaggerAxis is the axis along which every other row or column of a staggered or hexagonal map is shifted.
+type StaggerAxis int
+
+const (
+	StaggerAxisY StaggerAxis = iota
+	StaggerAxisX
+)
+
+func (sa StaggerAxis) String() string {
+	switch sa {
+	case StaggerAxisY:
+		return "y"
+	case StaggerAxisX:
+		return "x"
+	default:
+		return "unknown"
+	}
+}
+
+func (sa StaggerAxis) IsValid() bool {
+	return sa >= StaggerAxisY && sa <= StaggerAxisX
+}
+
+func (sa StaggerAxis) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(sa)
+}
+
+func (sa *StaggerAxis) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[StaggerAxis](data)
+	if err != nil {
+		return err
+	}
+	*sa = val
+	return nil
+}
+
+// ======================================================
+// Stagger Index
+// ======================================================
+
+// StaggerIndex selects whether the odd or the even rows or columns of a staggered or hexagonal map are shifted.
+type StaggerIndex int
+
+const (
+	StaggerIndexOdd StaggerIndex = iota
+	StaggerIndexEven
+)
+
+func (si StaggerIndex) String() string {
+	switch si {
+	case StaggerIndexOdd:
+		return "odd"
+	case StaggerIndexEven:
+		return "even"
+	default:
+		return "unknown"
+	}
+}
+
+func (si StaggerIndex) IsValid() bool {
+	return si >= StaggerIndexOdd && si <= StaggerIndexEven
+}
+
+func (si StaggerIndex) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(si)
+}
+
+func (si *StaggerIndex) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[StaggerIndex](data)
+	if err != nil {
+		return err
+	}
+	*si = val
+	return nil
+}
+
 // ======================================================
 // Render Order
 // ======================================================
@@ -446,6 +836,9 @@ func (ro *RenderOrder) UnmarshalJSON(data []byte) error {
 
 type Image struct {
 	Attrs TiledXMLAttrTable `xml:",any,attr"`
+
+	// File set the image is loaded from, if its file was loaded with LoadTMX or LoadTSX.
+	files *fileSet
 }
 
 func (img Image) Source() string {
@@ -475,6 +868,104 @@ func (img Image) Height() int {
 	return 0
 }
 
+// Trans returns the color drawn as transparent in the image, if it has one.
+func (img Image) Trans() (color.NRGBA, bool) {
+	if trans, exists := img.Attrs[TransAttr]; exists {
+		if attr, ok := trans.(AttrColor); ok {
+			return attr.Color(), true
+		}
+	}
+	return color.NRGBA{}, false
+}
+
+// Resolve returns the decoded image file the element references. Images of files loaded with
+// LoadTMXWithImages resolve within the file system they were loaded from, others through the Resolver.
+func (img Image) Resolve() (image.Image, error) {
+	return lookupImage(img.files, img.Source())
+}
+
+// ======================================================
+// Tileset Tile
+// ======================================================
+
+type TilesetTile struct {
+	Attrs       TiledXMLAttrTable `xml:",any,attr"`
+	Image       *Image            `xml:"image"`
+	Properties  []*Property       `xml:"properties>property"`
+	ObjectGroup *ObjectGroup      `xml:"objectgroup"`
+	Animation   []*Frame          `xml:"animation>frame"`
+}
+
+func (tile TilesetTile) ID() uint32 {
+	if id, exists := tile.Attrs[IDAttr]; exists {
+		if attr, ok := id.(AttrInt); ok {
+			return uint32(attr.Int())
+		}
+	}
+	return 0
+}
+
+// Class returns the tile's class, falling back to the type attribute used before Tiled 1.9.
+func (tile TilesetTile) Class() string {
+	for _, name := range []string{ClassAttr, TypeAttr} {
+		if class, exists := tile.Attrs[name]; exists {
+			if attr, ok := class.(AttrString); ok {
+				return attr.String()
+			}
+		}
+	}
+	return ""
+}
+
+func (tile TilesetTile) PropertyOfType(ptype string) (*Property, bool) {
+	for _, prop := range tile.Properties {
+		if prop.PropertyType() == ptype {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+func (tile TilesetTile) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(tile.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (tile TilesetTile) HasProperty(name string) bool {
+	_, exists := tile.PropertyByName(name)
+	return exists
+}
+
+// ======================================================
+// Animation Frame
+// ======================================================
+
+// Frame is one frame of an animated tile: the tile of the same tileset shown, and for how long.
+type Frame struct {
+	Attrs TiledXMLAttrTable `xml:",any,attr"`
+}
+
+func (frame Frame) TileID() uint32 {
+	if id, exists := frame.Attrs[TileIDAttr]; exists {
+		if attr, ok := id.(AttrInt); ok {
+			return uint32(attr.Int())
+		}
+	}
+	return 0
+}
+
+// Duration returns how long the frame is shown.
+func (frame Frame) Duration() time.Duration {
+	if duration, exists := frame.Attrs[DurationAttr]; exists {
+		if attr, ok := duration.(AttrInt); ok {
+			return time.Duration(attr.Int()) * time.Millisecond
+		}
+	}
+	return 0
+}
+
 // ======================================================
 // Layer Data
 // ======================================================
@@ -498,6 +989,19 @@ func (data LayerData) Encoding() Encoding {
 	return TMXEncodingCSV
 }
 
+func (data LayerData) Compression() Compression {
+	if compression, exists := data.Attrs[CompressionAttr]; exists {
+		if attr, ok := compression.(AttrString); ok {
+			e, err := enum.Value[Compression](attr.String())
+			if err != nil {
+				panic(err)
+			}
+			return e
+		}
+	}
+	return TMXCompressionNone
+}
+
 // ======================================================
 // Data Chunk
 // ======================================================
@@ -562,8 +1066,9 @@ type Layer struct {
 	Properties []*Property       `xml:"properties>property"`
 
 	// Should these be stored here? Don't serialize them!
-	tiles      []*Tile
-	partitions LayerPartitions
+	grids      []*cellGrid // Indexed like the layer's chunks; blocks that are not decoded are nil.
+	gridDrops  int         // Counts decoded blocks being dropped, so what was built from them is dropped too.
+	generation int
 }
 
 func (layer Layer) ID() int {
@@ -611,6 +1116,62 @@ func (layer Layer) IsVisible() bool {
 	return true
 }
 
+func (layer Layer) Opacity() float64 {
+	if opacity, exists := layer.Attrs[OpacityAttr]; exists {
+		if attr, ok := opacity.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 1
+}
+
+func (layer Layer) OffsetX() float64 {
+	if x, exists := layer.Attrs[OffsetXAttr]; exists {
+		if attr, ok := x.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 0
+}
+
+func (layer Layer) OffsetY() float64 {
+	if y, exists := layer.Attrs[OffsetYAttr]; exists {
+		if attr, ok := y.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 0
+}
+
+// ParallaxX returns the layer's horizontal scroll factor relative to the camera, defaulting to 1.
+func (layer Layer) ParallaxX() float64 {
+	if x, exists := layer.Attrs[ParallaxXAttr]; exists {
+		if attr, ok := x.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 1
+}
+
+// ParallaxY returns the layer's vertical scroll factor relative to the camera, defaulting to 1.
+func (layer Layer) ParallaxY() float64 {
+	if y, exists := layer.Attrs[ParallaxYAttr]; exists {
+		if attr, ok := y.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 1
+}
+
+func (layer Layer) TintColor() color.NRGBA {
+	if tint, exists := layer.Attrs[TintColorAttr]; exists {
+		if attr, ok := tint.(AttrColor); ok {
+			return attr.Color()
+		}
+	}
+	return color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+}
+
 func (layer Layer) Bounds() geom.Rect64 {
 	if layer.Data == nil {
 		return geom.Rect64{}
@@ -636,9 +1197,145 @@ func (layer Layer) PropertyOfType(ptype string) (*Property, bool) {
 	return nil, false
 }
 
-// ======================================================
-// Property
-// ======================================================
+func (layer Layer) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(layer.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (layer Layer) HasProperty(name string) bool {
+	_, exists := layer.PropertyByName(name)
+	return exists
+}
+
+// ======================================================
+// Image Layer
+// ======================================================
+
+type ImageLayer struct {
+	Attrs      TiledXMLAttrTable `xml:",any,attr"`
+	Image      *Image            `xml:"image"`
+	Properties []*Property       `xml:"properties>property"`
+}
+
+func (il ImageLayer) ID() int {
+	if id, exists := il.Attrs[IDAttr]; exists {
+		if attr, ok := id.(AttrInt); ok {
+			return attr.Int()
+		}
+	}
+	return 0
+}
+
+func (il ImageLayer) Name() string {
+	if name, exists := il.Attrs[NameAttr]; exists {
+		if attr, ok := name.(AttrString); ok {
+			return attr.String()
+		}
+	}
+	return ""
+}
+
+func (il ImageLayer) OffsetX() float64 {
+	if x, exists := il.Attrs[OffsetXAttr]; exists {
+		if attr, ok := x.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 0
+}
+
+func (il ImageLayer) OffsetY() float64 {
+	if y, exists := il.Attrs[OffsetYAttr]; exists {
+		if attr, ok := y.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 0
+}
+
+func (il ImageLayer) Opacity() float64 {
+	if opacity, exists := il.Attrs[OpacityAttr]; exists {
+		if attr, ok := opacity.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 1
+}
+
+func (il ImageLayer) IsVisible() bool {
+	if visible, exists := il.Attrs[VisibleAttr]; exists {
+		if attr, ok := visible.(AttrBool); ok {
+			return attr.Bool()
+		}
+	}
+	return true
+}
+
+func (il ImageLayer) TintColor() color.NRGBA {
+	if tint, exists := il.Attrs[TintColorAttr]; exists {
+		if attr, ok := tint.(AttrColor); ok {
+			return attr.Color()
+		}
+	}
+	return color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+}
+
+func (il ImageLayer) RepeatX() bool {
+	if repeat, exists := il.Attrs[RepeatXAttr]; exists {
+		if attr, ok := repeat.(AttrBool); ok {
+			return attr.Bool()
+		}
+	}
+	return false
+}
+
+func (il ImageLayer) RepeatY() bool {
+	if repeat, exists := il.Attrs[RepeatYAttr]; exists {
+		if attr, ok := repeat.(AttrBool); ok {
+			return attr.Bool()
+		}
+	}
+	return false
+}
+
+func (il ImageLayer) PropertyOfType(ptype string) (*Property, bool) {
+	for _, prop := range il.Properties {
+		if prop.PropertyType() == ptype {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+func (il ImageLayer) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(il.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (il ImageLayer) HasProperty(name string) bool {
+	_, exists := il.PropertyByName(name)
+	return exists
+}
+
+// ======================================================
+// Property
+// ======================================================
+
+// Value types a property can declare.
+const (
+	StringPropertyType = "string"
+	IntPropertyType    = "int"
+	FloatPropertyType  = "float"
+	BoolPropertyType   = "bool"
+	ColorPropertyType  = "color"
+	FilePropertyType   = "file"
+	ObjectPropertyType = "object"
+	ClassPropertyType  = "class"
+)
 
 type Property struct {
 	Attrs      TiledXMLAttrTable `xml:",any,attr"`
@@ -654,13 +1351,14 @@ func (prop Property) Name() string {
 	return ""
 }
 
+// Type returns the declared type of the property's value, one of the *PropertyType constants.
 func (prop Property) Type() string {
-	if ptype, exists := prop.Attrs[PropertyTypeAttr]; exists {
+	if ptype, exists := prop.Attrs[TypeAttr]; exists {
 		if attr, ok := ptype.(AttrString); ok {
 			return attr.String()
 		}
 	}
-	return "string"
+	return StringPropertyType
 }
 
 func (prop Property) Value() string {
@@ -672,6 +1370,90 @@ func (prop Property) Value() string {
 	return ""
 }
 
+// Int returns the value of an int property.
+func (prop Property) Int() (int, error) {
+	if err := prop.expectType(IntPropertyType); err != nil {
+		return 0, err
+	}
+	v, err := strconv.Atoi(prop.Value())
+	if err != nil {
+		return 0, fmt.Errorf("invalid int property %s: %s", prop.Name(), prop.Value())
+	}
+	return v, nil
+}
+
+// Float returns the value of a float property. Int properties are accepted as well.
+func (prop Property) Float() (float64, error) {
+	if prop.Type() != IntPropertyType {
+		if err := prop.expectType(FloatPropertyType); err != nil {
+			return 0, err
+		}
+	}
+	v, err := strconv.ParseFloat(prop.Value(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float property %s: %s", prop.Name(), prop.Value())
+	}
+	return v, nil
+}
+
+// Bool returns the value of a bool property.
+func (prop Property) Bool() (bool, error) {
+	if err := prop.expectType(BoolPropertyType); err != nil {
+		return false, err
+	}
+	b, err := UnmarshalAttrBool(prop.Value())
+	if err != nil {
+		return false, fmt.Errorf("invalid bool property %s: %s", prop.Name(), prop.Value())
+	}
+	return b.Bool(), nil
+}
+
+// Color returns the value of a color property. An unset color is fully transparent.
+func (prop Property) Color() (color.NRGBA, error) {
+	if err := prop.expectType(ColorPropertyType); err != nil {
+		return color.NRGBA{}, err
+	}
+	if prop.Value() == "" {
+		return color.NRGBA{}, nil
+	}
+	c, err := UnmarshalAttrColor(prop.Value())
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color property %s: %s", prop.Name(), prop.Value())
+	}
+	return c.Color(), nil
+}
+
+// FileRef returns the path held by a file property. Properties of loaded assets hold paths
+// already resolved against the directory of the file they appear in.
+func (prop Property) FileRef() (string, error) {
+	if err := prop.expectType(FilePropertyType); err != nil {
+		return "", err
+	}
+	return prop.Value(), nil
+}
+
+// ObjectRef returns the ID of the object referenced by an object property, or 0 if none is set.
+func (prop Property) ObjectRef() (int, error) {
+	if err := prop.expectType(ObjectPropertyType); err != nil {
+		return 0, err
+	}
+	if prop.Value() == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(prop.Value())
+	if err != nil {
+		return 0, fmt.Errorf("invalid object property %s: %s", prop.Name(), prop.Value())
+	}
+	return id, nil
+}
+
+func (prop Property) expectType(ptype string) error {
+	if prop.Type() != ptype {
+		return fmt.Errorf("property %s is %s, not %s", prop.Name(), prop.Type(), ptype)
+	}
+	return nil
+}
+
 func (prop Property) PropertyType() string {
 	if ptype, exists := prop.Attrs[PropertyTypeAttr]; exists {
 		if attr, ok := ptype.(AttrString); ok {
@@ -690,6 +1472,27 @@ func (prop Property) PropertyOfType(ptype string) (*Property, bool) {
 	return nil, false
 }
 
+func (prop Property) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(prop.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (prop Property) HasProperty(name string) bool {
+	_, exists := prop.PropertyByName(name)
+	return exists
+}
+
+func findProperty(props []*Property, name string) *Property {
+	for _, prop := range props {
+		if prop.Name() == name {
+			return prop
+		}
+	}
+	return nil
+}
+
 // ======================================================
 // ObjectGroups
 // ======================================================
@@ -718,6 +1521,61 @@ func (og ObjectGroup) Name() string {
 	return ""
 }
 
+// Color returns the color objects of the group are displayed with in the editor,
+// defaulting to the editor's gray.
+func (og ObjectGroup) Color() color.NRGBA {
+	if c, exists := og.Attrs[ColorAttr]; exists {
+		if attr, ok := c.(AttrColor); ok {
+			return attr.Color()
+		}
+	}
+	return color.NRGBA{R: 0xA0, G: 0xA0, B: 0xA4, A: 0xFF}
+}
+
+func (og ObjectGroup) IsVisible() bool {
+	if visible, exists := og.Attrs[VisibleAttr]; exists {
+		if attr, ok := visible.(AttrBool); ok {
+			return attr.Bool()
+		}
+	}
+	return true
+}
+
+func (og ObjectGroup) Opacity() float64 {
+	if opacity, exists := og.Attrs[OpacityAttr]; exists {
+		if attr, ok := opacity.(AttrFloat); ok {
+			return attr.Float()
+		}
+	}
+	return 1
+}
+
+// DrawOrder returns the order the group's objects are drawn in, defaulting to topdown like Tiled.
+func (og ObjectGroup) DrawOrder() DrawOrder {
+	if drawOrder, exists := og.Attrs[DrawOrderAttr]; exists {
+		if attr, ok := drawOrder.(AttrString); ok {
+			e, err := enum.Value[DrawOrder](attr.String())
+			if err != nil {
+				panic(err)
+			}
+			return e
+		}
+	}
+	return DrawOrderTopDown
+}
+
+// OrderedObjects returns the group's objects in draw order: by Y for topdown groups,
+// keeping document order between objects at the same Y, or in document order for index groups.
+func (og ObjectGroup) OrderedObjects() []*Object {
+	objects := slices.Clone(og.Objects)
+	if og.DrawOrder() == DrawOrderTopDown {
+		slices.SortStableFunc(objects, func(a, b *Object) int {
+			return cmp.Compare(a.Y64(), b.Y64())
+		})
+	}
+	return objects
+}
+
 func (og ObjectGroup) PropertyOfType(ptype string) (*Property, bool) {
 	for _, prop := range og.Properties {
 		if prop.PropertyType() == ptype {
@@ -727,6 +1585,18 @@ func (og ObjectGroup) PropertyOfType(ptype string) (*Property, bool) {
 	return nil, false
 }
 
+func (og ObjectGroup) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(og.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (og ObjectGroup) HasProperty(name string) bool {
+	_, exists := og.PropertyByName(name)
+	return exists
+}
+
 // ======================================================
 // Object
 // ======================================================
@@ -735,8 +1605,19 @@ type Object struct {
 	Attrs      TiledXMLAttrTable `xml:",any,attr"`
 	Properties []*Property       `xml:"properties>property"`
 	Tileset    *Tileset          `xml:"tileset"`
+	Polygon    *Polyline         `xml:"polygon"`
+	Polyline   *Polyline         `xml:"polyline"`
+	Ellipse    *Marker           `xml:"ellipse"`
+	Point      *Marker           `xml:"point"`
+	Text       *TextObject       `xml:"text"`
+
+	// File set the object's template is loaded from, if its map was loaded with LoadTMX.
+	files *fileSet
+}
 
-	tile *Tile
+// TX returns the template the object is an instance of.
+func (obj Object) TX() (*TX, error) {
+	return lookupTX(obj.files, obj.Template())
 }
 
 func (obj Object) ID() int {
@@ -758,36 +1639,46 @@ func (obj Object) GID() int {
 }
 
 func (obj Object) X() int {
-	if x, exists := obj.Attrs[XAttr]; exists {
-		if attr, ok := x.(AttrInt); ok {
-			return attr.Int()
-		}
-	}
-	return 0
+	return int(obj.X64())
+}
+
+func (obj Object) X64() float64 {
+	x, _ := attrNumber(obj.Attrs, XAttr)
+	return x
 }
 
 func (obj Object) Y() int {
-	if y, exists := obj.Attrs[YAttr]; exists {
-		if attr, ok := y.(AttrInt); ok {
-			return attr.Int()
-		}
-	}
-	return 0
+	return int(obj.Y64())
+}
+
+func (obj Object) Y64() float64 {
+	y, _ := attrNumber(obj.Attrs, YAttr)
+	return y
 }
 
 func (obj Object) Width() int {
-	if width, exists := obj.Attrs[WidthAttr]; exists {
-		if attr, ok := width.(AttrInt); ok {
-			return attr.Int()
-		}
-	}
-	return 0
+	return int(obj.Width64())
+}
+
+func (obj Object) Width64() float64 {
+	width, _ := attrNumber(obj.Attrs, WidthAttr)
+	return width
 }
 
 func (obj Object) Height() int {
-	if height, exists := obj.Attrs[HeightAttr]; exists {
-		if attr, ok := height.(AttrInt); ok {
-			return attr.Int()
+	return int(obj.Height64())
+}
+
+func (obj Object) Height64() float64 {
+	height, _ := attrNumber(obj.Attrs, HeightAttr)
+	return height
+}
+
+// Rotation returns the object's clockwise rotation around its position, in degrees.
+func (obj Object) Rotation() float64 {
+	if rotation, exists := obj.Attrs[RotationAttr]; exists {
+		if attr, ok := rotation.(AttrFloat); ok {
+			return attr.Float()
 		}
 	}
 	return 0
@@ -802,6 +1693,18 @@ func (obj Object) Name() string {
 	return ""
 }
 
+// Class returns the object's class, falling back to the type attribute used before Tiled 1.9.
+func (obj Object) Class() string {
+	for _, name := range []string{ClassAttr, TypeAttr} {
+		if class, exists := obj.Attrs[name]; exists {
+			if attr, ok := class.(AttrString); ok {
+				return attr.String()
+			}
+		}
+	}
+	return ""
+}
+
 func (obj Object) Template() string {
 	if template, exists := obj.Attrs[TemplateAttr]; exists {
 		if attr, ok := template.(AttrString); ok {
@@ -820,16 +1723,330 @@ func (obj Object) PropertyOfType(ptype string) (*Property, bool) {
 	return nil, false
 }
 
+func (obj Object) PropertyByName(name string) (*Property, bool) {
+	if prop := findProperty(obj.Properties, name); prop != nil {
+		return prop, true
+	}
+	return nil, false
+}
+
+func (obj Object) HasProperty(name string) bool {
+	_, exists := obj.PropertyByName(name)
+	return exists
+}
+
 func (obj Object) HasTemplate() bool {
 	return obj.Template() != ""
 }
 
+func (obj Object) IsVisible() bool {
+	if visible, exists := obj.Attrs[VisibleAttr]; exists {
+		if attr, ok := visible.(AttrBool); ok {
+			return attr.Bool()
+		}
+	}
+	return true
+}
+
+// Shape reports which kind of shape the object is, from the child element Tiled wrote for it.
+// Objects without one, including tile objects, are rectangles.
+func (obj Object) Shape() ObjectShape {
+	switch {
+	case obj.Ellipse != nil:
+		return ObjectShapeEllipse
+	case obj.Point != nil:
+		return ObjectShapePoint
+	case obj.Polygon != nil:
+		return ObjectShapePolygon
+	case obj.Polyline != nil:
+		return ObjectShapePolyline
+	case obj.Text != nil:
+		return ObjectShapeText
+	default:
+		return ObjectShapeRect
+	}
+}
+
+// ======================================================
+// Object Shape
+// ======================================================
+
+type ObjectShape int
+
+const (
+	ObjectShapeRect ObjectShape = iota
+	ObjectShapeEllipse
+	ObjectShapePolygon
+	ObjectShapePolyline
+	ObjectShapePoint
+	ObjectShapeText
+)
+
+func (shape ObjectShape) String() string {
+	switch shape {
+	case ObjectShapeRect:
+		return "rect"
+	case ObjectShapeEllipse:
+		return "ellipse"
+	case ObjectShapePolygon:
+		return "polygon"
+	case ObjectShapePolyline:
+		return "polyline"
+	case ObjectShapePoint:
+		return "point"
+	case ObjectShapeText:
+		return "text"
+	default:
+		return "unknown"
+	}
+}
+
+func (shape ObjectShape) IsValid() bool {
+	return shape >= ObjectShapeRect && shape <= ObjectShapeText
+}
+
+func (shape ObjectShape) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(shape)
+}
+
+func (shape *ObjectShape) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[ObjectShape](data)
+	if err != nil {
+		return err
+	}
+	*shape = val
+	return nil
+}
+
+// ======================================================
+// Text Object
+// ======================================================
+
+type TextHAlign int
+
+const (
+	TextAlignLeft TextHAlign = iota
+	TextAlignCenter
+	TextAlignRight
+	TextAlignJustify
+)
+
+func (a TextHAlign) String() string {
+	switch a {
+	case TextAlignLeft:
+		return "left"
+	case TextAlignCenter:
+		return "center"
+	case TextAlignRight:
+		return "right"
+	case TextAlignJustify:
+		return "justify"
+	default:
+		return "unknown"
+	}
+}
+
+func (a TextHAlign) IsValid() bool {
+	return a >= TextAlignLeft && a <= TextAlignJustify
+}
+
+func (a TextHAlign) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(a)
+}
+
+func (a *TextHAlign) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[TextHAlign](data)
+	if err != nil {
+		return err
+	}
+	*a = val
+	return nil
+}
+
+type TextVAlign int
+
+const (
+	TextAlignTop TextVAlign = iota
+	TextAlignMiddle
+	TextAlignBottom
+)
+
+func (a TextVAlign) String() string {
+	switch a {
+	case TextAlignTop:
+		return "top"
+	case TextAlignMiddle:
+		return "center"
+	case TextAlignBottom:
+		return "bottom"
+	default:
+		return "unknown"
+	}
+}
+
+func (a TextVAlign) IsValid() bool {
+	return a >= TextAlignTop && a <= TextAlignBottom
+}
+
+func (a TextVAlign) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(a)
+}
+
+func (a *TextVAlign) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[TextVAlign](data)
+	if err != nil {
+		return err
+	}
+	*a = val
+	return nil
+}
+
+// TextObject is the text of a text object, such as a label or a sign.
+type TextObject struct {
+	Attrs TiledXMLAttrTable `xml:",any,attr"`
+	Text  string            `xml:",chardata"`
+}
+
+func (t TextObject) FontFamily() string {
+	if family, exists := t.Attrs[FontFamilyAttr]; exists {
+		if attr, ok := family.(AttrString); ok {
+			return attr.String()
+		}
+	}
+	return "sans-serif"
+}
+
+func (t TextObject) PixelSize() int {
+	if size, exists := t.Attrs[PixelSizeAttr]; exists {
+		if attr, ok := size.(AttrInt); ok {
+			return attr.Int()
+		}
+	}
+	return 16
+}
+
+func (t TextObject) Color() color.NRGBA {
+	if c, exists := t.Attrs[ColorAttr]; exists {
+		if attr, ok := c.(AttrColor); ok {
+			return attr.Color()
+		}
+	}
+	return color.NRGBA{A: 0xFF}
+}
+
+func (t TextObject) HAlign() TextHAlign {
+	if halign, exists := t.Attrs[HAlignAttr]; exists {
+		if attr, ok := halign.(AttrString); ok {
+			if a, err := enum.Value[TextHAlign](attr.String()); err == nil {
+				return a
+			}
+		}
+	}
+	return TextAlignLeft
+}
+
+func (t TextObject) VAlign() TextVAlign {
+	if valign, exists := t.Attrs[VAlignAttr]; exists {
+		if attr, ok := valign.(AttrString); ok {
+			if a, err := enum.Value[TextVAlign](attr.String()); err == nil {
+				return a
+			}
+		}
+	}
+	return TextAlignTop
+}
+
+func (t TextObject) Wrap() bool      { return t.flag(WrapAttr, false) }
+func (t TextObject) Bold() bool      { return t.flag(BoldAttr, false) }
+func (t TextObject) Italic() bool    { return t.flag(ItalicAttr, false) }
+func (t TextObject) Underline() bool { return t.flag(UnderlineAttr, false) }
+func (t TextObject) Strikeout() bool { return t.flag(StrikeoutAttr, false) }
+func (t TextObject) Kerning() bool   { return t.flag(KerningAttr, true) }
+
+func (t TextObject) flag(name string, fallback bool) bool {
+	if flag, exists := t.Attrs[name]; exists {
+		if attr, ok := flag.(AttrBool); ok {
+			return attr.Bool()
+		}
+	}
+	return fallback
+}
+
+// ======================================================
+// Marker
+// ======================================================
+
+// Marker is an empty element whose presence changes the meaning of its parent, such as an object's <ellipse/>.
+type Marker struct{}
+
+// ======================================================
+// Polyline
+// ======================================================
+
+// Polyline holds the points of a polygon or polyline object, relative to the object's position.
+type Polyline struct {
+	Attrs TiledXMLAttrTable `xml:",any,attr"`
+}
+
+func (pl Polyline) Points() []geom.Point64 {
+	points, exists := pl.Attrs[PointsAttr]
+	if !exists {
+		return nil
+	}
+
+	var result []geom.Point64
+	for _, pair := range strings.Fields(points.String()) {
+		xs, ys, ok := strings.Cut(pair, ",")
+		if !ok {
+			continue
+		}
+		x, errX := strconv.ParseFloat(xs, 64)
+		y, errY := strconv.ParseFloat(ys, 64)
+		if errX != nil || errY != nil {
+			continue
+		}
+		result = append(result, geom.NewPoint64(x, y))
+	}
+	return result
+}
+
 // ======================================================
 // Tileset
 // ======================================================
 
 type Tileset struct {
 	Attrs TiledXMLAttrTable `xml:",any,attr"`
+
+	// Source rectangles of the tileset's tiles by ID, filled in for cooked maps.
+	rects []image.Rectangle
+
+	// File set the tileset is loaded from, if its map or template was loaded with LoadTMX or LoadTX.
+	files *fileSet
+}
+
+// TSX returns the tileset the reference points to.
+func (ts Tileset) TSX() (*TSX, error) {
+	return lookupTSX(ts.files, ts.Source())
+}
+
+// Tile returns the tile with the ID in the tileset, resolving like the tileset does. Its position,
+// size and flags are left zero; TileOf fills them in for a cell.
+func (ts Tileset) Tile(id uint32) Tile {
+	tile := Tile{GID: id, TsxSrc: ts.Source(), files: ts.files}
+	if int(id) < len(ts.rects) {
+		tile.src = ts.rects[id]
+	}
+	return tile
+}
+
+// TilesetOf returns the tileset a GID belongs to, or nil if it is below every tileset's first GID.
+func TilesetOf(gid uint32, tilesets []*Tileset) *Tileset {
+	for j := len(tilesets) - 1; j >= 0; j-- {
+		if gid >= tilesets[j].FirstGID() {
+			return tilesets[j]
+		}
+	}
+	return nil
 }
 
 func (ts Tileset) FirstGID() uint32 {
@@ -0,0 +1,95 @@
+package tiled
+
+import "sort"
+
+// ======================================================
+// Tile Usage
+// ======================================================
+
+// TileKey identifies a tile by its tileset source and local tile ID,
+// so usage can be compared across maps with different GID tables.
+type TileKey struct {
+	Source string
+	ID     uint32
+}
+
+// TileUsage counts how many cells reference each tile.
+type TileUsage map[TileKey]int
+
+// CountTileUsage counts tile references across all tile layers of the provided maps.
+// Passing every map of a project produces project-wide usage.
+func CountTileUsage(maps ...*TMX) (TileUsage, error) {
+	usage := make(TileUsage)
+	for _, tmx := range maps {
+		for _, layer := range tmx.Layers {
+			err := forEachCell(layer, func(x, y int, data uint32) {
+				if key, ok := TileKeyOf(data, tmx.Tilesets); ok {
+					usage[key]++
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return usage, nil
+}
+
+// Max returns the highest usage count.
+func (u TileUsage) Max() int {
+	highest := 0
+	for _, count := range u {
+		if count > highest {
+			highest = count
+		}
+	}
+	return highest
+}
+
+// Unused returns the tiles of the provided tilesets that are never referenced.
+func (u TileUsage) Unused(tilesets []*Tileset) ([]TileKey, error) {
+	var unused []TileKey
+	for _, tileset := range tilesets {
+		tsx, err := tileset.TSX()
+		if err != nil {
+			return nil, err
+		}
+		for id := 0; id < tsx.TileCount(); id++ {
+			key := TileKey{Source: tileset.Source(), ID: uint32(id)}
+			if u[key] == 0 {
+				unused = append(unused, key)
+			}
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].Source != unused[j].Source {
+			return unused[i].Source < unused[j].Source
+		}
+		return unused[i].ID < unused[j].ID
+	})
+	return unused, nil
+}
+
+// TileKeyOf returns the key of the tile a cell's raw data references in a map using the tilesets.
+func TileKeyOf(data uint32, tilesets []*Tileset) (TileKey, bool) {
+	gid := data & TILE_ID_MASK
+	if gid == 0 {
+		return TileKey{}, false
+	}
+	for i := len(tilesets) - 1; i >= 0; i-- {
+		if gid >= tilesets[i].FirstGID() {
+			return TileKey{Source: tilesets[i].Source(), ID: gid - tilesets[i].FirstGID()}, true
+		}
+	}
+	return TileKey{}, false
+}
+
+// gidOf returns the GID that references the tile in a map using the provided tilesets.
+func gidOf(key TileKey, tilesets []*Tileset) (uint32, bool) {
+	for _, ts := range tilesets {
+		if ts.Source() == key.Source {
+			return ts.FirstGID() + key.ID, true
+		}
+	}
+	return 0, false
+}
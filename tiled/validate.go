@@ -0,0 +1,230 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adm87/finch-core/enum"
+)
+
+// ======================================================
+// Validation Issue Kind
+// ======================================================
+
+type IssueKind int
+
+const (
+	IssueMissingTileset IssueKind = iota
+	IssueGIDOutOfRange
+	IssueInvalidData
+	IssueChunkSize
+	IssueMissingTemplate
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueMissingTileset:
+		return "missing-tileset"
+	case IssueGIDOutOfRange:
+		return "gid-out-of-range"
+	case IssueInvalidData:
+		return "invalid-data"
+	case IssueChunkSize:
+		return "chunk-size"
+	case IssueMissingTemplate:
+		return "missing-template"
+	default:
+		return "unknown"
+	}
+}
+
+func (k IssueKind) IsValid() bool {
+	return k >= IssueMissingTileset && k <= IssueMissingTemplate
+}
+
+func (k IssueKind) MarshalJSON() ([]byte, error) {
+	return enum.MarshalEnum(k)
+}
+
+func (k *IssueKind) UnmarshalJSON(data []byte) error {
+	val, err := enum.UnmarshalEnum[IssueKind](data)
+	if err != nil {
+		return err
+	}
+	*k = val
+	return nil
+}
+
+// ======================================================
+// Map Validation
+// ======================================================
+
+// ValidationIssue is a problem found in a map by Validate.
+type ValidationIssue struct {
+	Kind IssueKind `json:"kind"`
+
+	// Layer names the tile layer or object group the issue is in, if any.
+	Layer string `json:"layer,omitempty"`
+
+	// Cell is the first cell with the issue and Count how many cells have it, for issues with cells.
+	Cell  Cell `json:"cell"`
+	Count int  `json:"count,omitempty"`
+
+	// Object is the ID of the object with the issue, for issues with objects.
+	Object int `json:"object,omitempty"`
+
+	// Source is the file the issue is about, such as a tileset or template that failed to load.
+	Source string `json:"source,omitempty"`
+
+	Message string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Layer == "" {
+		return fmt.Sprintf("%s: %s", i.Kind, i.Message)
+	}
+	return fmt.Sprintf("%s: layer %s: %s", i.Kind, i.Layer, i.Message)
+}
+
+// ValidationReport lists the issues Validate found in a map.
+type ValidationReport struct {
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// OK reports whether no issues were found.
+func (r *ValidationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Err returns the issues as an error, or nil if there are none.
+func (r *ValidationReport) Err() error {
+	errs := make([]error, len(r.Issues))
+	for i, issue := range r.Issues {
+		errs[i] = errors.New(issue.String())
+	}
+	return errors.Join(errs...)
+}
+
+func (r *ValidationReport) add(issue ValidationIssue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// Validate checks the map for problems that would otherwise surface mid-draw as a logged error: that
+// its tilesets and templates are loaded, that every tile layer's data decodes to cells of the right
+// count, that chunks are consistently sized, and that every GID, of cells and tile objects alike,
+// falls within a tileset. LoadMapWithDependencies and AcquireMap run it on every map they load; call
+// it directly for maps loaded otherwise, once the map and what it references are loaded.
+func (tmx *TMX) Validate() *ValidationReport {
+	report := &ValidationReport{}
+
+	tilesets := make(map[*Tileset]*TSX, len(tmx.Tilesets))
+	for _, tileset := range tmx.Tilesets {
+		tsx, err := tileset.TSX()
+		if err != nil {
+			report.add(ValidationIssue{Kind: IssueMissingTileset, Source: tileset.Source(), Message: err.Error()})
+			continue
+		}
+		tilesets[tileset] = tsx
+	}
+
+	// inRange reports whether the GID references a tile of a loaded tileset. GIDs of tilesets that
+	// failed to load are not reported again.
+	inRange := func(gid uint32) bool {
+		tileset := TilesetOf(gid, tmx.Tilesets)
+		if tileset == nil {
+			return false
+		}
+		tsx, loaded := tilesets[tileset]
+		if !loaded {
+			return true
+		}
+		id := gid - tileset.FirstGID()
+		if tsx.IsImageCollection() {
+			return tsx.Tile(id) != nil
+		}
+		return int(id) < tsx.TileCount()
+	}
+
+	for _, layer := range tmx.Layers {
+		validateLayer(report, layer, inRange)
+	}
+
+	for _, og := range tmx.ObjectGroups {
+		for _, obj := range og.Objects {
+			if obj.HasTemplate() {
+				if tx, err := obj.TX(); err != nil {
+					report.add(ValidationIssue{Kind: IssueMissingTemplate, Layer: og.Name(), Object: obj.ID(), Source: obj.Template(), Message: err.Error()})
+				} else if tx.Tileset != nil && tx.Tileset.Source() != "" {
+					if _, err := tx.Tileset.TSX(); err != nil {
+						report.add(ValidationIssue{Kind: IssueMissingTileset, Layer: og.Name(), Object: obj.ID(), Source: tx.Tileset.Source(), Message: err.Error()})
+					}
+				}
+			}
+
+			if gid := uint32(obj.GID()) & TILE_ID_MASK; gid != 0 && !inRange(gid) {
+				report.add(ValidationIssue{Kind: IssueGIDOutOfRange, Layer: og.Name(), Object: obj.ID(), Message: fmt.Sprintf("object %d references GID %d, which no tileset has", obj.ID(), gid)})
+			}
+		}
+	}
+
+	return report
+}
+
+// validateLayer checks that the layer's data decodes into consistently sized blocks of cells whose
+// GIDs are in range.
+func validateLayer(report *ValidationReport, layer *Layer, inRange func(uint32) bool) {
+	if layer.Data == nil {
+		return
+	}
+
+	outOfRange := ValidationIssue{Kind: IssueGIDOutOfRange, Layer: layer.Name()}
+	check := func(x, y int, cells []uint32, width int) {
+		for i, data := range cells {
+			if gid := data & TILE_ID_MASK; gid != 0 && !inRange(gid) {
+				if outOfRange.Count == 0 {
+					outOfRange.Cell = Cell{X: x + i%width, Y: y + i/width}
+					outOfRange.Message = fmt.Sprintf("cell %d,%d references GID %d, which no tileset has", outOfRange.Cell.X, outOfRange.Cell.Y, gid)
+				}
+				outOfRange.Count++
+			}
+		}
+	}
+
+	if len(layer.Data.Chunks) == 0 {
+		cells, err := layer.Data.decode(layer.Data.Data)
+		switch {
+		case err != nil:
+			report.add(ValidationIssue{Kind: IssueInvalidData, Layer: layer.Name(), Message: err.Error()})
+		case len(cells) != layer.Width()*layer.Height():
+			report.add(ValidationIssue{Kind: IssueInvalidData, Layer: layer.Name(), Message: fmt.Sprintf("data has %d cells, layer is %dx%d", len(cells), layer.Width(), layer.Height())})
+		default:
+			check(0, 0, cells, layer.Width())
+		}
+	} else {
+		first := layer.Data.Chunks[0]
+		for _, chunk := range layer.Data.Chunks {
+			cell := Cell{X: chunk.X(), Y: chunk.Y()}
+			if chunk.Width() <= 0 || chunk.Height() <= 0 {
+				report.add(ValidationIssue{Kind: IssueChunkSize, Layer: layer.Name(), Cell: cell, Message: fmt.Sprintf("chunk at %d,%d is %dx%d", cell.X, cell.Y, chunk.Width(), chunk.Height())})
+				continue
+			}
+			if chunk.Width() != first.Width() || chunk.Height() != first.Height() {
+				report.add(ValidationIssue{Kind: IssueChunkSize, Layer: layer.Name(), Cell: cell, Message: fmt.Sprintf("chunk at %d,%d is %dx%d, unlike the layer's other %dx%d chunks", cell.X, cell.Y, chunk.Width(), chunk.Height(), first.Width(), first.Height())})
+			}
+
+			cells, err := layer.Data.decode(chunk.Data)
+			switch {
+			case err != nil:
+				report.add(ValidationIssue{Kind: IssueInvalidData, Layer: layer.Name(), Cell: cell, Message: fmt.Sprintf("chunk at %d,%d: %s", cell.X, cell.Y, err)})
+			case len(cells) != chunk.Width()*chunk.Height():
+				report.add(ValidationIssue{Kind: IssueInvalidData, Layer: layer.Name(), Cell: cell, Message: fmt.Sprintf("chunk at %d,%d has %d cells, chunk is %dx%d", cell.X, cell.Y, len(cells), chunk.Width(), chunk.Height())})
+			default:
+				check(chunk.X(), chunk.Y(), cells, chunk.Width())
+			}
+		}
+	}
+
+	if outOfRange.Count > 0 {
+		report.add(outOfRange)
+	}
+}
@@ -0,0 +1,96 @@
+package tiled
+
+import (
+	"fmt"
+)
+
+// ======================================================
+// Walkability Grid
+// ======================================================
+
+// WalkabilityGrid is a flat, row-major snapshot of which cells of a layer can be walked on.
+// It covers every cell of a finite layer, or the bounds of every chunk of an infinite layer.
+type WalkabilityGrid struct {
+	// X and Y are the tile coordinates of the grid's top-left cell.
+	X, Y int
+
+	Width, Height int
+
+	// Walkable holds one entry per cell, indexed by (y-Y)*Width + (x-X).
+	Walkable []bool
+
+	// Costs optionally holds the cost of entering each cell, indexed like Walkable.
+	// Costs should be at least 1. If nil, every cell costs 1.
+	Costs []float64
+}
+
+// IsWalkable reports whether the cell at the given tile coordinates is walkable.
+// Cells outside the grid are not walkable.
+func (g *WalkabilityGrid) IsWalkable(x, y int) bool {
+	if x < g.X || y < g.Y || x >= g.X+g.Width || y >= g.Y+g.Height {
+		return false
+	}
+	return g.Walkable[(y-g.Y)*g.Width+(x-g.X)]
+}
+
+// Cost returns the cost of entering the cell at the given tile coordinates.
+func (g *WalkabilityGrid) Cost(x, y int) float64 {
+	if g.Costs == nil || !g.IsWalkable(x, y) {
+		return 1
+	}
+	return g.Costs[(y-g.Y)*g.Width+(x-g.X)]
+}
+
+// BuildWalkabilityGrid snapshots the named tile layer into a walkability grid.
+// Empty cells are walkable; tiles are walkable unless blocked reports them, e.g. through
+// TilePropertyEquals("solid", "true") or TileClassIs("wall"). If blocked is nil, every tile blocks.
+func BuildWalkabilityGrid(tmx *TMX, layerName string, blocked SolidFunc) (*WalkabilityGrid, error) {
+	cg, err := NewCollisionGrid(tmx, layerName, blocked)
+	if err != nil {
+		return nil, err
+	}
+	return cg.WalkabilityGrid()
+}
+
+// WalkabilityGrid snapshots the collision grid, treating every non-solid cell as walkable.
+// Slopes and one-way platforms are walkable.
+func (cg *CollisionGrid) WalkabilityGrid() (*WalkabilityGrid, error) {
+	grids, err := cg.layer.cellGrids()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build walkability grid: %w", err)
+	}
+
+	x, y, width, height := gridBounds(grids)
+
+	solid, err := cg.solidCells(grids, x, y, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build walkability grid: %w", err)
+	}
+
+	walkable := make([]bool, len(solid))
+	for i := range solid {
+		walkable[i] = !solid[i]
+	}
+
+	return &WalkabilityGrid{X: x, Y: y, Width: width, Height: height, Walkable: walkable}, nil
+}
+
+// ======================================================
+// Tile Matchers
+// ======================================================
+
+// TilePropertyEquals matches tiles whose tileset tile has the named property set to value.
+func TilePropertyEquals(name, value string) SolidFunc {
+	return func(key TileKey) bool {
+		prop := tileProperty(key, nil, name)
+		return prop != nil && prop.Value() == value
+	}
+}
+
+// TileClassIs matches tiles whose tileset tile has the provided class.
+func TileClassIs(class string) SolidFunc {
+	return func(key TileKey) bool {
+		tile := tilesetTile(key, nil)
+		return tile != nil && tile.Class() == class
+	}
+}
@@ -0,0 +1,444 @@
+package tiled
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ======================================================
+// Write Options
+// ======================================================
+
+// WriteOptions controls how maps are written back to TMX.
+type WriteOptions struct {
+	// BasePath is the asset path the map is written to. Tileset, template and image sources
+	// are written relative to it. If empty, sources are written as they are stored.
+	BasePath string
+
+	// Format overrides the data format of every tile layer. If nil, layers keep their current format.
+	Format *DataFormat
+
+	// LayerFormats overrides the data format of individual tile layers by name.
+	LayerFormats map[string]DataFormat
+
+	// Level is the compression level used for layers that keep their current format: up to 9 for
+	// gzip and zlib, up to 22 for zstd. Zero uses the default level.
+	Level int
+
+	// FloatPrecision is the number of significant digits written for float attributes.
+	// Zero uses DefaultFloatPrecision, which matches Tiled's own output; a negative value
+	// writes the shortest representation that round-trips exactly.
+	FloatPrecision int
+}
+
+// DefaultFloatPrecision is the number of significant digits Tiled writes for float values.
+const DefaultFloatPrecision = 6
+
+// formatFloat formats a float attribute using the options' precision.
+// Whole numbers are written without a decimal point and exponents are never used,
+// so integral floats and ints serialize identically.
+func (opts WriteOptions) formatFloat(v float64) string {
+	precision := opts.FloatPrecision
+	if precision == 0 {
+		precision = DefaultFloatPrecision
+	}
+
+	s := strconv.FormatFloat(v, 'g', precision, 64)
+	if strings.ContainsAny(s, "eE") {
+		rounded, _ := strconv.ParseFloat(s, 64)
+		s = strconv.FormatFloat(rounded, 'f', -1, 64)
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// layerFormat returns the format a layer's data should be written in.
+func (opts WriteOptions) layerFormat(layer *Layer) DataFormat {
+	if format, exists := opts.LayerFormats[layer.Name()]; exists {
+		return format
+	}
+	if opts.Format != nil {
+		return *opts.Format
+	}
+	format := layer.Data.Format()
+	format.Level = opts.Level
+	return format
+}
+
+// validate checks every format the options select before anything is written.
+func (opts WriteOptions) validate() error {
+	if opts.Format != nil {
+		if err := opts.Format.Validate(); err != nil {
+			return err
+		}
+	}
+	for name, format := range opts.LayerFormats {
+		if err := format.Validate(); err != nil {
+			return fmt.Errorf("layer %s: %w", name, err)
+		}
+	}
+	if opts.Level < 0 || opts.Level > maxCompressionLevel(TMXCompressionZstd) {
+		return fmt.Errorf("invalid compression level: %d", opts.Level)
+	}
+	return nil
+}
+
+// ======================================================
+// TMX Writer
+// ======================================================
+
+// WriteTMX writes the map as Tiled XML, keeping each layer's current data format.
+func WriteTMX(w io.Writer, tmx *TMX) error {
+	return WriteTMXWithOptions(w, tmx, WriteOptions{})
+}
+
+// WriteTMXWithOptions writes the map as Tiled XML.
+// Tile layer data is decoded and re-encoded in the format selected by the options,
+// regardless of the format it was loaded from.
+func WriteTMXWithOptions(w io.Writer, tmx *TMX, opts WriteOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	tw := &tmxWriter{enc: xml.NewEncoder(&buf), opts: opts}
+	tw.enc.Indent("", " ")
+
+	if err := tw.writeMap(tmx); err != nil {
+		return err
+	}
+	if err := tw.enc.Flush(); err != nil {
+		return err
+	}
+
+	buf.WriteByte('\n')
+
+	// encoding/xml cannot write self-closing elements, which Tiled uses for every empty element.
+	_, err := w.Write(emptyElementPattern.ReplaceAll(buf.Bytes(), []byte("<$1$2/>")))
+	return err
+}
+
+var emptyElementPattern = regexp.MustCompile(`<(\w+)([^<>]*)></(\w+)>`)
+
+type tmxWriter struct {
+	enc  *xml.Encoder
+	opts WriteOptions
+}
+
+func (tw *tmxWriter) writeMap(tmx *TMX) error {
+	if err := tw.start("map", tmx.Attrs); err != nil {
+		return err
+	}
+	if err := tw.writeProperties(tmx.Properties); err != nil {
+		return err
+	}
+
+	for _, tileset := range tmx.Tilesets {
+		if err := tw.empty("tileset", tw.opts.relativeSource(tileset.Attrs, SourceAttr)); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range tmx.OrderedLayers() {
+		var err error
+		switch layer := l.(type) {
+		case *Layer:
+			err = tw.writeLayer(layer)
+		case *ImageLayer:
+			err = tw.writeImageLayer(layer)
+		case *ObjectGroup:
+			err = tw.writeObjectGroup(layer)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.end("map")
+}
+
+func (tw *tmxWriter) writeLayer(layer *Layer) error {
+	if err := tw.start("layer", layer.Attrs); err != nil {
+		return err
+	}
+	if err := tw.writeProperties(layer.Properties); err != nil {
+		return err
+	}
+
+	if layer.Data != nil {
+		format := tw.opts.layerFormat(layer)
+
+		dataAttrs := TiledXMLAttrTable{}
+		for key, value := range layer.Data.Attrs {
+			dataAttrs[key] = value
+		}
+		(&LayerData{Attrs: dataAttrs}).setFormat(format)
+
+		if err := tw.start("data", dataAttrs); err != nil {
+			return err
+		}
+
+		if len(layer.Data.Chunks) > 0 {
+			for _, chunk := range layer.Data.Chunks {
+				encoded, err := reencodeData(layer.Data, chunk.Data, chunk.Width(), format)
+				if err != nil {
+					return fmt.Errorf("failed to encode layer %s: %w", layer.Name(), err)
+				}
+				if err := tw.start("chunk", chunk.Attrs); err != nil {
+					return err
+				}
+				if err := tw.text(encoded); err != nil {
+					return err
+				}
+				if err := tw.end("chunk"); err != nil {
+					return err
+				}
+			}
+		} else {
+			encoded, err := reencodeData(layer.Data, layer.Data.Data, layer.Width(), format)
+			if err != nil {
+				return fmt.Errorf("failed to encode layer %s: %w", layer.Name(), err)
+			}
+			if err := tw.text(encoded); err != nil {
+				return err
+			}
+		}
+
+		if err := tw.end("data"); err != nil {
+			return err
+		}
+	}
+
+	return tw.end("layer")
+}
+
+func (tw *tmxWriter) writeImageLayer(layer *ImageLayer) error {
+	if err := tw.start("imagelayer", layer.Attrs); err != nil {
+		return err
+	}
+	if err := tw.writeProperties(layer.Properties); err != nil {
+		return err
+	}
+	if layer.Image != nil {
+		if err := tw.empty("image", tw.opts.relativeSource(layer.Image.Attrs, SourceAttr)); err != nil {
+			return err
+		}
+	}
+	return tw.end("imagelayer")
+}
+
+func (tw *tmxWriter) writeObjectGroup(og *ObjectGroup) error {
+	if err := tw.start("objectgroup", og.Attrs); err != nil {
+		return err
+	}
+	if err := tw.writeProperties(og.Properties); err != nil {
+		return err
+	}
+	for _, obj := range og.Objects {
+		if err := tw.writeObject(obj); err != nil {
+			return err
+		}
+	}
+	return tw.end("objectgroup")
+}
+
+func (tw *tmxWriter) writeObject(obj *Object) error {
+	if err := tw.start("object", tw.opts.relativeSource(obj.Attrs, TemplateAttr)); err != nil {
+		return err
+	}
+	if err := tw.writeProperties(obj.Properties); err != nil {
+		return err
+	}
+	if obj.Polygon != nil {
+		if err := tw.empty("polygon", obj.Polygon.Attrs); err != nil {
+			return err
+		}
+	}
+	if obj.Polyline != nil {
+		if err := tw.empty("polyline", obj.Polyline.Attrs); err != nil {
+			return err
+		}
+	}
+	if obj.Ellipse != nil {
+		if err := tw.empty("ellipse", nil); err != nil {
+			return err
+		}
+	}
+	if obj.Point != nil {
+		if err := tw.empty("point", nil); err != nil {
+			return err
+		}
+	}
+	if obj.Text != nil {
+		if err := tw.start("text", obj.Text.Attrs); err != nil {
+			return err
+		}
+		if err := tw.text(obj.Text.Text); err != nil {
+			return err
+		}
+		if err := tw.end("text"); err != nil {
+			return err
+		}
+	}
+	return tw.end("object")
+}
+
+func (tw *tmxWriter) writeProperties(props []*Property) error {
+	if len(props) == 0 {
+		return nil
+	}
+	if err := tw.start("properties", nil); err != nil {
+		return err
+	}
+	for _, prop := range props {
+		attrs := prop.Attrs
+		if prop.Type() == FilePropertyType && prop.Value() != "" {
+			attrs = tw.opts.relativeSource(attrs, ValueAttr)
+		}
+		if len(prop.Properties) == 0 {
+			if err := tw.empty("property", attrs); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tw.start("property", attrs); err != nil {
+			return err
+		}
+		if err := tw.writeProperties(prop.Properties); err != nil {
+			return err
+		}
+		if err := tw.end("property"); err != nil {
+			return err
+		}
+	}
+	return tw.end("properties")
+}
+
+func (tw *tmxWriter) start(name string, attrs TiledXMLAttrTable) error {
+	return tw.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: name}, Attr: tw.attrs(attrs)})
+}
+
+func (tw *tmxWriter) end(name string) error {
+	return tw.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}})
+}
+
+func (tw *tmxWriter) empty(name string, attrs TiledXMLAttrTable) error {
+	if err := tw.start(name, attrs); err != nil {
+		return err
+	}
+	return tw.end(name)
+}
+
+func (tw *tmxWriter) text(s string) error {
+	return tw.enc.EncodeToken(xml.CharData(s))
+}
+
+// attrs converts an attribute table to XML attributes in the order Tiled writes them.
+func (tw *tmxWriter) attrs(table TiledXMLAttrTable) []xml.Attr {
+	attrs := make([]xml.Attr, 0, len(table))
+	for key, value := range table {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: key}, Value: tw.formatAttr(value)})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		ri, rj := attrRank(attrs[i].Name.Local), attrRank(attrs[j].Name.Local)
+		if ri != rj {
+			return ri < rj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+	return attrs
+}
+
+// relativeSource returns a copy of the attribute table with the named source attribute
+// made relative to the base path.
+func (opts WriteOptions) relativeSource(table TiledXMLAttrTable, key string) TiledXMLAttrTable {
+	source, exists := table[key]
+	if !exists || opts.BasePath == "" {
+		return table
+	}
+
+	rel, err := filepath.Rel(path.Dir(opts.BasePath), source.String())
+	if err != nil {
+		return table
+	}
+
+	copied := make(TiledXMLAttrTable, len(table))
+	for k, v := range table {
+		copied[k] = v
+	}
+	copied[key] = AttrString(filepath.ToSlash(rel))
+	return copied
+}
+
+// formatAttr formats an attribute value the way Tiled writes it.
+func (tw *tmxWriter) formatAttr(value TiledXMLAttr) string {
+	switch v := value.(type) {
+	case AttrBool:
+		if v.Bool() {
+			return "1"
+		}
+		return "0"
+	case AttrFloat:
+		return tw.opts.formatFloat(v.Float())
+	default:
+		return value.String()
+	}
+}
+
+func reencodeData(data *LayerData, raw string, width int, format DataFormat) (string, error) {
+	decoded, err := data.decode(raw)
+	if err != nil {
+		return "", err
+	}
+	return encodeData(decoded, width, format)
+}
+
+var attr_write_order = []string{
+	VersionAttr,
+	TiledVersionAttr,
+	IDAttr,
+	FirstGIDAttr,
+	NameAttr,
+	TypeAttr,
+	ClassAttr,
+	PropertyTypeAttr,
+	ValueAttr,
+	SourceAttr,
+	TemplateAttr,
+	GIDAttr,
+	OrientationAttr,
+	RenderOrderAttr,
+	EncodingAttr,
+	CompressionAttr,
+	XAttr,
+	YAttr,
+	WidthAttr,
+	HeightAttr,
+	TileWidthAttr,
+	TileHeightAttr,
+	HexSideLengthAttr,
+	StaggerAxisAttr,
+	StaggerIndexAttr,
+	InfiniteAttr,
+}
+
+func attrRank(name string) int {
+	for i := range attr_write_order {
+		if attr_write_order[i] == name {
+			return i
+		}
+	}
+	return len(attr_write_order)
+}
@@ -0,0 +1,114 @@
+package tiled
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const writerTestMap = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="16" tileheight="16" infinite="0" nextlayerid="3" nextobjectid="3">
+ <properties>
+  <property name="music" type="file" value="../audio/theme.ogg"/>
+  <property name="gravity" type="float" value="9.8"/>
+ </properties>
+ <tileset firstgid="1" source="../tiles.tsx"/>
+ <layer id="1" name="ground" width="3" height="2">
+  <data encoding="base64" compression="zlib">{{data}}</data>
+ </layer>
+ <objectgroup id="2" name="things">
+  <object id="1" name="spawn" x="8.5" y="24"><point/></object>
+  <object id="2" name="crate" gid="2" x="32" y="32" width="16" height="16"/>
+ </objectgroup>
+</map>`
+
+func parseWriterTestMap(t *testing.T) *TMX {
+	t.Helper()
+	source := strings.Replace(writerTestMap, "{{data}}", writerTestData(t), 1)
+	tmx, err := ParseTMX(strings.NewReader(source), RelativeTo("maps/levels/one.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmx
+}
+
+// writerTestData returns the ground layer's cells encoded as base64/zlib.
+func writerTestData(t *testing.T) string {
+	t.Helper()
+	raw, err := encodeData([]uint32{1, 0, 2, 3 | TILE_FLIP_HORIZONTAL, 0, 1}, 3, DataFormatBase64Zlib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestWriteTMXRoundTrip(t *testing.T) {
+	tmx := parseWriterTestMap(t)
+
+	var out bytes.Buffer
+	if err := WriteTMXWithOptions(&out, tmx, WriteOptions{BasePath: "maps/levels/one.tmx"}); err != nil {
+		t.Fatal(err)
+	}
+	written := out.String()
+
+	for _, want := range []string{`source="../tiles.tsx"`, `value="../audio/theme.ogg"`, `compression="zlib"`, `<point/>`} {
+		if !strings.Contains(written, want) {
+			t.Errorf("written map lacks %s:\n%s", want, written)
+		}
+	}
+
+	reread, err := ParseTMX(strings.NewReader(written), RelativeTo("maps/levels/one.tmx"))
+	if err != nil {
+		t.Fatalf("written map does not parse: %v\n%s", err, written)
+	}
+	if reread.Width() != 3 || reread.Height() != 2 || reread.Tilesets[0].Source() != "maps/tiles.tsx" {
+		t.Errorf("map attributes changed: %dx%d, tileset %s", reread.Width(), reread.Height(), reread.Tilesets[0].Source())
+	}
+	if cells := mustDecodeLayer(t, reread.Layers[0]); !slices.Equal(cells, mustDecodeLayer(t, tmx.Layers[0])) {
+		t.Errorf("cells changed to %v", cells)
+	}
+	objects := reread.ObjectGroupByName("things").Objects
+	if len(objects) != 2 || objects[0].X64() != 8.5 || objects[0].Point == nil || objects[1].GID() != 2 {
+		t.Errorf("objects changed: %+v", objects)
+	}
+	if music, _ := reread.PropertyByName("music"); music == nil || music.Value() != "maps/audio/theme.ogg" {
+		t.Errorf("file property resolves to %v, want maps/audio/theme.ogg", music)
+	}
+
+	// Writing what was read back gives the same document.
+	var again bytes.Buffer
+	if err := WriteTMXWithOptions(&again, reread, WriteOptions{BasePath: "maps/levels/one.tmx"}); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != written {
+		t.Errorf("second write differs:\n%s\nfirst:\n%s", again.String(), written)
+	}
+}
+
+func TestWriteTMXReencodesLayers(t *testing.T) {
+	tmx := parseWriterTestMap(t)
+	want := mustDecodeLayer(t, tmx.Layers[0])
+
+	for _, format := range []DataFormat{DataFormatCSV, DataFormatBase64, DataFormatBase64Gzip, DataFormatBase64Zstd} {
+		var out bytes.Buffer
+		if err := WriteTMXWithOptions(&out, tmx, WriteOptions{Format: &format}); err != nil {
+			t.Fatalf("%s/%s: %v", format.Encoding, format.Compression, err)
+		}
+		reread, err := ParseTMX(&out, nil)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", format.Encoding, format.Compression, err)
+		}
+		layer := reread.Layers[0]
+		if got := layer.Data.Format(); got.Encoding != format.Encoding || got.Compression != format.Compression {
+			t.Errorf("written as %s/%s, want %s/%s", got.Encoding, got.Compression, format.Encoding, format.Compression)
+		}
+		if cells := mustDecodeLayer(t, layer); !slices.Equal(cells, want) {
+			t.Errorf("%s/%s: cells changed to %v", format.Encoding, format.Compression, cells)
+		}
+	}
+
+	if layer := tmx.Layers[0]; layer.Data.Format() != DataFormatBase64Zlib {
+		t.Error("writing changed the map's own layer format")
+	}
+}